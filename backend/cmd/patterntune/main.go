@@ -0,0 +1,107 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"stocking-chain/internal/analysis"
+)
+
+//go:embed fixtures/candlestick_patterns.json
+var defaultFixtures []byte
+
+func main() {
+	fixturesPath := flag.String("fixtures", "", "Path to a labeled fixture JSON file (defaults to the embedded golden dataset)")
+	configPath := flag.String("config", "", "Path to a PatternConfig JSON file; omitted fields keep their default values")
+	verbose := flag.Bool("v", false, "List every fixture the detectors got wrong")
+	flag.Parse()
+
+	fixtures, err := loadFixtures(*fixturesPath)
+	if err != nil {
+		log.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	baseline := analysis.EvaluatePatternConfig(analysis.DefaultPatternConfig(), fixtures)
+
+	if *configPath == "" {
+		printEvaluation("Default thresholds", baseline, nil, *verbose)
+		return
+	}
+
+	cfg, err := analysis.LoadPatternConfig(*configPath)
+	if err != nil {
+		log.Fatalf("Failed to load config: %v", err)
+	}
+
+	candidate := analysis.EvaluatePatternConfig(cfg, fixtures)
+	printEvaluation("Candidate thresholds ("+*configPath+")", candidate, &baseline, *verbose)
+}
+
+// loadFixtures reads the fixture file at path, or the embedded dataset when path is empty
+func loadFixtures(path string) ([]analysis.PatternFixture, error) {
+	raw := defaultFixtures
+	if path != "" {
+		var err error
+		raw, err = os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var fixtures []analysis.PatternFixture
+	if err := json.Unmarshal(raw, &fixtures); err != nil {
+		return nil, fmt.Errorf("failed to decode fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// printEvaluation prints a precision/recall table, with F1 deltas against the baseline if given
+func printEvaluation(title string, eval analysis.PatternEvaluation, baseline *analysis.PatternEvaluation, verbose bool) {
+	fmt.Printf("%s - %d fixtures\n\n", title, eval.Fixtures)
+	fmt.Printf("%-22s %4s %4s %4s %9s %9s %6s", "Pattern", "TP", "FP", "FN", "Precision", "Recall", "F1")
+	if baseline != nil {
+		fmt.Printf(" %8s", "ΔF1")
+	}
+	fmt.Println()
+	fmt.Println(strings.Repeat("-", 66))
+
+	baselineF1 := map[string]float64{}
+	if baseline != nil {
+		for _, s := range baseline.Detectors {
+			baselineF1[s.Pattern] = s.F1
+		}
+	}
+
+	for _, s := range eval.Detectors {
+		fmt.Printf("%-22s %4d %4d %4d %9s %9s %6.2f",
+			s.Pattern, s.TruePositives, s.FalsePositives, s.FalseNegatives,
+			ratio(s.Precision, s.TruePositives+s.FalsePositives),
+			ratio(s.Recall, s.TruePositives+s.FalseNegatives),
+			s.F1,
+		)
+		if baseline != nil {
+			fmt.Printf(" %+8.2f", s.F1-baselineF1[s.Pattern])
+		}
+		fmt.Println()
+	}
+
+	fmt.Printf("\n%d of %d fixtures had mismatches\n", len(eval.Mismatches), eval.Fixtures)
+	if verbose {
+		for _, m := range eval.Mismatches {
+			fmt.Printf("  %s: missing=%v unexpected=%v\n", m.Fixture, m.Missing, m.Unexpected)
+		}
+	}
+}
+
+// ratio formats a metric, or "n/a" when its denominator was zero
+func ratio(value float64, denominator int) string {
+	if denominator == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f", value)
+}
@@ -17,8 +17,39 @@ func main() {
 	}
 
 	yahooClient := ssi.NewClient("")
-	analyzer := analysis.NewAnalyzer()
+
+	// SCORING_MODELS points at a JSON file of named scoring models; its default
+	// model makes the recommendations and the rest can be compared per request
+	config := analysis.DefaultAnalyzerConfig()
+	var scoringModels analysis.ScoringModelSet
+	if path := os.Getenv("SCORING_MODELS"); path != "" {
+		set, err := analysis.LoadScoringModels(path)
+		if err != nil {
+			log.Fatalf("Failed to load scoring models: %v", err)
+		}
+		scoringModels = set
+		for _, model := range set.Models {
+			if model.Name == set.Default {
+				config.ScoringModel = model
+			}
+		}
+		log.Printf("Loaded %d scoring models from %s (default %q)", len(set.Models), path, config.ScoringModel.Name)
+	}
+
+	// PATTERN_CONFIG points at candlestick thresholds tuned with cmd/patterntune;
+	// the file only needs the fields it changes
+	if path := os.Getenv("PATTERN_CONFIG"); path != "" {
+		patterns, err := analysis.LoadPatternConfig(path)
+		if err != nil {
+			log.Fatalf("Failed to load pattern config: %v", err)
+		}
+		config.Patterns = patterns
+		log.Printf("Loaded candlestick thresholds from %s", path)
+	}
+
+	analyzer := analysis.NewAnalyzerWithConfig(config)
 	handler := api.NewHandler(yahooClient, analyzer)
+	handler.RegisterScoringModels(scoringModels.Models)
 
 	server := &http.Server{
 		Addr:    ":" + port,
@@ -30,6 +61,12 @@ func main() {
 	log.Printf("Vietnamese stocks will automatically use .VN suffix")
 	log.Printf("API endpoints:")
 	log.Printf("  - POST /api/analyze - Analyze a stock")
+	log.Printf("  - POST /api/transform - Heikin-Ashi, Renko, Kagi, Line Break or P&F bars")
+	log.Printf("  - POST /api/backtest - Backtest the analyzer recommendations or rules")
+	log.Printf("  - POST /api/optimize - Walk-forward optimization of the analyzer parameters")
+	log.Printf("  - GET  /api/scoring-models - List the scoring models and signals")
+	log.Printf("  - POST /api/screen - Screen symbols with a rule")
+	log.Printf("  - POST /api/wyckoff/backtest - Backtest entries on Wyckoff events and zones")
 	log.Printf("  - GET  /api/price?symbol=XXX - Get latest price")
 	log.Printf("  - GET  /api/health - Health check")
 
@@ -6,13 +6,31 @@ import (
 	"time"
 )
 
-type Analyzer struct{}
+type Analyzer struct {
+	config AnalyzerConfig
+}
 
 func NewAnalyzer() *Analyzer {
-	return &Analyzer{}
+	return NewAnalyzerWithConfig(DefaultAnalyzerConfig())
+}
+
+// NewAnalyzerWithConfig creates an Analyzer with custom thresholds and indicator periods
+func NewAnalyzerWithConfig(config AnalyzerConfig) *Analyzer {
+	return &Analyzer{config: config}
+}
+
+// Config returns the thresholds and indicator periods the Analyzer uses
+func (a *Analyzer) Config() AnalyzerConfig {
+	return a.config
 }
 
 func (a *Analyzer) Analyze(symbol string, data []models.StockData) (*models.AnalysisReport, error) {
+	return a.AnalyzeWithMarket(symbol, data, nil)
+}
+
+// AnalyzeWithMarket runs the full analysis, comparing the stock against the
+// market index and sector series in market when they are available
+func (a *Analyzer) AnalyzeWithMarket(symbol string, data []models.StockData, market *MarketContext) (*models.AnalysisReport, error) {
 	if len(data) == 0 {
 		return nil, nil
 	}
@@ -20,13 +38,15 @@ func (a *Analyzer) Analyze(symbol string, data []models.StockData) (*models.Anal
 	currentData := data[len(data)-1]
 	currentPrice := currentData.Close
 
-	indicators := CalculateTechnicalIndicators(data)
-	patterns := DetectAllTimeframePatterns(data)
-	supportResistance := DetectSupportResistance(data)
+	indicators := CalculateTechnicalIndicatorsWithPeriods(data, a.config.indicatorPeriods())
+	patterns := DetectAllTimeframePatternsWithOptions(data, a.config.Patterns, a.config.PatternContext)
+	chartPatterns := DetectChartPatterns(data)
+	gaps := DetectGaps(data)
+	supportResistance := DetectSupportResistanceWithGaps(data, gaps)
 	trend := AnalyzeTrend(data)
-	wyckoff := AnalyzeWyckoff(data)
+	wyckoff := AnalyzeWyckoffWithOptions(data, market, a.config.wyckoffOptions())
 
-	recommendation, score := a.generateRecommendation(
+	scores := a.generateRecommendation(
 		currentPrice,
 		indicators,
 		patterns,
@@ -48,18 +68,25 @@ func (a *Analyzer) Analyze(symbol string, data []models.StockData) (*models.Anal
 		CurrentPrice:        currentPrice,
 		Indicators:          indicators,
 		Patterns:            patterns,
+		ChartPatterns:       chartPatterns,
 		SupportResistance:   supportResistance,
+		Gaps:                gaps,
 		Trend:               trend,
 		Wyckoff:             wyckoff,
 		BuyRange:            buyRange,
 		HalfBuyRange:        halfBuyRange,
 		SellRange:           sellRange,
-		Recommendation:      recommendation,
-		RecommendationScore: score,
+		Recommendation:      scores[0].Recommendation,
+		RecommendationScore: scores[0].Score,
+		ScoringModel:        scores[0].Model,
+		ModelComparison:     modelComparison(scores),
+		ScoreBreakdown:      scores[0].Breakdown,
 		PriceHistory:        data,
 	}, nil
 }
 
+// generateRecommendation scores the analysis with the configured scoring model
+// and with each comparison model. The first score is the configured model's
 func (a *Analyzer) generateRecommendation(
 	currentPrice float64,
 	indicators models.TechnicalIndicators,
@@ -67,117 +94,30 @@ func (a *Analyzer) generateRecommendation(
 	sr models.SupportResistance,
 	trend models.TrendAnalysis,
 	wyckoff models.WyckoffAnalysis,
-) (string, float64) {
-	score := 0.0
-
-	if indicators.RSI < 30 {
-		score += 2.0
-	} else if indicators.RSI < 40 {
-		score += 1.0
-	} else if indicators.RSI > 70 {
-		score -= 2.0
-	} else if indicators.RSI > 60 {
-		score -= 1.0
-	}
-
-	if indicators.MACD > indicators.MACDSignal {
-		score += 1.5
-	} else {
-		score -= 1.5
-	}
-
-	if currentPrice > indicators.SMA20 && indicators.SMA20 > indicators.SMA50 {
-		score += 1.5
-	} else if currentPrice < indicators.SMA20 && indicators.SMA20 < indicators.SMA50 {
-		score -= 1.5
-	}
-
-	if currentPrice < indicators.BollingerLower {
-		score += 1.0
-	} else if currentPrice > indicators.BollingerUpper {
-		score -= 1.0
-	}
-
-	// Use daily patterns for recommendation scoring
-	for _, pattern := range patterns.Daily {
-		if pattern.Type == "bullish" {
-			score += pattern.Confidence
-		} else if pattern.Type == "bearish" {
-			score -= pattern.Confidence
-		}
-	}
-
-	if trend.Trend == "uptrend" {
-		score += trend.Strength * 2
-	} else if trend.Trend == "downtrend" {
-		score -= trend.Strength * 2
-	}
-
-	if len(sr.SupportLevels) > 0 {
-		nearestSupport := sr.SupportLevels[0]
-		distanceToSupport := (currentPrice - nearestSupport) / currentPrice
-		if distanceToSupport < 0.02 {
-			score += 1.0
-		}
-	}
-
-	if len(sr.ResistanceLevels) > 0 {
-		nearestResistance := sr.ResistanceLevels[0]
-		distanceToResistance := (nearestResistance - currentPrice) / currentPrice
-		if distanceToResistance < 0.02 {
-			score -= 1.0
-		}
+) []models.ModelScore {
+	inputs := scoringInputs{
+		price:      currentPrice,
+		indicators: indicators,
+		patterns:   patterns,
+		sr:         sr,
+		trend:      trend,
+		wyckoff:    wyckoff,
 	}
 
-	// Wyckoff phase scoring (reduced weights to avoid dominating other signals)
-	switch wyckoff.Phase {
-	case "accumulation":
-		// Accumulation phase is bullish - smart money buying
-		score += 1.0 * wyckoff.PhaseConfidence
-	case "markup":
-		// Markup phase - trend is up
-		score += 0.75 * wyckoff.PhaseConfidence
-	case "distribution":
-		// Distribution phase is bearish - smart money selling
-		score -= 1.0 * wyckoff.PhaseConfidence
-	case "markdown":
-		// Markdown phase - trend is down
-		score -= 0.75 * wyckoff.PhaseConfidence
+	scores := []models.ModelScore{a.config.ScoringModel.score(inputs, a.config.BuyThreshold, a.config.SellThreshold)}
+	for _, model := range a.config.CompareModels {
+		scores = append(scores, model.score(inputs, a.config.BuyThreshold, a.config.SellThreshold))
 	}
+	return scores
+}
 
-	// Wyckoff events scoring (reduced weights)
-	for _, event := range wyckoff.Events {
-		switch event.Name {
-		case "Spring", "Sign of Strength", "Selling Climax":
-			// Bullish accumulation events
-			score += 0.75 * event.Confidence
-		case "Upthrust", "Sign of Weakness", "Buying Climax":
-			// Bearish distribution events
-			score -= 0.75 * event.Confidence
-		}
-	}
-
-	// Effort vs Result divergence
-	if wyckoff.EffortResult == "diverging" {
-		// Divergence suggests potential reversal
-		// Reduce confidence in current trend
-		if trend.Trend == "uptrend" {
-			score -= 0.25
-		} else if trend.Trend == "downtrend" {
-			score += 0.25
-		}
-	}
-
-	normalizedScore := math.Max(-1, math.Min(1, score/10))
-
-	recommendation := "hold"
-	if normalizedScore > 0.3 {
-		recommendation = "buy"
-	} else if normalizedScore < -0.3 {
-		recommendation = "sell"
+// modelComparison returns the scores to report side by side, or nil when only
+// the configured model ran
+func modelComparison(scores []models.ModelScore) []models.ModelScore {
+	if len(scores) < 2 {
+		return nil
 	}
-
-	return recommendation, normalizedScore
+	return scores
 }
 
 func (a *Analyzer) calculatePriceRanges(
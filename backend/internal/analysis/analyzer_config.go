@@ -0,0 +1,112 @@
+package analysis
+
+import "fmt"
+
+// AnalyzerConfig holds the tunable thresholds and indicator periods of the
+// Analyzer. The fast and slow trend SMAs fill the SMA20 and SMA50 indicator
+// fields, which keep their names for API compatibility
+type AnalyzerConfig struct {
+	// Overall recommendation: normalized score above/below these is buy/sell
+	BuyThreshold  float64 `json:"buy_threshold"`
+	SellThreshold float64 `json:"sell_threshold"`
+
+	// Wyckoff recommendation thresholds
+	WyckoffBuyThreshold  float64 `json:"wyckoff_buy_threshold"`
+	WyckoffSellThreshold float64 `json:"wyckoff_sell_threshold"`
+
+	// CauseCount sets the Point & Figure chart of the Wyckoff horizontal count
+	CauseCount CauseCountOptions `json:"cause_count"`
+
+	// Indicator periods
+	RSIPeriod       int `json:"rsi_period"`
+	FastSMAPeriod   int `json:"fast_sma_period"`
+	SlowSMAPeriod   int `json:"slow_sma_period"`
+	BollingerPeriod int `json:"bollinger_period"`
+
+	// Patterns holds the candlestick shape thresholds, as tuned with cmd/patterntune
+	Patterns PatternConfig `json:"patterns"`
+
+	// PatternContext selects the volume and trend checks that adjust candlestick
+	// pattern confidence
+	PatternContext PatternContextOptions `json:"pattern_context"`
+
+	// ScoringModel weighs the signals into the overall score; CompareModels are
+	// scored alongside it in the report without affecting the recommendation
+	ScoringModel  ScoringModel   `json:"scoring_model"`
+	CompareModels []ScoringModel `json:"compare_models,omitempty"`
+}
+
+// DefaultAnalyzerConfig returns the thresholds, periods and weights the Analyzer was built with
+func DefaultAnalyzerConfig() AnalyzerConfig {
+	return AnalyzerConfig{
+		BuyThreshold:         0.3,
+		SellThreshold:        -0.3,
+		WyckoffBuyThreshold:  0.4,
+		WyckoffSellThreshold: -0.4,
+		CauseCount:           DefaultCauseCountOptions(),
+		RSIPeriod:            14,
+		FastSMAPeriod:        20,
+		SlowSMAPeriod:        50,
+		BollingerPeriod:      20,
+		Patterns:             DefaultPatternConfig(),
+		PatternContext:       DefaultPatternContextOptions(),
+		ScoringModel:         DefaultScoringModel(),
+	}
+}
+
+// Validate checks that the thresholds are ordered and the periods usable
+func (c AnalyzerConfig) Validate() error {
+	if c.BuyThreshold <= c.SellThreshold {
+		return fmt.Errorf("buy_threshold (%.2f) must be above sell_threshold (%.2f)", c.BuyThreshold, c.SellThreshold)
+	}
+	if c.WyckoffBuyThreshold <= c.WyckoffSellThreshold {
+		return fmt.Errorf("wyckoff_buy_threshold (%.2f) must be above wyckoff_sell_threshold (%.2f)", c.WyckoffBuyThreshold, c.WyckoffSellThreshold)
+	}
+	for name, period := range map[string]int{
+		"rsi_period":       c.RSIPeriod,
+		"fast_sma_period":  c.FastSMAPeriod,
+		"slow_sma_period":  c.SlowSMAPeriod,
+		"bollinger_period": c.BollingerPeriod,
+	} {
+		if period < 2 {
+			return fmt.Errorf("%s must be at least 2, got %d", name, period)
+		}
+	}
+	if c.FastSMAPeriod >= c.SlowSMAPeriod {
+		return fmt.Errorf("fast_sma_period (%d) must be below slow_sma_period (%d)", c.FastSMAPeriod, c.SlowSMAPeriod)
+	}
+	if err := c.CauseCount.Validate(); err != nil {
+		return err
+	}
+	if err := c.Patterns.Validate(); err != nil {
+		return err
+	}
+	if err := c.ScoringModel.Validate(); err != nil {
+		return err
+	}
+	for _, model := range c.CompareModels {
+		if err := model.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// wyckoffOptions returns the Wyckoff settings of the config
+func (c AnalyzerConfig) wyckoffOptions() WyckoffOptions {
+	return WyckoffOptions{
+		BuyThreshold:  c.WyckoffBuyThreshold,
+		SellThreshold: c.WyckoffSellThreshold,
+		CauseCount:    c.CauseCount,
+	}
+}
+
+// indicatorPeriods returns the indicator periods of the config
+func (c AnalyzerConfig) indicatorPeriods() IndicatorPeriods {
+	return IndicatorPeriods{
+		RSI:       c.RSIPeriod,
+		FastSMA:   c.FastSMAPeriod,
+		SlowSMA:   c.SlowSMAPeriod,
+		Bollinger: c.BollingerPeriod,
+	}
+}
@@ -0,0 +1,835 @@
+package analysis
+
+import (
+	"math"
+	"sort"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// SWING POINT DETECTION
+// ============================================================================
+
+const (
+	// swingStrength is the number of bars on each side a swing point must exceed
+	swingStrength = 3
+	// minSwingMove is the minimum percentage move between alternating swings
+	minSwingMove = 0.03
+	// maxChartPatternAge is how many bars back a pattern's last key point may be
+	maxChartPatternAge = 120
+)
+
+// swingPoint is a confirmed local high or low in the price series
+type swingPoint struct {
+	index  int
+	price  float64
+	isHigh bool
+}
+
+// findSwingPoints returns alternating swing highs and lows, filtering out
+// swings whose move from the previous swing is smaller than minMove
+func findSwingPoints(data []models.StockData, strength int, minMove float64) []swingPoint {
+	raw := []swingPoint{}
+
+	for i := strength; i < len(data)-strength; i++ {
+		isHigh := true
+		isLow := true
+
+		for j := i - strength; j <= i+strength; j++ {
+			if j == i {
+				continue
+			}
+			if data[j].High >= data[i].High {
+				isHigh = false
+			}
+			if data[j].Low <= data[i].Low {
+				isLow = false
+			}
+		}
+
+		// An outside bar can be both; keep the side that continues the alternation
+		if isHigh && isLow && len(raw) > 0 {
+			if raw[len(raw)-1].isHigh {
+				isHigh = false
+			} else {
+				isLow = false
+			}
+		}
+
+		if isHigh {
+			raw = append(raw, swingPoint{index: i, price: data[i].High, isHigh: true})
+		}
+		if isLow {
+			raw = append(raw, swingPoint{index: i, price: data[i].Low, isHigh: false})
+		}
+	}
+
+	swings := []swingPoint{}
+	for _, s := range raw {
+		if len(swings) == 0 {
+			swings = append(swings, s)
+			continue
+		}
+
+		last := &swings[len(swings)-1]
+		if s.isHigh == last.isHigh {
+			// Keep the more extreme of two consecutive swings of the same kind
+			if (s.isHigh && s.price > last.price) || (!s.isHigh && s.price < last.price) {
+				*last = s
+			}
+			continue
+		}
+
+		if last.price > 0 && math.Abs(s.price-last.price)/last.price < minMove {
+			continue
+		}
+		swings = append(swings, s)
+	}
+
+	return swings
+}
+
+// ============================================================================
+// LINE HELPERS
+// ============================================================================
+
+// priceLine is a straight line in (bar index, price) space
+type priceLine struct {
+	slope     float64
+	intercept float64
+}
+
+// at returns the value of the line at the given bar index
+func (l priceLine) at(idx int) float64 {
+	return l.slope*float64(idx) + l.intercept
+}
+
+// lineThrough returns the line passing through two swing points
+func lineThrough(a, b swingPoint) priceLine {
+	if a.index == b.index {
+		return priceLine{slope: 0, intercept: a.price}
+	}
+	slope := (b.price - a.price) / float64(b.index-a.index)
+	return priceLine{slope: slope, intercept: a.price - slope*float64(a.index)}
+}
+
+// fitLine fits a least-squares line through the given swing points
+func fitLine(points []swingPoint) priceLine {
+	if len(points) == 0 {
+		return priceLine{}
+	}
+	if len(points) == 1 {
+		return priceLine{slope: 0, intercept: points[0].price}
+	}
+
+	n := float64(len(points))
+	sumX, sumY, sumXY, sumX2 := 0.0, 0.0, 0.0, 0.0
+	for _, p := range points {
+		x := float64(p.index)
+		sumX += x
+		sumY += p.price
+		sumXY += x * p.price
+		sumX2 += x * x
+	}
+
+	denom := n*sumX2 - sumX*sumX
+	if denom == 0 {
+		return priceLine{slope: 0, intercept: sumY / n}
+	}
+	slope := (n*sumXY - sumX*sumY) / denom
+	return priceLine{slope: slope, intercept: (sumY - slope*sumX) / n}
+}
+
+// fitBarLine fits a least-squares line through the highs or lows of data[start:end+1]
+func fitBarLine(data []models.StockData, start, end int, useHigh bool) priceLine {
+	points := make([]swingPoint, 0, end-start+1)
+	for i := start; i <= end; i++ {
+		price := data[i].Low
+		if useHigh {
+			price = data[i].High
+		}
+		points = append(points, swingPoint{index: i, price: price, isHigh: useHigh})
+	}
+	return fitLine(points)
+}
+
+// maxLineDeviation returns the largest relative distance of a point from the line
+func maxLineDeviation(points []swingPoint, line priceLine) float64 {
+	maxDev := 0.0
+	for _, p := range points {
+		if p.price == 0 {
+			continue
+		}
+		dev := math.Abs(p.price-line.at(p.index)) / p.price
+		if dev > maxDev {
+			maxDev = dev
+		}
+	}
+	return maxDev
+}
+
+// constantLine returns a horizontal line function at the given price
+func constantLine(price float64) func(int) float64 {
+	return func(int) float64 { return price }
+}
+
+// ============================================================================
+// BREAKOUT EVALUATION
+// ============================================================================
+
+// evaluateBreakout walks the bars after a pattern's last key point and reports
+// "confirmed" if price closed through the breakout line before the invalidation
+// line, "failed" if the invalidation line was crossed first, and "forming" otherwise
+func evaluateBreakout(
+	data []models.StockData,
+	from int,
+	bullish bool,
+	breakout func(int) float64,
+	invalidation func(int) float64,
+) (string, int) {
+	for i := from + 1; i < len(data); i++ {
+		price := data[i].Close
+		if bullish {
+			if price > breakout(i) {
+				return "confirmed", i
+			}
+			if price < invalidation(i) {
+				return "failed", i
+			}
+		} else {
+			if price < breakout(i) {
+				return "confirmed", i
+			}
+			if price > invalidation(i) {
+				return "failed", i
+			}
+		}
+	}
+	return "forming", -1
+}
+
+// breakoutPoint returns the key point for a confirmed breakout bar
+func breakoutPoint(data []models.StockData, idx int) models.ChartPoint {
+	return models.ChartPoint{Label: "breakout", Date: data[idx].Date, Price: data[idx].Close}
+}
+
+// swingChartPoint converts a swing point to a labeled chart point
+func swingChartPoint(data []models.StockData, s swingPoint, label string) models.ChartPoint {
+	return models.ChartPoint{Label: label, Date: data[s.index].Date, Price: s.price}
+}
+
+// levelIndex returns the bar at which a pattern's breakout level is reported:
+// the breakout bar if there was one, otherwise the latest bar
+func levelIndex(data []models.StockData, breakoutIdx int) int {
+	if breakoutIdx >= 0 {
+		return breakoutIdx
+	}
+	return len(data) - 1
+}
+
+// ============================================================================
+// HEAD AND SHOULDERS
+// ============================================================================
+
+// detectHeadAndShoulders checks five swings for a head and shoulders top
+// (high-low-high-low-high) or an inverse head and shoulders bottom
+func detectHeadAndShoulders(data []models.StockData, s []swingPoint) *models.ChartPattern {
+	if len(s) != 5 {
+		return nil
+	}
+
+	top := s[0].isHigh
+	leftShoulder, leftTrough, head, rightTrough, rightShoulder := s[0], s[1], s[2], s[3], s[4]
+
+	shoulderAvg := (leftShoulder.price + rightShoulder.price) / 2
+	shoulderDiff := math.Abs(leftShoulder.price-rightShoulder.price) / shoulderAvg
+
+	// How far the head extends beyond the nearer shoulder
+	headExcess := (head.price - math.Max(leftShoulder.price, rightShoulder.price)) / shoulderAvg
+	if !top {
+		headExcess = (math.Min(leftShoulder.price, rightShoulder.price) - head.price) / shoulderAvg
+	}
+
+	// Shoulders roughly level, head clearly beyond both
+	if shoulderDiff > 0.05 || headExcess < 0.03 {
+		return nil
+	}
+
+	neckline := lineThrough(leftTrough, rightTrough)
+
+	// Neckline should not slope more than 10% across the pattern
+	span := float64(rightShoulder.index - leftShoulder.index)
+	if math.Abs(neckline.slope*span)/shoulderAvg > 0.10 {
+		return nil
+	}
+
+	status, breakoutIdx := evaluateBreakout(data, rightShoulder.index, !top, neckline.at, constantLine(head.price))
+
+	levelIdx := levelIndex(data, breakoutIdx)
+	breakoutLevel := neckline.at(levelIdx)
+	height := math.Abs(head.price - neckline.at(head.index))
+
+	confidence := 0.7
+	if shoulderDiff < 0.02 {
+		confidence += 0.1
+	}
+	// Volume typically fades on the right shoulder
+	if data[rightShoulder.index].Volume < data[head.index].Volume {
+		confidence += 0.05
+	}
+	if status == "confirmed" {
+		confidence += 0.05
+	}
+
+	pattern := &models.ChartPattern{
+		Status:        status,
+		StartDate:     data[leftShoulder.index].Date,
+		EndDate:       data[rightShoulder.index].Date,
+		BreakoutLevel: breakoutLevel,
+		Confidence:    math.Min(confidence, 0.95),
+		KeyPoints: []models.ChartPoint{
+			swingChartPoint(data, leftShoulder, "left_shoulder"),
+			swingChartPoint(data, leftTrough, "neckline_left"),
+			swingChartPoint(data, head, "head"),
+			swingChartPoint(data, rightTrough, "neckline_right"),
+			swingChartPoint(data, rightShoulder, "right_shoulder"),
+		},
+	}
+
+	if top {
+		pattern.Name = "Head and Shoulders"
+		pattern.Type = "bearish"
+		pattern.Target = breakoutLevel - height
+	} else {
+		pattern.Name = "Inverse Head and Shoulders"
+		pattern.Type = "bullish"
+		pattern.Target = breakoutLevel + height
+	}
+
+	if breakoutIdx >= 0 {
+		pattern.KeyPoints = append(pattern.KeyPoints, breakoutPoint(data, breakoutIdx))
+	}
+
+	return pattern
+}
+
+// ============================================================================
+// DOUBLE / TRIPLE TOPS AND BOTTOMS
+// ============================================================================
+
+// detectMultipleTop checks alternating swings for a double (3 swings) or
+// triple (5 swings) top or bottom with peaks at roughly the same level
+func detectMultipleTop(data []models.StockData, s []swingPoint) *models.ChartPattern {
+	if len(s) != 3 && len(s) != 5 {
+		return nil
+	}
+
+	top := s[0].isHigh
+	peaks := []swingPoint{}
+	troughs := []swingPoint{}
+	for i, p := range s {
+		if i%2 == 0 {
+			peaks = append(peaks, p)
+		} else {
+			troughs = append(troughs, p)
+		}
+	}
+
+	peakPrices := make([]float64, len(peaks))
+	for i, p := range peaks {
+		peakPrices[i] = p.price
+	}
+	peakAvg := averageFloat64(peakPrices)
+
+	// All peaks within 3% of their average
+	maxPeakDiff := 0.0
+	for _, p := range peaks {
+		maxPeakDiff = math.Max(maxPeakDiff, math.Abs(p.price-peakAvg)/peakAvg)
+	}
+	if maxPeakDiff > 0.03 {
+		return nil
+	}
+
+	// Peaks should be separated by at least two weeks of trading
+	if peaks[len(peaks)-1].index-peaks[0].index < 10 {
+		return nil
+	}
+
+	// Neckline is the most extreme intervening trough
+	neckline := troughs[0].price
+	for _, t := range troughs {
+		if (top && t.price < neckline) || (!top && t.price > neckline) {
+			neckline = t.price
+		}
+	}
+
+	depth := math.Abs(peakAvg-neckline) / peakAvg
+	if depth < 0.05 {
+		return nil
+	}
+
+	extreme := peaks[0].price
+	for _, p := range peaks {
+		if (top && p.price > extreme) || (!top && p.price < extreme) {
+			extreme = p.price
+		}
+	}
+
+	last := peaks[len(peaks)-1]
+	status, breakoutIdx := evaluateBreakout(data, last.index, !top, constantLine(neckline), constantLine(extreme))
+
+	height := math.Abs(peakAvg - neckline)
+
+	confidence := 0.65
+	if len(peaks) == 3 {
+		confidence += 0.1
+	}
+	if maxPeakDiff < 0.015 {
+		confidence += 0.05
+	}
+	if status == "confirmed" {
+		confidence += 0.1
+	}
+
+	prefix := "Double"
+	if len(peaks) == 3 {
+		prefix = "Triple"
+	}
+
+	pattern := &models.ChartPattern{
+		Status:        status,
+		StartDate:     data[peaks[0].index].Date,
+		EndDate:       data[last.index].Date,
+		BreakoutLevel: neckline,
+		Confidence:    math.Min(confidence, 0.95),
+		KeyPoints:     []models.ChartPoint{},
+	}
+
+	peakLabel := "top"
+	troughLabel := "valley"
+	if top {
+		pattern.Name = prefix + " Top"
+		pattern.Type = "bearish"
+		pattern.Target = neckline - height
+	} else {
+		pattern.Name = prefix + " Bottom"
+		pattern.Type = "bullish"
+		pattern.Target = neckline + height
+		peakLabel = "bottom"
+		troughLabel = "peak"
+	}
+
+	for i, p := range s {
+		label := peakLabel
+		if i%2 == 1 {
+			label = troughLabel
+		}
+		pattern.KeyPoints = append(pattern.KeyPoints, swingChartPoint(data, p, label))
+	}
+	if breakoutIdx >= 0 {
+		pattern.KeyPoints = append(pattern.KeyPoints, breakoutPoint(data, breakoutIdx))
+	}
+
+	return pattern
+}
+
+// ============================================================================
+// TRIANGLES AND WEDGES
+// ============================================================================
+
+// detectConvergingPattern fits trendlines through the swing highs and lows and
+// classifies ascending/descending/symmetrical triangles and rising/falling wedges
+func detectConvergingPattern(data []models.StockData, s []swingPoint) *models.ChartPattern {
+	if len(s) < 4 {
+		return nil
+	}
+
+	highs := []swingPoint{}
+	lows := []swingPoint{}
+	for _, p := range s {
+		if p.isHigh {
+			highs = append(highs, p)
+		} else {
+			lows = append(lows, p)
+		}
+	}
+	if len(highs) < 2 || len(lows) < 2 {
+		return nil
+	}
+
+	upper := fitLine(highs)
+	lower := fitLine(lows)
+
+	// Every swing must sit close to its trendline
+	if maxLineDeviation(highs, upper) > 0.03 || maxLineDeviation(lows, lower) > 0.03 {
+		return nil
+	}
+
+	first := s[0].index
+	last := s[len(s)-1].index
+	startWidth := upper.at(first) - lower.at(first)
+	endWidth := upper.at(last) - lower.at(last)
+
+	// Lines must converge without having crossed
+	if startWidth <= 0 || endWidth <= 0 || endWidth >= startWidth*0.9 {
+		return nil
+	}
+
+	midPrice := (upper.at(last) + lower.at(last)) / 2
+	upperSlope := upper.slope / midPrice
+	lowerSlope := lower.slope / midPrice
+
+	// Less than 0.05% per bar counts as flat (about 1% per month)
+	const flat = 0.0005
+
+	var name, patternType string
+	switch {
+	case math.Abs(upperSlope) < flat && lowerSlope > flat:
+		name, patternType = "Ascending Triangle", "bullish"
+	case upperSlope < -flat && math.Abs(lowerSlope) < flat:
+		name, patternType = "Descending Triangle", "bearish"
+	case upperSlope < -flat && lowerSlope > flat:
+		name, patternType = "Symmetrical Triangle", "neutral"
+	case upperSlope > flat && lowerSlope > upperSlope:
+		name, patternType = "Rising Wedge", "bearish"
+	case lowerSlope < -flat && upperSlope < lowerSlope:
+		name, patternType = "Falling Wedge", "bullish"
+	default:
+		return nil
+	}
+
+	bullish := patternType != "bearish"
+	var status string
+	var breakoutIdx int
+	if bullish {
+		status, breakoutIdx = evaluateBreakout(data, last, true, upper.at, lower.at)
+	} else {
+		status, breakoutIdx = evaluateBreakout(data, last, false, lower.at, upper.at)
+	}
+
+	// A symmetrical triangle takes the direction of whichever side breaks
+	if patternType == "neutral" && status == "failed" {
+		status = "confirmed"
+		patternType = "bearish"
+		bullish = false
+	} else if patternType == "neutral" && status == "confirmed" {
+		patternType = "bullish"
+	}
+
+	levelIdx := levelIndex(data, breakoutIdx)
+	confidence := 0.6 + math.Min(float64(len(s)-4)*0.05, 0.1)
+	if status == "confirmed" {
+		confidence += 0.15
+	}
+
+	pattern := &models.ChartPattern{
+		Name:       name,
+		Type:       patternType,
+		Status:     status,
+		StartDate:  data[first].Date,
+		EndDate:    data[last].Date,
+		Confidence: math.Min(confidence, 0.95),
+		KeyPoints:  []models.ChartPoint{},
+	}
+
+	if bullish {
+		pattern.BreakoutLevel = upper.at(levelIdx)
+		pattern.Target = pattern.BreakoutLevel + startWidth
+	} else {
+		pattern.BreakoutLevel = lower.at(levelIdx)
+		pattern.Target = pattern.BreakoutLevel - startWidth
+	}
+
+	for _, p := range s {
+		label := "lower"
+		if p.isHigh {
+			label = "upper"
+		}
+		pattern.KeyPoints = append(pattern.KeyPoints, swingChartPoint(data, p, label))
+	}
+	if breakoutIdx >= 0 {
+		pattern.KeyPoints = append(pattern.KeyPoints, breakoutPoint(data, breakoutIdx))
+	}
+
+	return pattern
+}
+
+// ============================================================================
+// FLAGS AND PENNANTS
+// ============================================================================
+
+// detectFlagOrPennant checks whether a sharp pole between two swings is followed
+// by a short consolidation that drifts against the pole (flag) or converges (pennant)
+func detectFlagOrPennant(data []models.StockData, poleStart, poleEnd swingPoint) *models.ChartPattern {
+	const (
+		minPoleMove  = 0.08
+		maxPoleBars  = 15
+		minFlagBars  = 4
+		maxFlagBars  = 20
+		maxRetrace   = 0.5
+		parallelSlop = 0.002
+	)
+
+	bullish := poleEnd.isHigh
+	poleBars := poleEnd.index - poleStart.index
+	poleHeight := math.Abs(poleEnd.price - poleStart.price)
+	if poleBars <= 0 || poleBars > maxPoleBars || poleHeight/poleStart.price < minPoleMove {
+		return nil
+	}
+
+	retraceLevel := poleEnd.price - poleHeight*maxRetrace
+	if !bullish {
+		retraceLevel = poleEnd.price + poleHeight*maxRetrace
+	}
+
+	// Consolidation runs until price leaves the pole's extreme or the retrace limit
+	end := poleEnd.index
+	for i := poleEnd.index + 1; i < len(data) && i-poleEnd.index <= maxFlagBars; i++ {
+		if bullish && (data[i].Close > poleEnd.price || data[i].Close < retraceLevel) {
+			break
+		}
+		if !bullish && (data[i].Close < poleEnd.price || data[i].Close > retraceLevel) {
+			break
+		}
+		end = i
+	}
+
+	if end-poleEnd.index < minFlagBars {
+		return nil
+	}
+
+	upper := fitBarLine(data, poleEnd.index+1, end, true)
+	lower := fitBarLine(data, poleEnd.index+1, end, false)
+
+	midPrice := (upper.at(end) + lower.at(end)) / 2
+	upperSlope := upper.slope / midPrice
+	lowerSlope := lower.slope / midPrice
+
+	var name string
+	switch {
+	case math.Abs(upperSlope-lowerSlope) < parallelSlop &&
+		((bullish && upperSlope <= 0) || (!bullish && lowerSlope >= 0)):
+		name = "Flag"
+	case upperSlope < 0 && lowerSlope > 0:
+		name = "Pennant"
+	default:
+		return nil
+	}
+
+	var status string
+	var breakoutIdx int
+	if bullish {
+		name = "Bull " + name
+		status, breakoutIdx = evaluateBreakout(data, end, true, upper.at, constantLine(retraceLevel))
+	} else {
+		name = "Bear " + name
+		status, breakoutIdx = evaluateBreakout(data, end, false, lower.at, constantLine(retraceLevel))
+	}
+
+	levelIdx := levelIndex(data, breakoutIdx)
+
+	confidence := 0.65
+	// Volume should contract during the consolidation
+	if calculateAverageVolume(data[poleEnd.index+1:end+1], end-poleEnd.index) <
+		calculateAverageVolume(data[poleStart.index:poleEnd.index+1], poleBars+1) {
+		confidence += 0.1
+	}
+	if status == "confirmed" {
+		confidence += 0.1
+	}
+
+	pattern := &models.ChartPattern{
+		Name:       name,
+		Status:     status,
+		StartDate:  data[poleStart.index].Date,
+		EndDate:    data[end].Date,
+		Confidence: math.Min(confidence, 0.95),
+		KeyPoints: []models.ChartPoint{
+			swingChartPoint(data, poleStart, "pole_start"),
+			swingChartPoint(data, poleEnd, "pole_end"),
+			{Label: "upper_end", Date: data[end].Date, Price: upper.at(end)},
+			{Label: "lower_end", Date: data[end].Date, Price: lower.at(end)},
+		},
+	}
+
+	if bullish {
+		pattern.Type = "bullish"
+		pattern.BreakoutLevel = upper.at(levelIdx)
+		pattern.Target = pattern.BreakoutLevel + poleHeight
+	} else {
+		pattern.Type = "bearish"
+		pattern.BreakoutLevel = lower.at(levelIdx)
+		pattern.Target = pattern.BreakoutLevel - poleHeight
+	}
+
+	if breakoutIdx >= 0 {
+		pattern.KeyPoints = append(pattern.KeyPoints, breakoutPoint(data, breakoutIdx))
+	}
+
+	return pattern
+}
+
+// ============================================================================
+// CUP AND HANDLE
+// ============================================================================
+
+// detectCupAndHandle checks a high-low-high swing triplet for a rounded cup
+// followed by a shallow handle below the right rim
+func detectCupAndHandle(data []models.StockData, leftRim, bottom, rightRim swingPoint) *models.ChartPattern {
+	if !leftRim.isHigh || bottom.isHigh || !rightRim.isHigh {
+		return nil
+	}
+
+	cupBars := rightRim.index - leftRim.index
+	if cupBars < 25 {
+		return nil
+	}
+
+	rim := math.Max(leftRim.price, rightRim.price)
+	if math.Abs(leftRim.price-rightRim.price)/rim > 0.05 {
+		return nil
+	}
+
+	depth := rim - bottom.price
+	depthPct := depth / rim
+	if depthPct < 0.12 || depthPct > 0.5 {
+		return nil
+	}
+
+	// Bottom should sit in the middle of the cup
+	bottomPos := float64(bottom.index-leftRim.index) / float64(cupBars)
+	if bottomPos < 0.25 || bottomPos > 0.75 {
+		return nil
+	}
+
+	// A rounded (U-shaped) bottom spends time near the lows; a V does not
+	nearBottom := 0
+	for i := leftRim.index; i <= rightRim.index; i++ {
+		if data[i].Low <= bottom.price+depth/3 {
+			nearBottom++
+		}
+	}
+	if float64(nearBottom)/float64(cupBars) < 0.15 {
+		return nil
+	}
+
+	// Handle must stay in the upper half of the cup
+	handleFloor := bottom.price + depth/2
+	status, breakoutIdx := evaluateBreakout(data, rightRim.index, true, constantLine(rim), constantLine(handleFloor))
+
+	handleEnd := len(data) - 1
+	if breakoutIdx >= 0 {
+		handleEnd = breakoutIdx - 1
+	}
+	if handleEnd-rightRim.index < 3 || handleEnd-rightRim.index > cupBars/2 {
+		return nil
+	}
+
+	handleLowIdx := rightRim.index + 1
+	for i := rightRim.index + 1; i <= handleEnd; i++ {
+		if data[i].Low < data[handleLowIdx].Low {
+			handleLowIdx = i
+		}
+	}
+
+	confidence := 0.65
+	if depthPct <= 0.33 {
+		confidence += 0.05
+	}
+	if rim-data[handleLowIdx].Low <= depth/3 {
+		confidence += 0.05
+	}
+	if status == "confirmed" {
+		confidence += 0.1
+	}
+
+	pattern := &models.ChartPattern{
+		Name:          "Cup and Handle",
+		Type:          "bullish",
+		Status:        status,
+		StartDate:     data[leftRim.index].Date,
+		EndDate:       data[handleEnd].Date,
+		BreakoutLevel: rim,
+		Target:        rim + depth,
+		Confidence:    math.Min(confidence, 0.95),
+		KeyPoints: []models.ChartPoint{
+			swingChartPoint(data, leftRim, "left_rim"),
+			swingChartPoint(data, bottom, "cup_bottom"),
+			swingChartPoint(data, rightRim, "right_rim"),
+			{Label: "handle_low", Date: data[handleLowIdx].Date, Price: data[handleLowIdx].Low},
+		},
+	}
+
+	if breakoutIdx >= 0 {
+		pattern.KeyPoints = append(pattern.KeyPoints, breakoutPoint(data, breakoutIdx))
+	}
+
+	return pattern
+}
+
+// ============================================================================
+// MAIN CHART PATTERN DETECTION FUNCTION
+// ============================================================================
+
+// DetectChartPatterns scans swing points for multi-week chart structures and
+// returns the most recent occurrence of each pattern, newest first
+func DetectChartPatterns(data []models.StockData) []models.ChartPattern {
+	if len(data) < 30 {
+		return []models.ChartPattern{}
+	}
+
+	swings := findSwingPoints(data, swingStrength, minSwingMove)
+	minIndex := len(data) - maxChartPatternAge
+
+	latest := map[string]models.ChartPattern{}
+	add := func(p *models.ChartPattern) {
+		if p == nil {
+			return
+		}
+		if existing, ok := latest[p.Name]; !ok || p.EndDate.After(existing.EndDate) {
+			latest[p.Name] = *p
+		}
+	}
+
+	for e := 0; e < len(swings); e++ {
+		if swings[e].index < minIndex {
+			continue
+		}
+
+		if e >= 4 {
+			window := swings[e-4 : e+1]
+			if hs := detectHeadAndShoulders(data, window); hs != nil {
+				add(hs)
+			} else if triple := detectMultipleTop(data, window); triple != nil {
+				add(triple)
+			} else if double := detectMultipleTop(data, swings[e-2:e+1]); double != nil {
+				add(double)
+			}
+			add(detectConvergingPattern(data, window))
+		} else if e >= 2 {
+			add(detectMultipleTop(data, swings[e-2:e+1]))
+		}
+
+		if e >= 1 {
+			add(detectFlagOrPennant(data, swings[e-1], swings[e]))
+		}
+
+		if e >= 2 {
+			add(detectCupAndHandle(data, swings[e-2], swings[e-1], swings[e]))
+		}
+	}
+
+	patterns := make([]models.ChartPattern, 0, len(latest))
+	for _, p := range latest {
+		patterns = append(patterns, p)
+	}
+
+	sort.Slice(patterns, func(i, j int) bool {
+		if patterns[i].EndDate.Equal(patterns[j].EndDate) {
+			return patterns[i].Name < patterns[j].Name
+		}
+		return patterns[i].EndDate.After(patterns[j].EndDate)
+	})
+
+	return patterns
+}
@@ -0,0 +1,565 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// PROBABILISTIC PRICE FORECAST
+// ============================================================================
+
+// Forecast methods
+const (
+	ForecastHistorical = "historical" // Lognormal prices from the historical volatility
+	ForecastBootstrap  = "bootstrap"  // Paths of resampled daily returns
+	ForecastGARCH      = "garch"      // Paths with GARCH(1,1) volatility fitted to the returns
+)
+
+// ForecastConfig controls the forecast bands. Zero fields take the defaults of
+// DefaultForecastConfig
+type ForecastConfig struct {
+	Horizon      int      `json:"horizon"`       // Trading days ahead
+	Methods      []string `json:"methods"`       // "historical", "bootstrap", "garch"
+	LookbackBars int      `json:"lookback_bars"` // Daily returns the models are fitted to, at most 2500
+	Simulations  int      `json:"simulations"`   // Paths per simulated method
+	Drift        bool     `json:"drift"`         // Keep the lookback's mean return; otherwise prices have no trend
+	Seed         int64    `json:"seed"`
+}
+
+// DefaultForecastConfig forecasts 20 trading days with the historical and
+// bootstrap methods, fitted to the last 250 returns, with 2000 paths
+func DefaultForecastConfig() ForecastConfig {
+	return ForecastConfig{
+		Horizon:      20,
+		Methods:      []string{ForecastHistorical, ForecastBootstrap},
+		LookbackBars: 250,
+		Simulations:  2000,
+		Seed:         1,
+	}
+}
+
+// minForecastReturns is the fewest daily returns a forecast is fitted to
+const minForecastReturns = 30
+
+// minForecastVolatility is the daily log-return deviation below which a
+// series is treated as flat (a suspended or illiquid ticker)
+const minForecastVolatility = 1e-6
+
+// maxForecastHorizon keeps the simulated paths to about a year
+const maxForecastHorizon = 250
+
+// maxForecastSimulations bounds the paths per method; memory and time grow
+// with simulations × horizon
+const maxForecastSimulations = 20000
+
+// maxForecastLookback caps the fitted returns at about ten years of daily bars
+const maxForecastLookback = 2500
+
+// forecastPercentiles are the percentiles of the bands, with their standard
+// normal quantiles for the lognormal method
+var (
+	forecastPercentiles = []float64{5, 25, 50, 75, 95}
+	forecastQuantiles   = []float64{-1.6448536269514722, -0.6744897501960817, 0, 0.6744897501960817, 1.6448536269514722}
+)
+
+// withDefaults fills the zero fields from DefaultForecastConfig
+func (c ForecastConfig) withDefaults() ForecastConfig {
+	defaults := DefaultForecastConfig()
+	if c.Horizon <= 0 {
+		c.Horizon = defaults.Horizon
+	}
+	if len(c.Methods) == 0 {
+		c.Methods = defaults.Methods
+	}
+	if c.LookbackBars <= 0 {
+		c.LookbackBars = defaults.LookbackBars
+	}
+	c.LookbackBars = min(c.LookbackBars, maxForecastLookback)
+	if c.Simulations <= 0 {
+		c.Simulations = defaults.Simulations
+	}
+	if c.Seed == 0 {
+		c.Seed = defaults.Seed
+	}
+	return c
+}
+
+// Validate checks the methods, the horizon and the number of simulations
+func (c ForecastConfig) Validate() error {
+	c = c.withDefaults()
+	for _, method := range c.Methods {
+		switch method {
+		case ForecastHistorical, ForecastBootstrap, ForecastGARCH:
+		default:
+			return fmt.Errorf("unknown forecast method %q", method)
+		}
+	}
+	if c.Horizon > maxForecastHorizon {
+		return fmt.Errorf("horizon must be at most %d trading days, got %d", maxForecastHorizon, c.Horizon)
+	}
+	if c.Simulations > maxForecastSimulations {
+		return fmt.Errorf("simulations must be at most %d, got %d", maxForecastSimulations, c.Simulations)
+	}
+	return nil
+}
+
+// forecastLevel is a support, resistance or Wyckoff zone whose touch
+// probability is reported. A single level has Min == Max
+type forecastLevel struct {
+	label string
+	kind  string
+	zone  models.PriceRange
+}
+
+// ForecastPrices replaces fixed percentage ranges with statistical bands for
+// the next Horizon trading days. Each method reports percentile bands of the
+// price by day and, for every support, resistance and Wyckoff zone, the
+// probability of trading at it within the horizon and of closing beyond it at
+// the end. Touches count intraday moves: the lognormal method is continuous,
+// and the simulated paths add the chance of crossing between two closes
+// (a Brownian bridge)
+func ForecastPrices(report *models.AnalysisReport, cfg ForecastConfig) models.PriceForecast {
+	cfg = cfg.withDefaults()
+	forecast := models.PriceForecast{
+		Horizon:      cfg.Horizon,
+		CurrentPrice: report.CurrentPrice,
+		Drift:        cfg.Drift,
+		Methods:      []models.ForecastMethod{},
+		Notes:        []string{},
+	}
+
+	returns := logReturns(report.PriceHistory, cfg.LookbackBars)
+	if len(returns) < minForecastReturns || report.CurrentPrice <= 0 {
+		forecast.Notes = append(forecast.Notes, fmt.Sprintf("Need at least %d daily returns, have %d", minForecastReturns, len(returns)))
+		return forecast
+	}
+	forecast.LookbackBars = len(returns)
+
+	drift := 0.0
+	if cfg.Drift {
+		drift = averageFloat64(returns)
+	}
+	levels := forecastLevels(report)
+
+	// Flat prices have no distribution to fit: every method collapses to the
+	// single path of the drift, and GARCH has no likelihood to maximize
+	if sampleStdDev(returns) < minForecastVolatility {
+		forecast.Notes = append(forecast.Notes, fmt.Sprintf("The last %d returns have no volatility; the bands collapse to a single path and GARCH is not fitted", len(returns)))
+		for _, method := range cfg.Methods {
+			forecast.Methods = append(forecast.Methods, flatForecast(method, report.CurrentPrice, drift, cfg.Horizon, levels))
+		}
+		return forecast
+	}
+
+	rng := rand.New(rand.NewSource(cfg.Seed))
+
+	for _, method := range cfg.Methods {
+		switch method {
+		case ForecastHistorical:
+			forecast.Methods = append(forecast.Methods, historicalForecast(report.CurrentPrice, returns, drift, cfg.Horizon, levels))
+		case ForecastBootstrap:
+			forecast.Methods = append(forecast.Methods, bootstrapForecast(report.CurrentPrice, returns, drift, cfg, levels, rng))
+		case ForecastGARCH:
+			forecast.Methods = append(forecast.Methods, garchForecast(report.CurrentPrice, returns, drift, cfg, levels, rng))
+		}
+	}
+	return forecast
+}
+
+// logReturns returns the daily log returns of the last lookback closes
+func logReturns(data []models.StockData, lookback int) []float64 {
+	start := max(1, len(data)-lookback)
+	returns := []float64{}
+	for i := start; i < len(data); i++ {
+		if data[i].Close > 0 && data[i-1].Close > 0 {
+			returns = append(returns, math.Log(data[i].Close/data[i-1].Close))
+		}
+	}
+	return returns
+}
+
+// forecastLevels collects the supports, resistances and Wyckoff zones
+func forecastLevels(report *models.AnalysisReport) []forecastLevel {
+	levels := []forecastLevel{}
+	for i, price := range report.SupportResistance.SupportLevels {
+		levels = append(levels, forecastLevel{label: fmt.Sprintf("support_%d", i+1), kind: "support", zone: models.PriceRange{Min: price, Max: price}})
+	}
+	for i, price := range report.SupportResistance.ResistanceLevels {
+		levels = append(levels, forecastLevel{label: fmt.Sprintf("resistance_%d", i+1), kind: "resistance", zone: models.PriceRange{Min: price, Max: price}})
+	}
+	for _, zone := range []struct {
+		label string
+		zone  models.PriceRange
+	}{
+		{"buy_zone", report.Wyckoff.BuyZone},
+		{"accumulation_zone", report.Wyckoff.AccumulationZone},
+		{"distribution_zone", report.Wyckoff.DistributionZone},
+		{"sell_zone", report.Wyckoff.SellZone},
+	} {
+		if zone.zone.Max > 0 {
+			levels = append(levels, forecastLevel{label: zone.label, kind: "wyckoff_zone", zone: zone.zone})
+		}
+	}
+	return levels
+}
+
+// levelProbability describes a level relative to price. The barrier is the
+// zone's near edge in log distance from price: negative below, positive above
+func levelProbability(level forecastLevel, price float64) (models.LevelProbability, float64) {
+	result := models.LevelProbability{Label: level.label, Kind: level.kind}
+	if level.zone.Min != level.zone.Max {
+		zone := level.zone
+		result.Zone = &zone
+	}
+
+	switch {
+	case level.zone.Max < price:
+		result.Direction = "below"
+		result.Price = level.zone.Max
+	case level.zone.Min > price:
+		result.Direction = "above"
+		result.Price = level.zone.Min
+	default:
+		result.Direction = "inside"
+		result.Price = price
+	}
+	result.DistancePercent = (result.Price/price - 1) * 100
+	return result, math.Log(result.Price / price)
+}
+
+// historicalForecast treats log prices as a Brownian motion with the sample
+// volatility, so the bands and touch probabilities are closed form
+func historicalForecast(price float64, returns []float64, drift float64, horizon int, levels []forecastLevel) models.ForecastMethod {
+	sigma := sampleStdDev(returns)
+	method := newForecastMethod(ForecastHistorical, sigma, horizon)
+
+	for day := 1; day <= horizon; day++ {
+		t := float64(day)
+		values := make([]float64, len(forecastQuantiles))
+		for i, z := range forecastQuantiles {
+			values[i] = price * math.Exp(drift*t+z*sigma*math.Sqrt(t))
+		}
+		method.Bands = append(method.Bands, forecastBand(day, values))
+	}
+
+	T := float64(horizon)
+	spread := sigma * math.Sqrt(T)
+	endBelow := func(x float64) float64 { return normalCDF((x - drift*T) / spread) }
+	for _, level := range levels {
+		result, barrier := levelProbability(level, price)
+		switch result.Direction {
+		case "below":
+			// Reflection principle for the running minimum with drift
+			result.TouchProbability = endBelow(barrier) + math.Exp(2*drift*barrier/(sigma*sigma))*normalCDF((barrier+drift*T)/spread)
+			result.EndBeyondProbability = endBelow(barrier)
+		case "above":
+			result.TouchProbability = normalCDF((drift*T-barrier)/spread) + math.Exp(2*drift*barrier/(sigma*sigma))*normalCDF((-barrier-drift*T)/spread)
+			result.EndBeyondProbability = 1 - endBelow(barrier)
+		default:
+			result.TouchProbability = 1
+		}
+		if result.Zone != nil {
+			inside := endBelow(math.Log(result.Zone.Max/price)) - endBelow(math.Log(result.Zone.Min/price))
+			result.EndInsideProbability = &inside
+		}
+		method.Levels = append(method.Levels, asPercentages(result))
+	}
+	return method
+}
+
+// flatForecast is the degenerate forecast of a series without volatility: the
+// price follows the drift, so each level is touched with certainty or not at all
+func flatForecast(name string, price float64, drift float64, horizon int, levels []forecastLevel) models.ForecastMethod {
+	method := newForecastMethod(name, 0, horizon)
+	for day := 1; day <= horizon; day++ {
+		value := price * math.Exp(drift*float64(day))
+		method.Bands = append(method.Bands, forecastBand(day, []float64{value, value, value, value, value}))
+	}
+
+	end := drift * float64(horizon)
+	certain := func(ok bool) float64 {
+		if ok {
+			return 1
+		}
+		return 0
+	}
+	for _, level := range levels {
+		result, barrier := levelProbability(level, price)
+		switch result.Direction {
+		case "below":
+			result.TouchProbability = certain(end <= barrier)
+			result.EndBeyondProbability = certain(end < barrier)
+		case "above":
+			result.TouchProbability = certain(end >= barrier)
+			result.EndBeyondProbability = certain(end > barrier)
+		default:
+			result.TouchProbability = 1
+		}
+		if result.Zone != nil {
+			inside := certain(end >= math.Log(result.Zone.Min/price) && end <= math.Log(result.Zone.Max/price))
+			result.EndInsideProbability = &inside
+		}
+		method.Levels = append(method.Levels, asPercentages(result))
+	}
+	return method
+}
+
+// bootstrapForecast draws each day's return from the lookback's returns, so the
+// paths keep their fat tails and skew
+func bootstrapForecast(price float64, returns []float64, drift float64, cfg ForecastConfig, levels []forecastLevel, rng *rand.Rand) models.ForecastMethod {
+	mean := averageFloat64(returns)
+	sigma := sampleStdDev(returns)
+	method := newForecastMethod(ForecastBootstrap, sigma, cfg.Horizon)
+
+	step := func(path int, day int) (float64, float64) {
+		return returns[rng.Intn(len(returns))] - mean + drift, sigma
+	}
+	simulateForecast(&method, price, cfg, levels, step)
+	return method
+}
+
+// garchForecast fits GARCH(1,1) to the returns and simulates paths whose
+// volatility starts from today's conditional volatility and reverts to the
+// long-run level
+func garchForecast(price float64, returns []float64, drift float64, cfg ForecastConfig, levels []forecastLevel, rng *rand.Rand) models.ForecastMethod {
+	fit, next := fitGARCH(returns)
+
+	// Expected variance over the horizon: E[h(k)] reverts geometrically to the long run
+	longRun := fit.Omega / (1 - fit.Persistence)
+	total := 0.0
+	for k := 0; k < cfg.Horizon; k++ {
+		total += longRun + math.Pow(fit.Persistence, float64(k))*(next-longRun)
+	}
+	method := newForecastMethod(ForecastGARCH, math.Sqrt(total/float64(cfg.Horizon)), cfg.Horizon)
+	method.GARCH = &fit
+
+	variances := make([]float64, cfg.Simulations)
+	for i := range variances {
+		variances[i] = next
+	}
+	step := func(path int, day int) (float64, float64) {
+		h := variances[path]
+		shock := math.Sqrt(h) * rng.NormFloat64()
+		variances[path] = fit.Omega + fit.Alpha*shock*shock + fit.Beta*h
+		return drift + shock, math.Sqrt(h)
+	}
+	simulateForecast(&method, price, cfg, levels, step)
+	return method
+}
+
+// simulateForecast runs the paths day by day; step returns a path's log return
+// for the day and the volatility it was drawn with
+func simulateForecast(method *models.ForecastMethod, price float64, cfg ForecastConfig, levels []forecastLevel, step func(path, day int) (float64, float64)) {
+	results := make([]models.LevelProbability, len(levels))
+	barriers := make([]float64, len(levels))
+	for i, level := range levels {
+		results[i], barriers[i] = levelProbability(level, price)
+	}
+
+	paths := make([][]float64, cfg.Simulations)
+	touches := make([]float64, len(levels))
+	endBeyond := make([]int, len(levels))
+	endInside := make([]int, len(levels))
+	missed := make([]float64, len(levels)) // Probability the path has not touched yet
+
+	for s := range paths {
+		paths[s] = make([]float64, cfg.Horizon)
+		for i := range missed {
+			missed[i] = 1
+			if results[i].Direction == "inside" {
+				missed[i] = 0
+			}
+		}
+
+		x := 0.0 // Log price relative to today
+		for day := 0; day < cfg.Horizon; day++ {
+			r, sigma := step(s, day)
+			next := x + r
+			for i, b := range barriers {
+				if missed[i] == 0 {
+					continue
+				}
+				if (b < 0 && next <= b) || (b > 0 && next >= b) {
+					missed[i] = 0
+					continue
+				}
+				// Chance the price crossed the level between the two closes
+				if sigma > 0 {
+					missed[i] *= 1 - math.Exp(-2*(b-x)*(b-next)/(sigma*sigma))
+				}
+			}
+			x = next
+			paths[s][day] = price * math.Exp(x)
+		}
+
+		final := paths[s][cfg.Horizon-1]
+		for i, b := range barriers {
+			touches[i] += 1 - missed[i]
+			if (b < 0 && x <= b) || (b > 0 && x >= b) {
+				endBeyond[i]++
+			}
+			if results[i].Zone != nil && final >= results[i].Zone.Min && final <= results[i].Zone.Max {
+				endInside[i]++
+			}
+		}
+	}
+
+	column := make([]float64, len(paths))
+	for day := 0; day < cfg.Horizon; day++ {
+		for s, path := range paths {
+			column[s] = path[day]
+		}
+		sort.Float64s(column)
+		values := make([]float64, len(forecastPercentiles))
+		for i, p := range forecastPercentiles {
+			values[i] = interpolatePercentile(column, p)
+		}
+		method.Bands = append(method.Bands, forecastBand(day+1, values))
+	}
+
+	n := float64(len(paths))
+	for i := range results {
+		results[i].TouchProbability = touches[i] / n
+		results[i].EndBeyondProbability = float64(endBeyond[i]) / n
+		if results[i].Zone != nil {
+			inside := float64(endInside[i]) / n
+			results[i].EndInsideProbability = &inside
+		}
+		method.Levels = append(method.Levels, asPercentages(results[i]))
+	}
+}
+
+// fitGARCH fits h(t+1) = omega + alpha*e(t)^2 + beta*h(t) to the demeaned
+// returns by maximum Gaussian likelihood. Omega is tied to the sample variance
+// (variance targeting); alpha and beta start from the best point of a coarse
+// grid and are refined by a compass search. It also returns the conditional
+// variance of the next day
+func fitGARCH(returns []float64) (models.GARCHFit, float64) {
+	mean := averageFloat64(returns)
+	residuals := make([]float64, len(returns))
+	variance := 0.0
+	for i, r := range returns {
+		residuals[i] = r - mean
+		variance += residuals[i] * residuals[i]
+	}
+	variance /= float64(len(residuals))
+
+	valid := func(alpha, beta float64) bool {
+		return alpha >= 0 && beta >= 0 && alpha+beta < 0.999
+	}
+	logLikelihood := func(alpha, beta float64) (float64, float64) {
+		omega := variance * (1 - alpha - beta)
+		h, ll := variance, 0.0
+		for _, e := range residuals {
+			ll -= 0.5 * (math.Log(2*math.Pi) + math.Log(h) + e*e/h)
+			h = omega + alpha*e*e + beta*h
+		}
+		return ll, h
+	}
+
+	bestAlpha, bestBeta, bestLL := 0.0, 0.0, math.Inf(-1)
+	for _, alpha := range []float64{0.02, 0.05, 0.1, 0.15, 0.2, 0.3} {
+		for _, beta := range []float64{0.5, 0.6, 0.7, 0.8, 0.85, 0.9, 0.95, 0.97} {
+			if !valid(alpha, beta) {
+				continue
+			}
+			if ll, _ := logLikelihood(alpha, beta); ll > bestLL {
+				bestAlpha, bestBeta, bestLL = alpha, beta, ll
+			}
+		}
+	}
+
+	directions := [][2]float64{{1, 0}, {-1, 0}, {0, 1}, {0, -1}}
+	for stepSize, iterations := 0.05, 0; stepSize > 1e-6 && iterations < 10000; iterations++ {
+		improved := false
+		for _, d := range directions {
+			alpha, beta := bestAlpha+d[0]*stepSize, bestBeta+d[1]*stepSize
+			if !valid(alpha, beta) {
+				continue
+			}
+			if ll, _ := logLikelihood(alpha, beta); ll > bestLL {
+				bestAlpha, bestBeta, bestLL = alpha, beta, ll
+				improved = true
+			}
+		}
+		if !improved {
+			stepSize /= 2
+		}
+	}
+
+	_, next := logLikelihood(bestAlpha, bestBeta)
+	persistence := bestAlpha + bestBeta
+	fit := models.GARCHFit{
+		Omega:             variance * (1 - persistence),
+		Alpha:             bestAlpha,
+		Beta:              bestBeta,
+		Persistence:       persistence,
+		LongRunVolatility: math.Sqrt(variance) * 100,
+		NextVolatility:    math.Sqrt(next) * 100,
+		LogLikelihood:     bestLL,
+	}
+	if persistence > 0 {
+		fit.HalfLife = math.Log(0.5) / math.Log(persistence)
+	}
+	return fit, next
+}
+
+// newForecastMethod starts a method's result from its daily volatility
+func newForecastMethod(name string, dailySigma float64, horizon int) models.ForecastMethod {
+	return models.ForecastMethod{
+		Method:            name,
+		DailyVolatility:   dailySigma * 100,
+		AnnualVolatility:  dailySigma * math.Sqrt(250) * 100,
+		HorizonVolatility: dailySigma * math.Sqrt(float64(horizon)) * 100,
+		Bands:             []models.ForecastBand{},
+		Levels:            []models.LevelProbability{},
+	}
+}
+
+func forecastBand(day int, values []float64) models.ForecastBand {
+	return models.ForecastBand{Day: day, P5: values[0], P25: values[1], P50: values[2], P75: values[3], P95: values[4]}
+}
+
+// asPercentages converts a level's probabilities from fractions to percent
+func asPercentages(result models.LevelProbability) models.LevelProbability {
+	result.TouchProbability *= 100
+	result.EndBeyondProbability *= 100
+	if result.EndInsideProbability != nil {
+		inside := *result.EndInsideProbability * 100
+		result.EndInsideProbability = &inside
+	}
+	return result
+}
+
+// sampleStdDev is the standard deviation with the n-1 denominator
+func sampleStdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := averageFloat64(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
+
+// normalCDF is the standard normal cumulative distribution function
+func normalCDF(x float64) float64 {
+	return 0.5 * math.Erfc(-x/math.Sqrt2)
+}
+
+// interpolatePercentile interpolates the p-th percentile (0-100) of sorted values
+func interpolatePercentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
@@ -0,0 +1,126 @@
+package analysis
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"stocking-chain/internal/models"
+)
+
+// gaussianReturns draws n daily log returns with mean zero and deviation sigma
+func gaussianReturns(n int, sigma float64, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	returns := make([]float64, n)
+	for i := range returns {
+		returns[i] = rng.NormFloat64() * sigma
+	}
+	return returns
+}
+
+// testLevels are a support and a resistance 8% from a price of 100
+func testLevels() []forecastLevel {
+	return []forecastLevel{
+		{label: "support_1", kind: "support", zone: models.PriceRange{Min: 92, Max: 92}},
+		{label: "resistance_1", kind: "resistance", zone: models.PriceRange{Min: 108, Max: 108}},
+	}
+}
+
+func TestHistoricalTouchIsTwiceEndBeyondWithoutDrift(t *testing.T) {
+	method := historicalForecast(100, gaussianReturns(250, 0.02, 1), 0, 20, testLevels())
+	if len(method.Levels) != 2 {
+		t.Fatalf("got %d levels, want 2", len(method.Levels))
+	}
+	for _, level := range method.Levels {
+		// Reflection principle: a driftless path that ends beyond the barrier
+		// touched it, and each touching path is equally likely to end either side
+		want := 2 * level.EndBeyondProbability
+		if math.Abs(level.TouchProbability-want) > 1e-9 {
+			t.Errorf("%s: touch %.4f%%, want 2 × end beyond = %.4f%%", level.Label, level.TouchProbability, want)
+		}
+		if level.TouchProbability <= 0 || level.TouchProbability >= 100 {
+			t.Errorf("%s: touch %.4f%% out of range", level.Label, level.TouchProbability)
+		}
+	}
+}
+
+func TestBootstrapAgreesWithHistoricalOnGaussianReturns(t *testing.T) {
+	returns := gaussianReturns(2000, 0.02, 2)
+	cfg := DefaultForecastConfig()
+	cfg.Simulations = 8000
+
+	historical := historicalForecast(100, returns, 0, cfg.Horizon, testLevels())
+	bootstrap := bootstrapForecast(100, returns, 0, cfg, testLevels(), rand.New(rand.NewSource(3)))
+
+	last := cfg.Horizon - 1
+	h, b := historical.Bands[last], bootstrap.Bands[last]
+	for _, pair := range [][3]float64{{5, h.P5, b.P5}, {50, h.P50, b.P50}, {95, h.P95, b.P95}} {
+		if math.Abs(pair[1]-pair[2])/pair[1] > 0.01 {
+			t.Errorf("p%.0f on day %d: historical %.2f, bootstrap %.2f", pair[0], cfg.Horizon, pair[1], pair[2])
+		}
+	}
+
+	// The simulated touches include the Brownian-bridge crossings between closes,
+	// so they should match the continuous closed form, not undershoot it
+	for i := range historical.Levels {
+		hl, bl := historical.Levels[i], bootstrap.Levels[i]
+		if math.Abs(hl.TouchProbability-bl.TouchProbability) > 2 {
+			t.Errorf("%s touch: historical %.2f%%, bootstrap %.2f%%", hl.Label, hl.TouchProbability, bl.TouchProbability)
+		}
+		if math.Abs(hl.EndBeyondProbability-bl.EndBeyondProbability) > 2 {
+			t.Errorf("%s end beyond: historical %.2f%%, bootstrap %.2f%%", hl.Label, hl.EndBeyondProbability, bl.EndBeyondProbability)
+		}
+	}
+}
+
+func TestFitGARCHRecoversParameters(t *testing.T) {
+	const (
+		omega = 0.000008
+		alpha = 0.10
+		beta  = 0.85
+	)
+	rng := rand.New(rand.NewSource(4))
+	returns := make([]float64, 4000)
+	h := omega / (1 - alpha - beta)
+	for i := range returns {
+		returns[i] = math.Sqrt(h) * rng.NormFloat64()
+		h = omega + alpha*returns[i]*returns[i] + beta*h
+	}
+
+	fit, next := fitGARCH(returns)
+	if math.Abs(fit.Alpha-alpha) > 0.04 {
+		t.Errorf("alpha = %.3f, want %.2f ± 0.04", fit.Alpha, alpha)
+	}
+	if math.Abs(fit.Beta-beta) > 0.05 {
+		t.Errorf("beta = %.3f, want %.2f ± 0.05", fit.Beta, beta)
+	}
+	if fit.Persistence >= 1 {
+		t.Errorf("persistence = %.3f, want below 1", fit.Persistence)
+	}
+	if next <= 0 || math.IsNaN(next) || math.IsInf(fit.LogLikelihood, 0) {
+		t.Errorf("next variance %.3g, log likelihood %.3g", next, fit.LogLikelihood)
+	}
+}
+
+func TestFlatSeriesForecastEncodes(t *testing.T) {
+	report := &models.AnalysisReport{CurrentPrice: 100}
+	for i := 0; i < 80; i++ {
+		report.PriceHistory = append(report.PriceHistory, models.StockData{Close: 100})
+	}
+	cfg := DefaultForecastConfig()
+	cfg.Methods = []string{ForecastHistorical, ForecastBootstrap, ForecastGARCH}
+	forecast := ForecastPrices(report, cfg)
+	if len(forecast.Methods) != 3 || len(forecast.Notes) == 0 {
+		t.Fatalf("got %d methods and notes %v, want 3 degenerate methods with a note", len(forecast.Methods), forecast.Notes)
+	}
+	for _, method := range forecast.Methods {
+		if method.GARCH != nil {
+			t.Errorf("%s: GARCH fitted on a flat series", method.Method)
+		}
+		for _, band := range method.Bands {
+			if band.P5 != 100 || band.P95 != 100 {
+				t.Errorf("%s day %d: band %.2f-%.2f, want 100", method.Method, band.Day, band.P5, band.P95)
+			}
+		}
+	}
+}
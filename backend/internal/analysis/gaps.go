@@ -0,0 +1,166 @@
+package analysis
+
+import (
+	"math"
+	"stocking-chain/internal/models"
+)
+
+const (
+	// minGapSize ignores gaps smaller than this fraction of the prior close
+	minGapSize = 0.003
+	// gapContextBars is the lookback used for trend and range context
+	gapContextBars = 20
+	// exhaustionFillBars is how quickly an exhaustion gap is expected to fill
+	exhaustionFillBars = 5
+)
+
+// ============================================================================
+// GAP DETECTION
+// ============================================================================
+
+// DetectGaps finds gaps between consecutive bars, classifies them and tracks
+// how much of each gap subsequent trading has filled
+func DetectGaps(data []models.StockData) []models.PriceGap {
+	gaps := []models.PriceGap{}
+
+	for i := 1; i < len(data); i++ {
+		prev := data[i-1]
+		current := data[i]
+
+		var gap models.PriceGap
+		switch {
+		case current.Low > prev.High:
+			gap = models.PriceGap{Direction: "up", Low: prev.High, High: current.Low}
+		case current.High < prev.Low:
+			gap = models.PriceGap{Direction: "down", Low: current.High, High: prev.Low}
+		default:
+			continue
+		}
+
+		if prev.Close <= 0 {
+			continue
+		}
+		gap.Size = (gap.High - gap.Low) / prev.Close
+		if gap.Size < minGapSize {
+			continue
+		}
+
+		gap.Date = current.Date
+		gap.VolumeRatio = gapVolumeRatio(data, i)
+
+		fillIdx := trackGapFill(data, i, &gap)
+		gap.Kind = classifyGap(data, i, gap, fillIdx)
+
+		gaps = append(gaps, gap)
+	}
+
+	return gaps
+}
+
+// gapVolumeRatio compares the gap bar's volume to the average of the preceding bars
+func gapVolumeRatio(data []models.StockData, idx int) float64 {
+	start := max(0, idx-gapContextBars)
+	if idx-start == 0 {
+		return 1
+	}
+	avgVolume := calculateAverageVolume(data[start:idx], idx-start)
+	if avgVolume == 0 {
+		return 1
+	}
+	return float64(data[idx].Volume) / avgVolume
+}
+
+// trackGapFill walks the bars after the gap and records the fill progress.
+// It returns the index of the bar that completely filled the gap, or -1
+func trackGapFill(data []models.StockData, idx int, gap *models.PriceGap) int {
+	height := gap.High - gap.Low
+	deepest := 0.0
+
+	for j := idx + 1; j < len(data); j++ {
+		var covered float64
+		if gap.Direction == "up" {
+			covered = gap.High - data[j].Low
+		} else {
+			covered = data[j].High - gap.Low
+		}
+		deepest = math.Max(deepest, covered)
+
+		if deepest >= height {
+			filledDate := data[j].Date
+			gap.Filled = true
+			gap.FilledDate = &filledDate
+			gap.FillPercent = 1
+			return j
+		}
+	}
+
+	if height > 0 {
+		gap.FillPercent = math.Max(0, deepest/height)
+	}
+	return -1
+}
+
+// classifyGap labels a gap as breakaway, runaway, exhaustion or common
+// based on the preceding range, trend extension and volume
+func classifyGap(data []models.StockData, idx int, gap models.PriceGap, fillIdx int) string {
+	start := max(0, idx-gapContextBars)
+	context := data[start:idx]
+	if len(context) < 5 {
+		return "common"
+	}
+
+	up := gap.Direction == "up"
+	rangeHigh := maxHigh(context)
+	rangeLow := minLow(context)
+	rangeWidth := (rangeHigh - rangeLow) / rangeLow
+
+	// Prior move in the direction of the gap
+	priorMove := (context[len(context)-1].Close - context[0].Close) / context[0].Close
+	if !up {
+		priorMove = -priorMove
+	}
+
+	// Breakaway: leaves a consolidation on expanding volume
+	leavesRange := (up && gap.High > rangeHigh) || (!up && gap.Low < rangeLow)
+	if leavesRange && rangeWidth < 0.15 && priorMove < 0.05 && gap.VolumeRatio >= 1.3 {
+		return "breakaway"
+	}
+
+	// Exhaustion: comes late in an extended move and is quickly filled or
+	// printed on climactic volume
+	filledQuickly := fillIdx >= 0 && fillIdx-idx <= exhaustionFillBars
+	if priorMove >= 0.15 && (filledQuickly || gap.VolumeRatio >= 2.0) {
+		return "exhaustion"
+	}
+
+	// Runaway (measuring): continuation gap in the middle of an established trend
+	if priorMove >= 0.05 && gap.VolumeRatio >= 1.0 && !filledQuickly {
+		return "runaway"
+	}
+
+	return "common"
+}
+
+// unfilledGapLevels returns the nearest edge of every unfilled gap, split into
+// candidate supports (below price) and resistances (above price)
+func unfilledGapLevels(gaps []models.PriceGap, currentPrice float64) (supports, resistances []float64) {
+	for _, gap := range gaps {
+		if gap.Filled {
+			continue
+		}
+
+		// Only the part of the gap that remains open can act as a level
+		if gap.Direction == "up" {
+			openTop := gap.High - (gap.High-gap.Low)*gap.FillPercent
+			if openTop < currentPrice {
+				supports = append(supports, openTop)
+			}
+		} else {
+			openBottom := gap.Low + (gap.High-gap.Low)*gap.FillPercent
+			if openBottom > currentPrice {
+				resistances = append(resistances, openBottom)
+			}
+		}
+	}
+	return supports, resistances
+}
@@ -106,15 +106,53 @@ func CalculateBollingerBands(data []models.StockData, period int) (upper, middle
 	return upper, middle, lower
 }
 
+// CalculateATR returns the average true range over the last period bars
+func CalculateATR(data []models.StockData, period int) float64 {
+	if len(data) < period+1 {
+		return 0
+	}
+
+	total := 0.0
+	for i := len(data) - period; i < len(data); i++ {
+		total += trueRange(data[i], data[i-1])
+	}
+
+	return total / float64(period)
+}
+
+// trueRange returns the greatest of the bar's range and its gaps from the prior close
+func trueRange(current, prev models.StockData) float64 {
+	return math.Max(current.High-current.Low,
+		math.Max(math.Abs(current.High-prev.Close), math.Abs(current.Low-prev.Close)))
+}
+
+// IndicatorPeriods sets the lookbacks of the tunable indicators
+type IndicatorPeriods struct {
+	RSI       int
+	FastSMA   int // Reported as SMA20
+	SlowSMA   int // Reported as SMA50
+	Bollinger int
+}
+
+// DefaultIndicatorPeriods returns RSI 14, SMA 20/50 and Bollinger 20
+func DefaultIndicatorPeriods() IndicatorPeriods {
+	return DefaultAnalyzerConfig().indicatorPeriods()
+}
+
 func CalculateTechnicalIndicators(data []models.StockData) models.TechnicalIndicators {
-	rsi := CalculateRSI(data, 14)
+	return CalculateTechnicalIndicatorsWithPeriods(data, DefaultIndicatorPeriods())
+}
+
+// CalculateTechnicalIndicatorsWithPeriods calculates the indicators with custom lookbacks
+func CalculateTechnicalIndicatorsWithPeriods(data []models.StockData, periods IndicatorPeriods) models.TechnicalIndicators {
+	rsi := CalculateRSI(data, periods.RSI)
 	macd, signal, histogram := CalculateMACD(data)
-	sma20 := CalculateSMA(data, 20)
-	sma50 := CalculateSMA(data, 50)
+	sma20 := CalculateSMA(data, periods.FastSMA)
+	sma50 := CalculateSMA(data, periods.SlowSMA)
 	sma200 := CalculateSMA(data, 200)
 	ema12 := CalculateEMA(data, 12)
 	ema26 := CalculateEMA(data, 26)
-	upper, middle, lower := CalculateBollingerBands(data, 20)
+	upper, middle, lower := CalculateBollingerBands(data, periods.Bollinger)
 
 	return models.TechnicalIndicators{
 		RSI:            rsi,
@@ -0,0 +1,134 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// PatternConfig holds every shape threshold used by the candlestick detectors.
+// Ratios are relative to the candle's total range unless noted otherwise
+type PatternConfig struct {
+	// Doji family
+	DojiBodyRatio        float64 `json:"doji_body_ratio"`         // Max body/range for a doji
+	DojiLongShadowRatio  float64 `json:"doji_long_shadow_ratio"`  // Min long shadow/range for dragonfly/gravestone
+	DojiShortShadowRatio float64 `json:"doji_short_shadow_ratio"` // Max short shadow/range for dragonfly/gravestone
+
+	// Spinning top
+	SpinningTopMaxBodyRatio  float64 `json:"spinning_top_max_body_ratio"`
+	SpinningTopMinBodyRatio  float64 `json:"spinning_top_min_body_ratio"`
+	SpinningTopShadowToBody  float64 `json:"spinning_top_shadow_to_body"` // Min each shadow/body
+	SpinningTopShadowBalance float64 `json:"spinning_top_shadow_balance"` // Max |upper-lower|/range
+
+	// Marubozu
+	MarubozuMinBodyRatio   float64 `json:"marubozu_min_body_ratio"`
+	MarubozuMaxShadowRatio float64 `json:"marubozu_max_shadow_ratio"`
+
+	// Hammer, inverted hammer, hanging man, shooting star (relative to body size)
+	LongShadowToBody  float64 `json:"long_shadow_to_body"`
+	ShortShadowToBody float64 `json:"short_shadow_to_body"`
+	TrendLookback     int     `json:"trend_lookback"` // Bars used by isInUptrend/isInDowntrend
+
+	// Two and three candle patterns
+	HaramiMaxBodyRatio      float64 `json:"harami_max_body_ratio"`       // Max second body/first body
+	TweezerTolerance        float64 `json:"tweezer_tolerance"`           // Max high/low difference as a fraction of price
+	StarMaxBodyRatio        float64 `json:"star_max_body_ratio"`         // Max star body/first body
+	SoldiersMaxShadowToBody float64 `json:"soldiers_max_shadow_to_body"` // Max closing-side shadow/body
+
+	// Kicker, belt hold, counterattack, three methods and mat hold
+	LongBodyRatio          float64 `json:"long_body_ratio"`            // Min body/range for a "long" candle
+	BeltHoldMaxShadowRatio float64 `json:"belt_hold_max_shadow_ratio"` // Max opening-side shadow/range
+	CounterattackTolerance float64 `json:"counterattack_tolerance"`    // Max close difference as a fraction of price
+	MethodsMaxBodyRatio    float64 `json:"methods_max_body_ratio"`     // Max inner body/first body
+}
+
+// DefaultPatternConfig returns the thresholds the detectors were originally tuned with
+func DefaultPatternConfig() PatternConfig {
+	return PatternConfig{
+		DojiBodyRatio:        0.1,
+		DojiLongShadowRatio:  0.7,
+		DojiShortShadowRatio: 0.1,
+
+		SpinningTopMaxBodyRatio:  0.3,
+		SpinningTopMinBodyRatio:  0.05,
+		SpinningTopShadowToBody:  0.5,
+		SpinningTopShadowBalance: 0.3,
+
+		MarubozuMinBodyRatio:   0.95,
+		MarubozuMaxShadowRatio: 0.03,
+
+		LongShadowToBody:  2.0,
+		ShortShadowToBody: 0.5,
+		TrendLookback:     5,
+
+		HaramiMaxBodyRatio:      0.5,
+		TweezerTolerance:        0.002,
+		StarMaxBodyRatio:        0.3,
+		SoldiersMaxShadowToBody: 0.3,
+
+		LongBodyRatio:          0.6,
+		BeltHoldMaxShadowRatio: 0.05,
+		CounterattackTolerance: 0.003,
+		MethodsMaxBodyRatio:    0.5,
+	}
+}
+
+// Validate checks that the ratios and tolerances are fractions, the shadow
+// multiples positive and the paired bounds ordered
+func (c PatternConfig) Validate() error {
+	for name, value := range map[string]float64{
+		"doji_body_ratio":             c.DojiBodyRatio,
+		"doji_long_shadow_ratio":      c.DojiLongShadowRatio,
+		"doji_short_shadow_ratio":     c.DojiShortShadowRatio,
+		"spinning_top_max_body_ratio": c.SpinningTopMaxBodyRatio,
+		"spinning_top_min_body_ratio": c.SpinningTopMinBodyRatio,
+		"spinning_top_shadow_balance": c.SpinningTopShadowBalance,
+		"marubozu_min_body_ratio":     c.MarubozuMinBodyRatio,
+		"marubozu_max_shadow_ratio":   c.MarubozuMaxShadowRatio,
+		"harami_max_body_ratio":       c.HaramiMaxBodyRatio,
+		"tweezer_tolerance":           c.TweezerTolerance,
+		"star_max_body_ratio":         c.StarMaxBodyRatio,
+		"long_body_ratio":             c.LongBodyRatio,
+		"belt_hold_max_shadow_ratio":  c.BeltHoldMaxShadowRatio,
+		"counterattack_tolerance":     c.CounterattackTolerance,
+		"methods_max_body_ratio":      c.MethodsMaxBodyRatio,
+	} {
+		if value <= 0 || value > 1 {
+			return fmt.Errorf("%s must be above 0 and at most 1, got %.4g", name, value)
+		}
+	}
+	for name, value := range map[string]float64{
+		"spinning_top_shadow_to_body": c.SpinningTopShadowToBody,
+		"long_shadow_to_body":         c.LongShadowToBody,
+		"short_shadow_to_body":        c.ShortShadowToBody,
+		"soldiers_max_shadow_to_body": c.SoldiersMaxShadowToBody,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("%s must be positive, got %.4g", name, value)
+		}
+	}
+	if c.SpinningTopMinBodyRatio >= c.SpinningTopMaxBodyRatio {
+		return fmt.Errorf("spinning_top_min_body_ratio (%.4g) must be below spinning_top_max_body_ratio (%.4g)", c.SpinningTopMinBodyRatio, c.SpinningTopMaxBodyRatio)
+	}
+	if c.ShortShadowToBody >= c.LongShadowToBody {
+		return fmt.Errorf("short_shadow_to_body (%.4g) must be below long_shadow_to_body (%.4g)", c.ShortShadowToBody, c.LongShadowToBody)
+	}
+	if c.TrendLookback < 1 {
+		return fmt.Errorf("trend_lookback must be at least 1, got %d", c.TrendLookback)
+	}
+	return nil
+}
+
+// LoadPatternConfig overlays the JSON file at path on the default thresholds,
+// so the file only needs the fields being changed
+func LoadPatternConfig(path string) (PatternConfig, error) {
+	cfg := DefaultPatternConfig()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return cfg, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return cfg, cfg.Validate()
+}
@@ -0,0 +1,242 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// PATTERN PROFILES
+// ============================================================================
+
+// patternProfile describes how many bars a pattern spans and what it signals
+type patternProfile struct {
+	bars int
+	role string // "reversal", "continuation", "indecision"
+}
+
+// patternProfiles maps each candlestick pattern name to its profile
+var patternProfiles = map[string]patternProfile{
+	"Dragonfly Doji":       {bars: 1, role: "reversal"},
+	"Gravestone Doji":      {bars: 1, role: "reversal"},
+	"Doji":                 {bars: 1, role: "indecision"},
+	"Spinning Top":         {bars: 1, role: "indecision"},
+	"Bullish Marubozu":     {bars: 1, role: "continuation"},
+	"Bearish Marubozu":     {bars: 1, role: "continuation"},
+	"Hanging Man":          {bars: 1, role: "reversal"},
+	"Hammer":               {bars: 1, role: "reversal"},
+	"Inverted Hammer":      {bars: 1, role: "reversal"},
+	"Shooting Star":        {bars: 1, role: "reversal"},
+	"Bullish Engulfing":    {bars: 2, role: "reversal"},
+	"Bearish Engulfing":    {bars: 2, role: "reversal"},
+	"Piercing Line":        {bars: 2, role: "reversal"},
+	"Dark Cloud Cover":     {bars: 2, role: "reversal"},
+	"Bullish Harami":       {bars: 2, role: "reversal"},
+	"Bearish Harami":       {bars: 2, role: "reversal"},
+	"Tweezer Top":          {bars: 2, role: "reversal"},
+	"Tweezer Bottom":       {bars: 2, role: "reversal"},
+	"Morning Star":         {bars: 3, role: "reversal"},
+	"Evening Star":         {bars: 3, role: "reversal"},
+	"Three White Soldiers": {bars: 3, role: "reversal"},
+	"Three Black Crows":    {bars: 3, role: "reversal"},
+	"Three Inside Up":      {bars: 3, role: "reversal"},
+	"Three Inside Down":    {bars: 3, role: "reversal"},
+	"Three Outside Up":     {bars: 3, role: "reversal"},
+	"Three Outside Down":   {bars: 3, role: "reversal"},
+
+	"Bullish Belt Hold":         {bars: 1, role: "reversal"},
+	"Bearish Belt Hold":         {bars: 1, role: "reversal"},
+	"Bullish Kicker":            {bars: 2, role: "reversal"},
+	"Bearish Kicker":            {bars: 2, role: "reversal"},
+	"Bullish Counterattack":     {bars: 2, role: "reversal"},
+	"Bearish Counterattack":     {bars: 2, role: "reversal"},
+	"Bullish Abandoned Baby":    {bars: 3, role: "reversal"},
+	"Bearish Abandoned Baby":    {bars: 3, role: "reversal"},
+	"Upside Tasuki Gap":         {bars: 3, role: "continuation"},
+	"Downside Tasuki Gap":       {bars: 3, role: "continuation"},
+	"Bullish Three Line Strike": {bars: 4, role: "reversal"},
+	"Bearish Three Line Strike": {bars: 4, role: "reversal"},
+	"Rising Three Methods":      {bars: 5, role: "continuation"},
+	"Falling Three Methods":     {bars: 5, role: "continuation"},
+	"Mat Hold":                  {bars: 5, role: "continuation"},
+}
+
+// profileFor returns the profile for a pattern, defaulting to a one-bar reversal
+func profileFor(name string) patternProfile {
+	if profile, ok := patternProfiles[name]; ok {
+		return profile
+	}
+	return patternProfile{bars: 1, role: "reversal"}
+}
+
+// ============================================================================
+// CONTEXT ADJUSTMENTS
+// ============================================================================
+
+// PatternContextOptions controls which context checks adjust pattern confidence
+type PatternContextOptions struct {
+	UseVolume bool `json:"use_volume"` // Boost or penalize on the relative volume of the pattern's bars
+	UseTrend  bool `json:"use_trend"`  // Boost or penalize on the AnalyzeTrend result preceding the pattern
+}
+
+// DefaultPatternContextOptions enables both volume and trend context
+func DefaultPatternContextOptions() PatternContextOptions {
+	return PatternContextOptions{UseVolume: true, UseTrend: true}
+}
+
+// applyPatternContext adjusts each pattern's confidence for volume confirmation
+// and trend context, recording the reason for every adjustment
+func applyPatternContext(patterns []models.CandlestickPattern, data []models.StockData, opts PatternContextOptions) []models.CandlestickPattern {
+	if len(data) == 0 {
+		return patterns
+	}
+
+	// Patterns of the same length share their bars, so each length is measured once
+	relVolumes := map[int]float64{}
+
+	for i := range patterns {
+		p := &patterns[i]
+		p.BaseConfidence = p.Confidence
+		profile := profileFor(p.Name)
+
+		relVolume, ok := relVolumes[profile.bars]
+		if !ok {
+			relVolume = patternRelativeVolume(data, profile.bars, 20)
+			relVolumes[profile.bars] = relVolume
+		}
+		p.RelativeVolume = relVolume
+
+		if opts.UseVolume && p.Type != "neutral" {
+			if adj := volumeAdjustment(relVolume); adj != nil {
+				p.Adjustments = append(p.Adjustments, *adj)
+			}
+		}
+
+		if opts.UseTrend && len(data) > profile.bars {
+			trend := AnalyzeTrend(data[:len(data)-profile.bars])
+			if adj := trendAdjustment(p.Type, profile.role, trend); adj != nil {
+				p.Adjustments = append(p.Adjustments, *adj)
+			}
+		}
+
+		confidence := p.BaseConfidence
+		for _, adj := range p.Adjustments {
+			confidence += adj.Delta
+		}
+		p.Confidence = math.Max(0.05, math.Min(confidence, 0.95))
+	}
+
+	return patterns
+}
+
+// relativeVolume compares a bar's volume to the average of the preceding lookback bars.
+// It returns 0 when no volume data is available
+func relativeVolume(data []models.StockData, idx int, lookback int) float64 {
+	start := max(0, idx-lookback)
+	if idx-start == 0 {
+		return 0
+	}
+	avgVolume := calculateAverageVolume(data[start:idx], idx-start)
+	if avgVolume == 0 {
+		return 0
+	}
+	return float64(data[idx].Volume) / avgVolume
+}
+
+// patternRelativeVolume compares the average volume of the last bars bars, the
+// ones a pattern spans, to the average of the lookback bars before them.
+// It returns 0 when no volume data is available
+func patternRelativeVolume(data []models.StockData, bars int, lookback int) float64 {
+	first := max(0, len(data)-bars)
+	start := max(0, first-lookback)
+	if first-start == 0 {
+		return 0
+	}
+	avgVolume := calculateAverageVolume(data[start:first], first-start)
+	if avgVolume == 0 {
+		return 0
+	}
+	return calculateAverageVolume(data[first:], len(data)-first) / avgVolume
+}
+
+// volumeAdjustment boosts patterns printed on heavy volume and penalizes light volume
+func volumeAdjustment(relVolume float64) *models.PatternAdjustment {
+	switch {
+	case relVolume == 0:
+		return nil
+	case relVolume >= 2.0:
+		return &models.PatternAdjustment{
+			Factor: "volume",
+			Delta:  0.15,
+			Reason: fmt.Sprintf("volume %.1fx the 20-bar average strongly confirms the pattern", relVolume),
+		}
+	case relVolume >= 1.5:
+		return &models.PatternAdjustment{
+			Factor: "volume",
+			Delta:  0.1,
+			Reason: fmt.Sprintf("volume %.1fx the 20-bar average confirms the pattern", relVolume),
+		}
+	case relVolume <= 0.7:
+		return &models.PatternAdjustment{
+			Factor: "volume",
+			Delta:  -0.1,
+			Reason: fmt.Sprintf("light volume (%.1fx the 20-bar average) weakens the pattern", relVolume),
+		}
+	}
+	return nil
+}
+
+// trendAdjustment checks that reversal patterns have a prior trend to reverse
+// and that continuation patterns agree with the prevailing trend
+func trendAdjustment(patternType, role string, trend models.TrendAnalysis) *models.PatternAdjustment {
+	if patternType == "neutral" || role == "indecision" {
+		return nil
+	}
+
+	bullish := patternType == "bullish"
+	aligned := (bullish && trend.Trend == "uptrend") || (!bullish && trend.Trend == "downtrend")
+	opposed := (bullish && trend.Trend == "downtrend") || (!bullish && trend.Trend == "uptrend")
+	magnitude := 0.05 + 0.1*trend.Strength
+
+	switch role {
+	case "reversal":
+		switch {
+		case opposed:
+			return &models.PatternAdjustment{
+				Factor: "trend",
+				Delta:  magnitude,
+				Reason: fmt.Sprintf("reversal pattern follows the prior %s (strength %.2f)", trend.Trend, trend.Strength),
+			}
+		case aligned:
+			return &models.PatternAdjustment{
+				Factor: "trend",
+				Delta:  -magnitude,
+				Reason: fmt.Sprintf("%s reversal pattern inside an existing %s has nothing to reverse", patternType, trend.Trend),
+			}
+		default:
+			return &models.PatternAdjustment{
+				Factor: "trend",
+				Delta:  -0.05,
+				Reason: "no prior trend for the reversal pattern to reverse",
+			}
+		}
+	case "continuation":
+		switch {
+		case aligned:
+			return &models.PatternAdjustment{
+				Factor: "trend",
+				Delta:  magnitude / 2,
+				Reason: fmt.Sprintf("continuation pattern agrees with the %s", trend.Trend),
+			}
+		case opposed:
+			return &models.PatternAdjustment{
+				Factor: "trend",
+				Delta:  -0.05,
+				Reason: fmt.Sprintf("%s continuation pattern runs against the %s", patternType, trend.Trend),
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,115 @@
+package analysis
+
+import (
+	"sort"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// PATTERN TUNING HARNESS
+// ============================================================================
+
+// PatternFixture is a labeled candle sequence whose last bar completes the expected patterns
+type PatternFixture struct {
+	Name     string             `json:"name"`
+	Candles  []models.StockData `json:"candles"`
+	Expected []string           `json:"expected"`
+}
+
+// DetectorScore holds the confusion counts and derived metrics for one detector
+type DetectorScore struct {
+	Pattern        string  `json:"pattern"`
+	TruePositives  int     `json:"true_positives"`
+	FalsePositives int     `json:"false_positives"`
+	FalseNegatives int     `json:"false_negatives"`
+	Precision      float64 `json:"precision"` // 0 when the detector never fired
+	Recall         float64 `json:"recall"`    // 0 when the pattern was never labeled
+	F1             float64 `json:"f1"`
+}
+
+// FixtureMismatch lists what a detector run got wrong for a single fixture
+type FixtureMismatch struct {
+	Fixture    string   `json:"fixture"`
+	Missing    []string `json:"missing,omitempty"`
+	Unexpected []string `json:"unexpected,omitempty"`
+}
+
+// PatternEvaluation is the result of replaying a fixture set through the detectors
+type PatternEvaluation struct {
+	Fixtures   int               `json:"fixtures"`
+	Detectors  []DetectorScore   `json:"detectors"`
+	Mismatches []FixtureMismatch `json:"mismatches"`
+}
+
+// EvaluatePatternConfig replays every fixture through the candlestick detectors with
+// the given thresholds and reports precision and recall per detector
+func EvaluatePatternConfig(cfg PatternConfig, fixtures []PatternFixture) PatternEvaluation {
+	scores := map[string]*DetectorScore{}
+	for name := range patternProfiles {
+		scores[name] = &DetectorScore{Pattern: name}
+	}
+	score := func(name string) *DetectorScore {
+		if _, ok := scores[name]; !ok {
+			scores[name] = &DetectorScore{Pattern: name}
+		}
+		return scores[name]
+	}
+
+	evaluation := PatternEvaluation{
+		Fixtures:   len(fixtures),
+		Detectors:  []DetectorScore{},
+		Mismatches: []FixtureMismatch{},
+	}
+
+	for _, fixture := range fixtures {
+		detected := map[string]bool{}
+		for _, p := range DetectCandlestickPatternsWithConfig(fixture.Candles, cfg) {
+			detected[p.Name] = true
+		}
+		expected := map[string]bool{}
+		for _, name := range fixture.Expected {
+			expected[name] = true
+		}
+
+		mismatch := FixtureMismatch{Fixture: fixture.Name}
+		for name := range expected {
+			if detected[name] {
+				score(name).TruePositives++
+			} else {
+				score(name).FalseNegatives++
+				mismatch.Missing = append(mismatch.Missing, name)
+			}
+		}
+		for name := range detected {
+			if !expected[name] {
+				score(name).FalsePositives++
+				mismatch.Unexpected = append(mismatch.Unexpected, name)
+			}
+		}
+
+		if len(mismatch.Missing) > 0 || len(mismatch.Unexpected) > 0 {
+			sort.Strings(mismatch.Missing)
+			sort.Strings(mismatch.Unexpected)
+			evaluation.Mismatches = append(evaluation.Mismatches, mismatch)
+		}
+	}
+
+	for _, s := range scores {
+		if s.TruePositives+s.FalsePositives > 0 {
+			s.Precision = float64(s.TruePositives) / float64(s.TruePositives+s.FalsePositives)
+		}
+		if s.TruePositives+s.FalseNegatives > 0 {
+			s.Recall = float64(s.TruePositives) / float64(s.TruePositives+s.FalseNegatives)
+		}
+		if s.Precision+s.Recall > 0 {
+			s.F1 = 2 * s.Precision * s.Recall / (s.Precision + s.Recall)
+		}
+		evaluation.Detectors = append(evaluation.Detectors, *s)
+	}
+
+	sort.Slice(evaluation.Detectors, func(i, j int) bool {
+		return evaluation.Detectors[i].Pattern < evaluation.Detectors[j].Pattern
+	})
+
+	return evaluation
+}
@@ -73,6 +73,15 @@ func isInDowntrend(data []models.StockData, lookback int) bool {
 	return end < start
 }
 
+// isLongBody checks if the body fills most of the candle's range
+func isLongBody(candle models.StockData, cfg PatternConfig) bool {
+	r := totalRange(candle)
+	if r == 0 {
+		return false
+	}
+	return bodySize(candle)/r >= cfg.LongBodyRatio
+}
+
 // almostEqual checks if two prices are approximately equal (within tolerance)
 func almostEqual(a, b, tolerance float64) bool {
 	return math.Abs(a-b) <= tolerance
@@ -83,17 +92,17 @@ func almostEqual(a, b, tolerance float64) bool {
 // ============================================================================
 
 // isDoji detects a Doji pattern (very small body)
-func isDoji(candle models.StockData) bool {
+func isDoji(candle models.StockData, cfg PatternConfig) bool {
 	r := totalRange(candle)
 	if r == 0 {
 		return false
 	}
-	return bodySize(candle)/r < 0.1
+	return bodySize(candle)/r < cfg.DojiBodyRatio
 }
 
 // isDragonflyDoji detects a Dragonfly Doji (bullish reversal signal)
 // Long lower shadow, no upper shadow, tiny body at top
-func isDragonflyDoji(candle models.StockData) bool {
+func isDragonflyDoji(candle models.StockData, cfg PatternConfig) bool {
 	r := totalRange(candle)
 	if r == 0 {
 		return false
@@ -102,12 +111,12 @@ func isDragonflyDoji(candle models.StockData) bool {
 	lower := lowerShadow(candle)
 	upper := upperShadow(candle)
 
-	return body/r < 0.1 && lower > r*0.7 && upper < r*0.1
+	return body/r < cfg.DojiBodyRatio && lower > r*cfg.DojiLongShadowRatio && upper < r*cfg.DojiShortShadowRatio
 }
 
 // isGravestoneDoji detects a Gravestone Doji (bearish reversal signal)
 // Long upper shadow, no lower shadow, tiny body at bottom
-func isGravestoneDoji(candle models.StockData) bool {
+func isGravestoneDoji(candle models.StockData, cfg PatternConfig) bool {
 	r := totalRange(candle)
 	if r == 0 {
 		return false
@@ -116,12 +125,12 @@ func isGravestoneDoji(candle models.StockData) bool {
 	lower := lowerShadow(candle)
 	upper := upperShadow(candle)
 
-	return body/r < 0.1 && upper > r*0.7 && lower < r*0.1
+	return body/r < cfg.DojiBodyRatio && upper > r*cfg.DojiLongShadowRatio && lower < r*cfg.DojiShortShadowRatio
 }
 
 // isSpinningTop detects a Spinning Top pattern (indecision)
 // Small body with upper and lower shadows roughly equal
-func isSpinningTop(candle models.StockData) bool {
+func isSpinningTop(candle models.StockData, cfg PatternConfig) bool {
 	r := totalRange(candle)
 	if r == 0 {
 		return false
@@ -130,18 +139,18 @@ func isSpinningTop(candle models.StockData) bool {
 	upper := upperShadow(candle)
 	lower := lowerShadow(candle)
 
-	// Body should be small (less than 30% of range)
+	// Body should be small (less than 30% of range by default)
 	// Both shadows should exist and be roughly similar
-	smallBody := body/r < 0.3 && body/r > 0.05
-	hasShadows := upper > body*0.5 && lower > body*0.5
-	shadowsBalanced := math.Abs(upper-lower) < r*0.3
+	smallBody := body/r < cfg.SpinningTopMaxBodyRatio && body/r > cfg.SpinningTopMinBodyRatio
+	hasShadows := upper > body*cfg.SpinningTopShadowToBody && lower > body*cfg.SpinningTopShadowToBody
+	shadowsBalanced := math.Abs(upper-lower) < r*cfg.SpinningTopShadowBalance
 
 	return smallBody && hasShadows && shadowsBalanced
 }
 
 // isBullishMarubozu detects a Bullish Marubozu (strong bullish signal)
 // Full bullish body with very small or no shadows
-func isBullishMarubozu(candle models.StockData) bool {
+func isBullishMarubozu(candle models.StockData, cfg PatternConfig) bool {
 	if !isBullish(candle) {
 		return false
 	}
@@ -153,13 +162,13 @@ func isBullishMarubozu(candle models.StockData) bool {
 	upper := upperShadow(candle)
 	lower := lowerShadow(candle)
 
-	// Body should be at least 95% of the range
-	return body/r > 0.95 && upper < r*0.03 && lower < r*0.03
+	// Body should be at least 95% of the range by default
+	return body/r > cfg.MarubozuMinBodyRatio && upper < r*cfg.MarubozuMaxShadowRatio && lower < r*cfg.MarubozuMaxShadowRatio
 }
 
 // isBearishMarubozu detects a Bearish Marubozu (strong bearish signal)
 // Full bearish body with very small or no shadows
-func isBearishMarubozu(candle models.StockData) bool {
+func isBearishMarubozu(candle models.StockData, cfg PatternConfig) bool {
 	if !isBearish(candle) {
 		return false
 	}
@@ -171,13 +180,13 @@ func isBearishMarubozu(candle models.StockData) bool {
 	upper := upperShadow(candle)
 	lower := lowerShadow(candle)
 
-	// Body should be at least 95% of the range
-	return body/r > 0.95 && upper < r*0.03 && lower < r*0.03
+	// Body should be at least 95% of the range by default
+	return body/r > cfg.MarubozuMinBodyRatio && upper < r*cfg.MarubozuMaxShadowRatio && lower < r*cfg.MarubozuMaxShadowRatio
 }
 
 // isHammer detects a Hammer pattern (bullish reversal at bottom)
 // Small body at top, long lower shadow, minimal upper shadow
-func isHammer(candle models.StockData) bool {
+func isHammer(candle models.StockData, cfg PatternConfig) bool {
 	body := bodySize(candle)
 	if body == 0 {
 		return false
@@ -185,12 +194,12 @@ func isHammer(candle models.StockData) bool {
 	lower := lowerShadow(candle)
 	upper := upperShadow(candle)
 
-	return lower > body*2 && upper < body*0.5
+	return lower > body*cfg.LongShadowToBody && upper < body*cfg.ShortShadowToBody
 }
 
 // isInvertedHammer detects an Inverted Hammer pattern (bullish reversal at bottom)
 // Small body at bottom, long upper shadow, minimal lower shadow
-func isInvertedHammer(candle models.StockData, data []models.StockData) bool {
+func isInvertedHammer(candle models.StockData, data []models.StockData, cfg PatternConfig) bool {
 	body := bodySize(candle)
 	if body == 0 {
 		return false
@@ -198,17 +207,17 @@ func isInvertedHammer(candle models.StockData, data []models.StockData) bool {
 	upper := upperShadow(candle)
 	lower := lowerShadow(candle)
 
-	hasShape := upper > body*2 && lower < body*0.5
+	hasShape := upper > body*cfg.LongShadowToBody && lower < body*cfg.ShortShadowToBody
 
 	// Must be in a downtrend for it to be an inverted hammer
-	inDowntrend := isInDowntrend(data, 5)
+	inDowntrend := isInDowntrend(data, cfg.TrendLookback)
 
 	return hasShape && inDowntrend
 }
 
 // isHangingMan detects a Hanging Man pattern (bearish reversal at top)
 // Same shape as hammer but appears after an uptrend
-func isHangingMan(candle models.StockData, data []models.StockData) bool {
+func isHangingMan(candle models.StockData, data []models.StockData, cfg PatternConfig) bool {
 	body := bodySize(candle)
 	if body == 0 {
 		return false
@@ -216,17 +225,17 @@ func isHangingMan(candle models.StockData, data []models.StockData) bool {
 	lower := lowerShadow(candle)
 	upper := upperShadow(candle)
 
-	hasHammerShape := lower > body*2 && upper < body*0.5
+	hasHammerShape := lower > body*cfg.LongShadowToBody && upper < body*cfg.ShortShadowToBody
 
 	// Must be in an uptrend for it to be a hanging man
-	inUptrend := isInUptrend(data, 5)
+	inUptrend := isInUptrend(data, cfg.TrendLookback)
 
 	return hasHammerShape && inUptrend
 }
 
 // isShootingStar detects a Shooting Star pattern (bearish reversal at top)
 // Small body at bottom, long upper shadow, minimal lower shadow
-func isShootingStar(candle models.StockData) bool {
+func isShootingStar(candle models.StockData, cfg PatternConfig) bool {
 	body := bodySize(candle)
 	if body == 0 {
 		return false
@@ -234,7 +243,43 @@ func isShootingStar(candle models.StockData) bool {
 	upper := upperShadow(candle)
 	lower := lowerShadow(candle)
 
-	return upper > body*2 && lower < body*0.5
+	return upper > body*cfg.LongShadowToBody && lower < body*cfg.ShortShadowToBody
+}
+
+// isBullishBeltHold detects a Bullish Belt Hold (bullish reversal at bottom)
+// Long bullish candle opening at its low, gapping below the prior low in a downtrend
+func isBullishBeltHold(prev, current models.StockData, data []models.StockData, cfg PatternConfig) bool {
+	if !isBullish(current) {
+		return false
+	}
+	r := totalRange(current)
+	if r == 0 {
+		return false
+	}
+
+	longBody := bodySize(current)/r >= cfg.LongBodyRatio
+	opensAtLow := lowerShadow(current) <= r*cfg.BeltHoldMaxShadowRatio
+	opensLower := current.Open < prev.Low
+
+	return longBody && opensAtLow && opensLower && isInDowntrend(data[:len(data)-1], cfg.TrendLookback)
+}
+
+// isBearishBeltHold detects a Bearish Belt Hold (bearish reversal at top)
+// Long bearish candle opening at its high, gapping above the prior high in an uptrend
+func isBearishBeltHold(prev, current models.StockData, data []models.StockData, cfg PatternConfig) bool {
+	if !isBearish(current) {
+		return false
+	}
+	r := totalRange(current)
+	if r == 0 {
+		return false
+	}
+
+	longBody := bodySize(current)/r >= cfg.LongBodyRatio
+	opensAtHigh := upperShadow(current) <= r*cfg.BeltHoldMaxShadowRatio
+	opensHigher := current.Open > prev.High
+
+	return longBody && opensAtHigh && opensHigher && isInUptrend(data[:len(data)-1], cfg.TrendLookback)
 }
 
 // ============================================================================
@@ -305,7 +350,7 @@ func isDarkCloudCover(prev, current models.StockData) bool {
 
 // isBullishHarami detects a Bullish Harami pattern (bullish reversal)
 // A bearish candle followed by a smaller bullish candle contained within it
-func isBullishHarami(prev, current models.StockData) bool {
+func isBullishHarami(prev, current models.StockData, cfg PatternConfig) bool {
 	if !isBearish(prev) || !isBullish(current) {
 		return false
 	}
@@ -314,14 +359,14 @@ func isBullishHarami(prev, current models.StockData) bool {
 	currentBody := bodySize(current)
 
 	// Current body must be smaller and contained within previous body
-	return currentBody < prevBody*0.5 &&
+	return currentBody < prevBody*cfg.HaramiMaxBodyRatio &&
 		current.Open > prev.Close &&
 		current.Close < prev.Open
 }
 
 // isBearishHarami detects a Bearish Harami pattern (bearish reversal)
 // A bullish candle followed by a smaller bearish candle contained within it
-func isBearishHarami(prev, current models.StockData) bool {
+func isBearishHarami(prev, current models.StockData, cfg PatternConfig) bool {
 	if !isBullish(prev) || !isBearish(current) {
 		return false
 	}
@@ -330,44 +375,100 @@ func isBearishHarami(prev, current models.StockData) bool {
 	currentBody := bodySize(current)
 
 	// Current body must be smaller and contained within previous body
-	return currentBody < prevBody*0.5 &&
+	return currentBody < prevBody*cfg.HaramiMaxBodyRatio &&
 		current.Open < prev.Close &&
 		current.Close > prev.Open
 }
 
 // isTweezerTop detects a Tweezer Top pattern (bearish reversal)
 // Two candles with nearly identical highs, first bullish, second bearish
-func isTweezerTop(prev, current models.StockData) bool {
+func isTweezerTop(prev, current models.StockData, cfg PatternConfig) bool {
 	if !isBullish(prev) || !isBearish(current) {
 		return false
 	}
 
 	avgPrice := (prev.High + current.High) / 2
-	tolerance := avgPrice * 0.002 // 0.2% tolerance
+	tolerance := avgPrice * cfg.TweezerTolerance // 0.2% tolerance by default
 
 	return almostEqual(prev.High, current.High, tolerance)
 }
 
 // isTweezerBottom detects a Tweezer Bottom pattern (bullish reversal)
 // Two candles with nearly identical lows, first bearish, second bullish
-func isTweezerBottom(prev, current models.StockData) bool {
+func isTweezerBottom(prev, current models.StockData, cfg PatternConfig) bool {
 	if !isBearish(prev) || !isBullish(current) {
 		return false
 	}
 
 	avgPrice := (prev.Low + current.Low) / 2
-	tolerance := avgPrice * 0.002 // 0.2% tolerance
+	tolerance := avgPrice * cfg.TweezerTolerance // 0.2% tolerance by default
 
 	return almostEqual(prev.Low, current.Low, tolerance)
 }
 
+// isBullishKicker detects a Bullish Kicker pattern (strong bullish reversal)
+// A long bearish candle followed by a long bullish candle that gaps above the prior open
+func isBullishKicker(prev, current models.StockData, cfg PatternConfig) bool {
+	if !isBearish(prev) || !isBullish(current) {
+		return false
+	}
+	if !isLongBody(prev, cfg) || !isLongBody(current, cfg) {
+		return false
+	}
+
+	// Current never trades back into the previous body
+	return current.Open > prev.Open && current.Low >= prev.Open
+}
+
+// isBearishKicker detects a Bearish Kicker pattern (strong bearish reversal)
+// A long bullish candle followed by a long bearish candle that gaps below the prior open
+func isBearishKicker(prev, current models.StockData, cfg PatternConfig) bool {
+	if !isBullish(prev) || !isBearish(current) {
+		return false
+	}
+	if !isLongBody(prev, cfg) || !isLongBody(current, cfg) {
+		return false
+	}
+
+	// Current never trades back into the previous body
+	return current.Open < prev.Open && current.High <= prev.Open
+}
+
+// isBullishCounterattack detects a Bullish Counterattack line (bullish reversal)
+// Long bearish candle, then a long bullish candle opening far lower and closing at the prior close
+func isBullishCounterattack(prev, current models.StockData, cfg PatternConfig) bool {
+	if !isBearish(prev) || !isBullish(current) {
+		return false
+	}
+	if !isLongBody(prev, cfg) || !isLongBody(current, cfg) {
+		return false
+	}
+
+	tolerance := prev.Close * cfg.CounterattackTolerance
+	return current.Open < prev.Low && almostEqual(current.Close, prev.Close, tolerance)
+}
+
+// isBearishCounterattack detects a Bearish Counterattack line (bearish reversal)
+// Long bullish candle, then a long bearish candle opening far higher and closing at the prior close
+func isBearishCounterattack(prev, current models.StockData, cfg PatternConfig) bool {
+	if !isBullish(prev) || !isBearish(current) {
+		return false
+	}
+	if !isLongBody(prev, cfg) || !isLongBody(current, cfg) {
+		return false
+	}
+
+	tolerance := prev.Close * cfg.CounterattackTolerance
+	return current.Open > prev.High && almostEqual(current.Close, prev.Close, tolerance)
+}
+
 // ============================================================================
 // THREE CANDLE PATTERNS
 // ============================================================================
 
 // isMorningStar detects a Morning Star pattern (bullish reversal)
 // Bearish candle, small body candle, bullish candle closing above midpoint of first
-func isMorningStar(first, second, third models.StockData) bool {
+func isMorningStar(first, second, third models.StockData, cfg PatternConfig) bool {
 	if !isBearish(first) || !isBullish(third) {
 		return false
 	}
@@ -375,8 +476,8 @@ func isMorningStar(first, second, third models.StockData) bool {
 	firstBody := bodySize(first)
 	secondBody := bodySize(second)
 
-	// Second candle should have a small body (less than 30% of first)
-	secondSmall := secondBody < firstBody*0.3
+	// Second candle should have a small body (less than 30% of first by default)
+	secondSmall := secondBody < firstBody*cfg.StarMaxBodyRatio
 
 	// Third candle should close above the midpoint of the first
 	return secondSmall && third.Close > bodyMidpoint(first)
@@ -384,7 +485,7 @@ func isMorningStar(first, second, third models.StockData) bool {
 
 // isEveningStar detects an Evening Star pattern (bearish reversal)
 // Bullish candle, small body candle, bearish candle closing below midpoint of first
-func isEveningStar(first, second, third models.StockData) bool {
+func isEveningStar(first, second, third models.StockData, cfg PatternConfig) bool {
 	if !isBullish(first) || !isBearish(third) {
 		return false
 	}
@@ -392,8 +493,8 @@ func isEveningStar(first, second, third models.StockData) bool {
 	firstBody := bodySize(first)
 	secondBody := bodySize(second)
 
-	// Second candle should have a small body (less than 30% of first)
-	secondSmall := secondBody < firstBody*0.3
+	// Second candle should have a small body (less than 30% of first by default)
+	secondSmall := secondBody < firstBody*cfg.StarMaxBodyRatio
 
 	// Third candle should close below the midpoint of the first
 	return secondSmall && third.Close < bodyMidpoint(first)
@@ -401,7 +502,7 @@ func isEveningStar(first, second, third models.StockData) bool {
 
 // isThreeWhiteSoldiers detects Three White Soldiers pattern (strong bullish)
 // Three consecutive bullish candles, each opening within previous body and closing higher
-func isThreeWhiteSoldiers(first, second, third models.StockData) bool {
+func isThreeWhiteSoldiers(first, second, third models.StockData, cfg PatternConfig) bool {
 	// All three must be bullish
 	if !isBullish(first) || !isBullish(second) || !isBullish(third) {
 		return false
@@ -424,9 +525,9 @@ func isThreeWhiteSoldiers(first, second, third models.StockData) bool {
 	progressiveCloses := second.Close > first.Close && third.Close > second.Close
 
 	// Small upper shadows (strong conviction)
-	firstSmallUpper := upperShadow(first) < firstBody*0.3
-	secondSmallUpper := upperShadow(second) < secondBody*0.3
-	thirdSmallUpper := upperShadow(third) < thirdBody*0.3
+	firstSmallUpper := upperShadow(first) < firstBody*cfg.SoldiersMaxShadowToBody
+	secondSmallUpper := upperShadow(second) < secondBody*cfg.SoldiersMaxShadowToBody
+	thirdSmallUpper := upperShadow(third) < thirdBody*cfg.SoldiersMaxShadowToBody
 
 	return secondOpensInFirst && thirdOpensInSecond &&
 		progressiveCloses &&
@@ -435,7 +536,7 @@ func isThreeWhiteSoldiers(first, second, third models.StockData) bool {
 
 // isThreeBlackCrows detects Three Black Crows pattern (strong bearish)
 // Three consecutive bearish candles, each opening within previous body and closing lower
-func isThreeBlackCrows(first, second, third models.StockData) bool {
+func isThreeBlackCrows(first, second, third models.StockData, cfg PatternConfig) bool {
 	// All three must be bearish
 	if !isBearish(first) || !isBearish(second) || !isBearish(third) {
 		return false
@@ -458,9 +559,9 @@ func isThreeBlackCrows(first, second, third models.StockData) bool {
 	progressiveCloses := second.Close < first.Close && third.Close < second.Close
 
 	// Small lower shadows (strong conviction)
-	firstSmallLower := lowerShadow(first) < firstBody*0.3
-	secondSmallLower := lowerShadow(second) < secondBody*0.3
-	thirdSmallLower := lowerShadow(third) < thirdBody*0.3
+	firstSmallLower := lowerShadow(first) < firstBody*cfg.SoldiersMaxShadowToBody
+	secondSmallLower := lowerShadow(second) < secondBody*cfg.SoldiersMaxShadowToBody
+	thirdSmallLower := lowerShadow(third) < thirdBody*cfg.SoldiersMaxShadowToBody
 
 	return secondOpensInFirst && thirdOpensInSecond &&
 		progressiveCloses &&
@@ -535,11 +636,183 @@ func isThreeOutsideDown(first, second, third models.StockData) bool {
 	return engulfing && confirmation
 }
 
+// isBullishAbandonedBaby detects a Bullish Abandoned Baby (strong bullish reversal)
+// Bearish candle, doji gapping below it, bullish candle gapping back above the doji
+func isBullishAbandonedBaby(first, second, third models.StockData, cfg PatternConfig) bool {
+	if !isBearish(first) || !isBullish(third) || !isDoji(second, cfg) {
+		return false
+	}
+
+	// The doji is isolated by gaps on both sides
+	return second.High < first.Low && third.Low > second.High
+}
+
+// isBearishAbandonedBaby detects a Bearish Abandoned Baby (strong bearish reversal)
+// Bullish candle, doji gapping above it, bearish candle gapping back below the doji
+func isBearishAbandonedBaby(first, second, third models.StockData, cfg PatternConfig) bool {
+	if !isBullish(first) || !isBearish(third) || !isDoji(second, cfg) {
+		return false
+	}
+
+	// The doji is isolated by gaps on both sides
+	return second.Low > first.High && third.High < second.Low
+}
+
+// isUpsideTasukiGap detects an Upside Tasuki Gap (bullish continuation)
+// Two bullish candles with a gap between them, then a bearish candle that fails to close the gap
+func isUpsideTasukiGap(first, second, third models.StockData) bool {
+	if !isBullish(first) || !isBullish(second) || !isBearish(third) {
+		return false
+	}
+
+	gapUp := second.Low > first.High
+	opensInSecond := third.Open > second.Open && third.Open < second.Close
+	closesInGap := third.Close < second.Low && third.Close > first.High
+
+	return gapUp && opensInSecond && closesInGap
+}
+
+// isDownsideTasukiGap detects a Downside Tasuki Gap (bearish continuation)
+// Two bearish candles with a gap between them, then a bullish candle that fails to close the gap
+func isDownsideTasukiGap(first, second, third models.StockData) bool {
+	if !isBearish(first) || !isBearish(second) || !isBullish(third) {
+		return false
+	}
+
+	gapDown := second.High < first.Low
+	opensInSecond := third.Open < second.Open && third.Open > second.Close
+	closesInGap := third.Close > second.High && third.Close < first.Low
+
+	return gapDown && opensInSecond && closesInGap
+}
+
+// ============================================================================
+// FOUR AND FIVE CANDLE PATTERNS
+// ============================================================================
+
+// isBullishThreeLineStrike detects a Bullish Three Line Strike (bullish reversal)
+// Three falling bearish candles, then a bullish candle opening lower and closing above the first open
+func isBullishThreeLineStrike(bars []models.StockData) bool {
+	if len(bars) != 4 {
+		return false
+	}
+	first, second, third, strike := bars[0], bars[1], bars[2], bars[3]
+
+	if !isBearish(first) || !isBearish(second) || !isBearish(third) || !isBullish(strike) {
+		return false
+	}
+
+	lowerCloses := second.Close < first.Close && third.Close < second.Close
+	return lowerCloses && strike.Open <= third.Close && strike.Close > first.Open
+}
+
+// isBearishThreeLineStrike detects a Bearish Three Line Strike (bearish reversal)
+// Three rising bullish candles, then a bearish candle opening higher and closing below the first open
+func isBearishThreeLineStrike(bars []models.StockData) bool {
+	if len(bars) != 4 {
+		return false
+	}
+	first, second, third, strike := bars[0], bars[1], bars[2], bars[3]
+
+	if !isBullish(first) || !isBullish(second) || !isBullish(third) || !isBearish(strike) {
+		return false
+	}
+
+	higherCloses := second.Close > first.Close && third.Close > second.Close
+	return higherCloses && strike.Open >= third.Close && strike.Close < first.Open
+}
+
+// isRisingThreeMethods detects Rising Three Methods (bullish continuation)
+// Long bullish candle, three small candles held within its range, then a long bullish candle closing higher
+func isRisingThreeMethods(bars []models.StockData, cfg PatternConfig) bool {
+	if len(bars) != 5 {
+		return false
+	}
+	first, last := bars[0], bars[4]
+
+	if !isBullish(first) || !isBullish(last) || !isLongBody(first, cfg) || !isLongBody(last, cfg) {
+		return false
+	}
+
+	for _, b := range bars[1:4] {
+		if bodySize(b) > bodySize(first)*cfg.MethodsMaxBodyRatio {
+			return false
+		}
+		if b.High > first.High || b.Low < first.Low {
+			return false
+		}
+	}
+
+	return last.Close > first.Close
+}
+
+// isFallingThreeMethods detects Falling Three Methods (bearish continuation)
+// Long bearish candle, three small candles held within its range, then a long bearish candle closing lower
+func isFallingThreeMethods(bars []models.StockData, cfg PatternConfig) bool {
+	if len(bars) != 5 {
+		return false
+	}
+	first, last := bars[0], bars[4]
+
+	if !isBearish(first) || !isBearish(last) || !isLongBody(first, cfg) || !isLongBody(last, cfg) {
+		return false
+	}
+
+	for _, b := range bars[1:4] {
+		if bodySize(b) > bodySize(first)*cfg.MethodsMaxBodyRatio {
+			return false
+		}
+		if b.High > first.High || b.Low < first.Low {
+			return false
+		}
+	}
+
+	return last.Close < first.Close
+}
+
+// isMatHold detects a Mat Hold pattern (bullish continuation)
+// Long bullish candle, a small candle gapping up, two more small candles holding above the
+// first candle's midpoint, then a bullish candle closing above the whole pattern
+func isMatHold(bars []models.StockData, cfg PatternConfig) bool {
+	if len(bars) != 5 {
+		return false
+	}
+	first, second, last := bars[0], bars[1], bars[4]
+
+	if !isBullish(first) || !isBullish(last) || !isLongBody(first, cfg) {
+		return false
+	}
+
+	// Second body gaps above the first close
+	if math.Min(second.Open, second.Close) <= first.Close {
+		return false
+	}
+
+	highest := first.High
+	for _, b := range bars[1:4] {
+		if bodySize(b) > bodySize(first)*cfg.MethodsMaxBodyRatio {
+			return false
+		}
+		if b.Low < bodyMidpoint(first) {
+			return false
+		}
+		highest = math.Max(highest, b.High)
+	}
+
+	return last.Close > highest
+}
+
 // ============================================================================
 // MAIN PATTERN DETECTION FUNCTION
 // ============================================================================
 
+// DetectCandlestickPatterns detects patterns ending on the last bar using the default thresholds
 func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPattern {
+	return DetectCandlestickPatternsWithConfig(data, DefaultPatternConfig())
+}
+
+// DetectCandlestickPatternsWithConfig detects patterns ending on the last bar using the given thresholds
+func DetectCandlestickPatternsWithConfig(data []models.StockData, cfg PatternConfig) []models.CandlestickPattern {
 	if len(data) < 3 {
 		return []models.CandlestickPattern{}
 	}
@@ -554,19 +827,19 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	// ========================================
 
 	// Doji patterns (check specific dojis first)
-	if isDragonflyDoji(current) {
+	if isDragonflyDoji(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Dragonfly Doji",
 			Type:       "bullish",
 			Confidence: 0.75,
 		})
-	} else if isGravestoneDoji(current) {
+	} else if isGravestoneDoji(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Gravestone Doji",
 			Type:       "bearish",
 			Confidence: 0.75,
 		})
-	} else if isDoji(current) {
+	} else if isDoji(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Doji",
 			Type:       "neutral",
@@ -575,7 +848,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Spinning Top
-	if isSpinningTop(current) {
+	if isSpinningTop(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Spinning Top",
 			Type:       "neutral",
@@ -584,7 +857,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Marubozu patterns
-	if isBullishMarubozu(current) {
+	if isBullishMarubozu(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Bullish Marubozu",
 			Type:       "bullish",
@@ -592,7 +865,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		})
 	}
 
-	if isBearishMarubozu(current) {
+	if isBearishMarubozu(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Bearish Marubozu",
 			Type:       "bearish",
@@ -601,13 +874,13 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Hammer-like patterns (context-dependent)
-	if isHangingMan(current, data) {
+	if isHangingMan(current, data, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Hanging Man",
 			Type:       "bearish",
 			Confidence: 0.7,
 		})
-	} else if isHammer(current) {
+	} else if isHammer(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Hammer",
 			Type:       "bullish",
@@ -616,13 +889,13 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Inverted Hammer (needs downtrend context)
-	if isInvertedHammer(current, data) {
+	if isInvertedHammer(current, data, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Inverted Hammer",
 			Type:       "bullish",
 			Confidence: 0.7,
 		})
-	} else if isShootingStar(current) {
+	} else if isShootingStar(current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Shooting Star",
 			Type:       "bearish",
@@ -630,6 +903,23 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		})
 	}
 
+	// Belt Hold (needs trend context)
+	if isBullishBeltHold(prev, current, data, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bullish Belt Hold",
+			Type:       "bullish",
+			Confidence: 0.7,
+		})
+	}
+
+	if isBearishBeltHold(prev, current, data, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bearish Belt Hold",
+			Type:       "bearish",
+			Confidence: 0.7,
+		})
+	}
+
 	// ========================================
 	// Two Candle Patterns
 	// ========================================
@@ -669,7 +959,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Harami patterns
-	if isBullishHarami(prev, current) {
+	if isBullishHarami(prev, current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Bullish Harami",
 			Type:       "bullish",
@@ -677,7 +967,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		})
 	}
 
-	if isBearishHarami(prev, current) {
+	if isBearishHarami(prev, current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Bearish Harami",
 			Type:       "bearish",
@@ -686,7 +976,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 	}
 
 	// Tweezer patterns
-	if isTweezerTop(prev, current) {
+	if isTweezerTop(prev, current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Tweezer Top",
 			Type:       "bearish",
@@ -694,7 +984,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		})
 	}
 
-	if isTweezerBottom(prev, current) {
+	if isTweezerBottom(prev, current, cfg) {
 		patterns = append(patterns, models.CandlestickPattern{
 			Name:       "Tweezer Bottom",
 			Type:       "bullish",
@@ -702,6 +992,40 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		})
 	}
 
+	// Kicker patterns
+	if isBullishKicker(prev, current, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bullish Kicker",
+			Type:       "bullish",
+			Confidence: 0.9,
+		})
+	}
+
+	if isBearishKicker(prev, current, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bearish Kicker",
+			Type:       "bearish",
+			Confidence: 0.9,
+		})
+	}
+
+	// Counterattack lines
+	if isBullishCounterattack(prev, current, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bullish Counterattack",
+			Type:       "bullish",
+			Confidence: 0.65,
+		})
+	}
+
+	if isBearishCounterattack(prev, current, cfg) {
+		patterns = append(patterns, models.CandlestickPattern{
+			Name:       "Bearish Counterattack",
+			Type:       "bearish",
+			Confidence: 0.65,
+		})
+	}
+
 	// ========================================
 	// Three Candle Patterns
 	// ========================================
@@ -710,7 +1034,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		prevPrev := data[len(data)-3]
 
 		// Morning Star and Evening Star
-		if isMorningStar(prevPrev, prev, current) {
+		if isMorningStar(prevPrev, prev, current, cfg) {
 			patterns = append(patterns, models.CandlestickPattern{
 				Name:       "Morning Star",
 				Type:       "bullish",
@@ -718,7 +1042,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 			})
 		}
 
-		if isEveningStar(prevPrev, prev, current) {
+		if isEveningStar(prevPrev, prev, current, cfg) {
 			patterns = append(patterns, models.CandlestickPattern{
 				Name:       "Evening Star",
 				Type:       "bearish",
@@ -727,7 +1051,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 		}
 
 		// Three White Soldiers and Three Black Crows
-		if isThreeWhiteSoldiers(prevPrev, prev, current) {
+		if isThreeWhiteSoldiers(prevPrev, prev, current, cfg) {
 			patterns = append(patterns, models.CandlestickPattern{
 				Name:       "Three White Soldiers",
 				Type:       "bullish",
@@ -735,7 +1059,7 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 			})
 		}
 
-		if isThreeBlackCrows(prevPrev, prev, current) {
+		if isThreeBlackCrows(prevPrev, prev, current, cfg) {
 			patterns = append(patterns, models.CandlestickPattern{
 				Name:       "Three Black Crows",
 				Type:       "bearish",
@@ -776,6 +1100,95 @@ func DetectCandlestickPatterns(data []models.StockData) []models.CandlestickPatt
 				Confidence: 0.85,
 			})
 		}
+
+		// Abandoned Baby
+		if isBullishAbandonedBaby(prevPrev, prev, current, cfg) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Bullish Abandoned Baby",
+				Type:       "bullish",
+				Confidence: 0.9,
+			})
+		}
+
+		if isBearishAbandonedBaby(prevPrev, prev, current, cfg) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Bearish Abandoned Baby",
+				Type:       "bearish",
+				Confidence: 0.9,
+			})
+		}
+
+		// Tasuki Gaps
+		if isUpsideTasukiGap(prevPrev, prev, current) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Upside Tasuki Gap",
+				Type:       "bullish",
+				Confidence: 0.7,
+			})
+		}
+
+		if isDownsideTasukiGap(prevPrev, prev, current) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Downside Tasuki Gap",
+				Type:       "bearish",
+				Confidence: 0.7,
+			})
+		}
+	}
+
+	// ========================================
+	// Four and Five Candle Patterns
+	// ========================================
+
+	if len(data) >= 4 {
+		lastFour := data[len(data)-4:]
+
+		// Three Line Strike
+		if isBullishThreeLineStrike(lastFour) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Bullish Three Line Strike",
+				Type:       "bullish",
+				Confidence: 0.85,
+			})
+		}
+
+		if isBearishThreeLineStrike(lastFour) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Bearish Three Line Strike",
+				Type:       "bearish",
+				Confidence: 0.85,
+			})
+		}
+	}
+
+	if len(data) >= 5 {
+		lastFive := data[len(data)-5:]
+
+		// Rising/Falling Three Methods
+		if isRisingThreeMethods(lastFive, cfg) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Rising Three Methods",
+				Type:       "bullish",
+				Confidence: 0.8,
+			})
+		}
+
+		if isFallingThreeMethods(lastFive, cfg) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Falling Three Methods",
+				Type:       "bearish",
+				Confidence: 0.8,
+			})
+		}
+
+		// Mat Hold
+		if isMatHold(lastFive, cfg) {
+			patterns = append(patterns, models.CandlestickPattern{
+				Name:       "Mat Hold",
+				Type:       "bullish",
+				Confidence: 0.85,
+			})
+		}
 	}
 
 	return patterns
@@ -902,16 +1315,22 @@ func aggregateCandles(candles []models.StockData) models.StockData {
 
 // DetectAllTimeframePatterns detects candlestick patterns for daily, weekly, and monthly timeframes
 func DetectAllTimeframePatterns(data []models.StockData) models.TimeframePatterns {
+	return DetectAllTimeframePatternsWithOptions(data, DefaultPatternConfig(), DefaultPatternContextOptions())
+}
+
+// DetectAllTimeframePatternsWithOptions detects candlestick patterns for each timeframe with the
+// given thresholds and adjusts their confidence with the requested volume and trend context
+func DetectAllTimeframePatternsWithOptions(data []models.StockData, cfg PatternConfig, opts PatternContextOptions) models.TimeframePatterns {
 	// Detect patterns on daily candles
-	dailyPatterns := DetectCandlestickPatterns(data)
+	dailyPatterns := applyPatternContext(DetectCandlestickPatternsWithConfig(data, cfg), data, opts)
 
 	// Aggregate to weekly and detect patterns
 	weeklyData := aggregateToWeeklyCandles(data)
-	weeklyPatterns := DetectCandlestickPatterns(weeklyData)
+	weeklyPatterns := applyPatternContext(DetectCandlestickPatternsWithConfig(weeklyData, cfg), weeklyData, opts)
 
 	// Aggregate to monthly and detect patterns
 	monthlyData := aggregateToMonthlyCandles(data)
-	monthlyPatterns := DetectCandlestickPatterns(monthlyData)
+	monthlyPatterns := applyPatternContext(DetectCandlestickPatternsWithConfig(monthlyData, cfg), monthlyData, opts)
 
 	return models.TimeframePatterns{
 		Daily:   dailyPatterns,
@@ -0,0 +1,116 @@
+package analysis
+
+import (
+	"stocking-chain/internal/models"
+	"time"
+)
+
+// ============================================================================
+// POINT-IN-TIME (AS-OF) ANALYSIS
+// ============================================================================
+
+// AnalyzeAsOf runs the full analysis as it would have looked at the close of
+// the asOf trading day. Every series is cut at that bar, so detectors that need
+// bars to the right (pivots, Springs, Upthrusts, climaxes) only report what was
+// confirmed by then. Each Wyckoff event is stamped with the bar on which a
+// walk forward through the history first reported it
+func (a *Analyzer) AnalyzeAsOf(symbol string, data []models.StockData, market *MarketContext, asOf time.Time) (*models.AnalysisReport, error) {
+	data = barsThrough(data, asOf)
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	report, err := a.AnalyzeWithMarket(symbol, data, market.Through(asOf))
+	if err != nil || report == nil {
+		return report, err
+	}
+
+	confirmWyckoffEvents(data, report.Wyckoff.Events)
+
+	asOfDate := data[len(data)-1].Date
+	report.Date = asOfDate
+	report.AsOf = &asOfDate
+	return report, nil
+}
+
+// confirmWyckoffEvents sets ConfirmedDate on each event to the first bar whose
+// close would have reported it: it reruns detection on every prefix of data
+// and looks for the same event overlapping the event's span. Events from the
+// full series always match on its last bar.
+//
+// The scan runs from the first event's start until every event is confirmed,
+// one detector pass per bar, so it is quadratic in the history length: about
+// 0.7s for three years of daily bars. It is not cut off after a fixed number
+// of bars because confirmations often lag by hundreds of bars, when a later
+// trading range is what makes an earlier climax or test recognizable, and a
+// cutoff would stamp those events with the wrong date
+func confirmWyckoffEvents(data []models.StockData, events []models.WyckoffEvent) {
+	if len(events) == 0 {
+		return
+	}
+
+	// Detection needs 30 bars and the bar after each event
+	first := len(data) - 1
+	for _, event := range events {
+		if idx := barIndexForDate(data, event.StartDate); idx >= 0 {
+			first = min(first, idx+1)
+		}
+	}
+
+	remaining := len(events)
+	for t := max(first, 29); t < len(data) && remaining > 0; t++ {
+		prefix := data[:t+1]
+		detected := detectWyckoffEvents(prefix, detectTradingRange(prefix))
+
+		for k := range events {
+			event := &events[k]
+			if event.ConfirmedDate != nil || !wyckoffEventDetected(*event, detected) {
+				continue
+			}
+			confirmed := data[t].Date
+			event.ConfirmedDate = &confirmed
+			event.ConfirmationBars = t - barIndexForDate(data, event.StartDate)
+			remaining--
+		}
+	}
+}
+
+// wyckoffEventDetected reports whether detected holds an event of the same name
+// and type whose span overlaps event's span
+func wyckoffEventDetected(event models.WyckoffEvent, detected []models.WyckoffEvent) bool {
+	for _, d := range detected {
+		if d.Name != event.Name || d.Type != event.Type {
+			continue
+		}
+		if !d.StartDate.After(event.EndDate) && !d.EndDate.Before(event.StartDate) {
+			return true
+		}
+	}
+	return false
+}
+
+// Through returns a copy of the market context cut at the asOf trading day.
+// A nil context stays nil
+func (m *MarketContext) Through(asOf time.Time) *MarketContext {
+	if m == nil {
+		return nil
+	}
+	return &MarketContext{
+		IndexSymbol:  m.IndexSymbol,
+		Index:        barsThrough(m.Index, asOf),
+		SectorSymbol: m.SectorSymbol,
+		Sector:       barsThrough(m.Sector, asOf),
+		History:      barsThrough(m.History, asOf),
+	}
+}
+
+// barsThrough returns the bars dated on or before the asOf calendar day
+func barsThrough(data []models.StockData, asOf time.Time) []models.StockData {
+	day := asOf.Format("2006-01-02")
+	for i, bar := range data {
+		if bar.Date.Format("2006-01-02") > day {
+			return data[:i]
+		}
+	}
+	return data
+}
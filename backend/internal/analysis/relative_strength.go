@@ -0,0 +1,217 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// RELATIVE STRENGTH VERSUS THE MARKET
+// ============================================================================
+
+const (
+	rsTrendLookback    = 20   // Bars used for the RS line slope
+	rsTrendFlatPercent = 0.05 // Slope (percent of RS per bar) below which the trend is flat
+	rsDeclineLookback  = 60   // Bars searched for the latest benchmark decline
+	rsMinDeclinePct    = 3.0  // Smallest benchmark peak-to-trough drop treated as a decline
+)
+
+// MarketContext holds benchmark series to compare a stock against, and the
+// stock's own longer daily history for weekly and monthly analysis
+type MarketContext struct {
+	IndexSymbol  string
+	Index        []models.StockData
+	SectorSymbol string
+	Sector       []models.StockData
+	History      []models.StockData
+}
+
+// CalculateRelativeStrength compares a stock against a benchmark on the dates
+// both traded. The RS line is the ratio of their closes scaled to 100 on the
+// first shared date. Returns nil when fewer than rsTrendLookback dates overlap
+func CalculateRelativeStrength(data, benchmark []models.StockData, benchmarkSymbol string) *models.RelativeStrength {
+	stock, bench := alignByDate(data, benchmark)
+	if len(stock) < rsTrendLookback || stock[0].Close == 0 || bench[0].Close == 0 {
+		return nil
+	}
+
+	base := stock[0].Close / bench[0].Close
+	line := make([]models.RSPoint, len(stock))
+	lineBars := make([]models.StockData, len(stock))
+	for i := range stock {
+		value := 0.0
+		if bench[i].Close > 0 {
+			value = stock[i].Close / bench[i].Close / base * 100
+		}
+		line[i] = models.RSPoint{Date: stock[i].Date, Value: value}
+		lineBars[i] = models.StockData{Date: stock[i].Date, Close: value}
+	}
+
+	current := line[len(line)-1].Value
+
+	// Trend of the RS line over the recent bars
+	slope, _ := linearRegression(lineBars[len(lineBars)-rsTrendLookback:])
+	slopePercent := 0.0
+	if current > 0 {
+		slopePercent = slope / current * 100
+	}
+	trend := "flat"
+	if slopePercent > rsTrendFlatPercent {
+		trend = "rising"
+	} else if slopePercent < -rsTrendFlatPercent {
+		trend = "falling"
+	}
+
+	// Rank the current RS value against its own history (0-100)
+	below := 0
+	for _, p := range line {
+		if p.Value < current {
+			below++
+		}
+	}
+	rank := int(math.Round(float64(below) / float64(len(line)-1) * 100))
+
+	rs := &models.RelativeStrength{
+		Benchmark:       benchmarkSymbol,
+		Line:            line,
+		Current:         current,
+		Trend:           trend,
+		Rank:            min(rank, 100),
+		Outperformance:  map[string]float64{},
+		DownsideCapture: downsideCapture(stock, bench),
+	}
+
+	for _, period := range []struct {
+		label string
+		bars  int
+	}{{"1m", 20}, {"3m", 60}, {"6m", 120}} {
+		if len(stock) > period.bars {
+			from := len(stock) - 1 - period.bars
+			rs.Outperformance[period.label] = percentChange(stock[from].Close, stock[len(stock)-1].Close) -
+				percentChange(bench[from].Close, bench[len(bench)-1].Close)
+		}
+	}
+
+	// How the stock fared during the benchmark's latest decline
+	if peak, trough := latestDecline(bench); peak >= 0 {
+		peakDate, troughDate := bench[peak].Date, bench[trough].Date
+		rs.DeclineStart = &peakDate
+		rs.DeclineEnd = &troughDate
+		rs.BenchmarkDecline = percentChange(bench[peak].Close, bench[trough].Close)
+		rs.StockDecline = percentChange(stock[peak].Close, stock[trough].Close)
+		rs.HeldUp = rs.StockDecline > rs.BenchmarkDecline
+	} else {
+		rs.HeldUp = rs.DownsideCapture > 0 && rs.DownsideCapture < 1
+	}
+
+	return rs
+}
+
+// alignByDate returns the bars of a and b that share a trading day, in the order of a
+func alignByDate(a, b []models.StockData) ([]models.StockData, []models.StockData) {
+	byDay := map[string]models.StockData{}
+	for _, d := range b {
+		byDay[d.Date.Format("2006-01-02")] = d
+	}
+
+	alignedA := []models.StockData{}
+	alignedB := []models.StockData{}
+	for _, d := range a {
+		if match, ok := byDay[d.Date.Format("2006-01-02")]; ok {
+			alignedA = append(alignedA, d)
+			alignedB = append(alignedB, match)
+		}
+	}
+	return alignedA, alignedB
+}
+
+// downsideCapture is the stock's average return on benchmark down days divided
+// by the benchmark's average return on those days. Below 1 the stock fell less
+func downsideCapture(stock, bench []models.StockData) float64 {
+	stockSum, benchSum := 0.0, 0.0
+	for i := 1; i < len(bench); i++ {
+		benchReturn := percentChange(bench[i-1].Close, bench[i].Close)
+		if benchReturn >= 0 {
+			continue
+		}
+		stockSum += percentChange(stock[i-1].Close, stock[i].Close)
+		benchSum += benchReturn
+	}
+	if benchSum == 0 {
+		return 0
+	}
+	return stockSum / benchSum
+}
+
+// latestDecline finds the most recent benchmark peak-to-trough drop of at least
+// rsMinDeclinePct within the lookback, returning -1, -1 when there is none
+func latestDecline(bench []models.StockData) (int, int) {
+	start := max(0, len(bench)-rsDeclineLookback)
+	bestPeak, bestTrough := -1, -1
+
+	peak := start
+	for i := start + 1; i < len(bench); i++ {
+		if bench[i].Close > bench[peak].Close {
+			peak = i
+			continue
+		}
+		if percentChange(bench[peak].Close, bench[i].Close) <= -rsMinDeclinePct {
+			// Keep the deepest trough of the latest qualifying decline
+			if bestPeak != peak || bench[i].Close < bench[bestTrough].Close {
+				bestPeak, bestTrough = peak, i
+			}
+		}
+	}
+
+	return bestPeak, bestTrough
+}
+
+// percentChange returns the percent move from one price to another
+func percentChange(from, to float64) float64 {
+	if from == 0 {
+		return 0
+	}
+	return (to - from) / from * 100
+}
+
+// relativeStrengthScore converts RS readings into a Wyckoff score contribution:
+// a rising RS line, a high RS rank and holding up in declines are bullish
+func relativeStrengthScore(rs *models.RelativeStrength, weight float64) float64 {
+	if rs == nil {
+		return 0
+	}
+
+	score := 0.0
+	switch rs.Trend {
+	case "rising":
+		score += 1.0
+	case "falling":
+		score -= 1.0
+	}
+
+	if rs.Rank >= 80 {
+		score += 0.5
+	} else if rs.Rank <= 20 {
+		score -= 0.5
+	}
+
+	if rs.HeldUp {
+		score += 0.5
+	} else if rs.DeclineStart != nil {
+		score -= 0.5
+	}
+
+	return score * weight
+}
+
+// relativeStrengthCondition describes the readings relativeStrengthScore uses
+func relativeStrengthCondition(rs *models.RelativeStrength) string {
+	condition := fmt.Sprintf("RS line %s, rank %d versus %s", rs.Trend, rs.Rank, rs.Benchmark)
+	if rs.HeldUp {
+		condition += ", held up during the decline"
+	} else if rs.DeclineStart != nil {
+		condition += ", fell with the benchmark"
+	}
+	return condition
+}
@@ -0,0 +1,448 @@
+package analysis
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"sort"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// SCORING MODELS
+// ============================================================================
+
+// ScoringComponent is one weighted signal of a scoring model. Every signal
+// evaluates to a value around [-1, 1] (bullish positive) that is multiplied by
+// Weight; Params override the signal's default thresholds
+type ScoringComponent struct {
+	Signal string             `json:"signal"`
+	Weight float64            `json:"weight"`
+	Params map[string]float64 `json:"params,omitempty"`
+}
+
+// ScoringModel turns the analysis into the overall recommendation: the sum of
+// its weighted components divided by Normalizer, clamped to [-1, 1]. When both
+// thresholds are zero the Analyzer's buy and sell thresholds apply
+type ScoringModel struct {
+	Name          string             `json:"name"`
+	Description   string             `json:"description,omitempty"`
+	Components    []ScoringComponent `json:"components"`
+	Normalizer    float64            `json:"normalizer"`
+	BuyThreshold  float64            `json:"buy_threshold,omitempty"`
+	SellThreshold float64            `json:"sell_threshold,omitempty"`
+}
+
+// ScoringModelSet is the file format of LoadScoringModels. Default names the
+// model that makes the recommendation; the others are available for comparison
+type ScoringModelSet struct {
+	Default string         `json:"default,omitempty"`
+	Models  []ScoringModel `json:"models"`
+}
+
+// scoringInputs is the analysis a scoring model reads
+type scoringInputs struct {
+	price      float64
+	indicators models.TechnicalIndicators
+	patterns   models.TimeframePatterns
+	sr         models.SupportResistance
+	trend      models.TrendAnalysis
+	wyckoff    models.WyckoffAnalysis
+}
+
+// scoringSignal evaluates one signal with its parameters merged over defaults,
+// returning its value and the condition that produced it
+type scoringSignal struct {
+	defaults map[string]float64
+	evaluate func(in scoringInputs, p map[string]float64) (float64, string)
+}
+
+var bullishWyckoffEvents = map[string]bool{
+	"Spring": true, "Sign of Strength": true, "Selling Climax": true, "Test of Spring": true,
+	"Last Point of Support": true, "Jump Across the Creek": true, "Back-Up": true,
+}
+
+var bearishWyckoffEvents = map[string]bool{
+	"Upthrust": true, "Sign of Weakness": true, "Buying Climax": true,
+	"Upthrust After Distribution": true, "Last Point of Supply": true,
+}
+
+// scoringSignals are the signals a component can name
+var scoringSignals = map[string]scoringSignal{
+	// RSI: full value beyond oversold/overbought, "mild" value in the zones next to them
+	"rsi": {
+		defaults: map[string]float64{"oversold": 30, "mild_oversold": 40, "mild_overbought": 60, "overbought": 70, "mild": 0.5},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			rsi := in.indicators.RSI
+			switch {
+			case rsi < p["oversold"]:
+				return 1, fmt.Sprintf("RSI %.1f below %.0f (oversold)", rsi, p["oversold"])
+			case rsi < p["mild_oversold"]:
+				return p["mild"], fmt.Sprintf("RSI %.1f below %.0f", rsi, p["mild_oversold"])
+			case rsi > p["overbought"]:
+				return -1, fmt.Sprintf("RSI %.1f above %.0f (overbought)", rsi, p["overbought"])
+			case rsi > p["mild_overbought"]:
+				return -p["mild"], fmt.Sprintf("RSI %.1f above %.0f", rsi, p["mild_overbought"])
+			}
+			return 0, fmt.Sprintf("RSI %.1f neutral", rsi)
+		},
+	},
+	// MACD line against its signal line
+	"macd": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			if in.indicators.MACD > in.indicators.MACDSignal {
+				return 1, "MACD above its signal line"
+			}
+			return -1, "MACD at or below its signal line"
+		},
+	},
+	// Price, fast SMA and slow SMA stacked in order
+	"sma_alignment": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			ind := in.indicators
+			if in.price > ind.SMA20 && ind.SMA20 > ind.SMA50 {
+				return 1, "Price above the fast SMA, fast SMA above the slow SMA"
+			}
+			if in.price < ind.SMA20 && ind.SMA20 < ind.SMA50 {
+				return -1, "Price below the fast SMA, fast SMA below the slow SMA"
+			}
+			return 0, "Moving averages not aligned"
+		},
+	},
+	// Close outside the Bollinger Bands, read as mean reversion
+	"bollinger": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			if in.price < in.indicators.BollingerLower {
+				return 1, "Price below the lower Bollinger Band"
+			}
+			if in.price > in.indicators.BollingerUpper {
+				return -1, "Price above the upper Bollinger Band"
+			}
+			return 0, "Price inside the Bollinger Bands"
+		},
+	},
+	// Daily candlestick patterns, each counted at its confidence
+	"candlestick_patterns": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			value, bullish, bearish := 0.0, 0, 0
+			for _, pattern := range in.patterns.Daily {
+				if pattern.Type == "bullish" {
+					value += pattern.Confidence
+					bullish++
+				} else if pattern.Type == "bearish" {
+					value -= pattern.Confidence
+					bearish++
+				}
+			}
+			return value, fmt.Sprintf("%d bullish and %d bearish daily patterns", bullish, bearish)
+		},
+	},
+	// Trend direction scaled by its strength
+	"trend": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			condition := fmt.Sprintf("%s, strength %.2f", in.trend.Trend, in.trend.Strength)
+			if in.trend.Trend == "uptrend" {
+				return in.trend.Strength, condition
+			}
+			if in.trend.Trend == "downtrend" {
+				return -in.trend.Strength, condition
+			}
+			return 0, condition
+		},
+	},
+	// Price within distance (a fraction of price) above the nearest support
+	"support_proximity": {
+		defaults: map[string]float64{"distance": 0.02},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			if len(in.sr.SupportLevels) == 0 {
+				return 0, "No support level"
+			}
+			distance := (in.price - in.sr.SupportLevels[0]) / in.price
+			condition := fmt.Sprintf("%.1f%% above support at %.0f", distance*100, in.sr.SupportLevels[0])
+			if distance < p["distance"] {
+				return 1, condition
+			}
+			return 0, condition
+		},
+	},
+	// Price within distance (a fraction of price) below the nearest resistance
+	"resistance_proximity": {
+		defaults: map[string]float64{"distance": 0.02},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			if len(in.sr.ResistanceLevels) == 0 {
+				return 0, "No resistance level"
+			}
+			distance := (in.sr.ResistanceLevels[0] - in.price) / in.price
+			condition := fmt.Sprintf("%.1f%% below resistance at %.0f", distance*100, in.sr.ResistanceLevels[0])
+			if distance < p["distance"] {
+				return -1, condition
+			}
+			return 0, condition
+		},
+	},
+	// Wyckoff phase at its confidence; the trending phases count for trend_factor
+	"wyckoff_phase": {
+		defaults: map[string]float64{"trend_factor": 0.75},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			confidence := in.wyckoff.PhaseConfidence
+			condition := fmt.Sprintf("%s phase, confidence %.2f", in.wyckoff.Phase, confidence)
+			switch in.wyckoff.Phase {
+			case "accumulation":
+				return confidence, condition
+			case "markup":
+				return p["trend_factor"] * confidence, condition
+			case "distribution":
+				return -confidence, condition
+			case "markdown":
+				return -p["trend_factor"] * confidence, condition
+			}
+			return 0, condition
+		},
+	},
+	// Bullish and bearish Wyckoff events, each counted at its confidence
+	"wyckoff_events": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			value, bullish, bearish := 0.0, 0, 0
+			for _, event := range in.wyckoff.Events {
+				if bullishWyckoffEvents[event.Name] {
+					value += event.Confidence
+					bullish++
+				} else if bearishWyckoffEvents[event.Name] {
+					value -= event.Confidence
+					bearish++
+				}
+			}
+			return value, fmt.Sprintf("%d bullish and %d bearish Wyckoff events", bullish, bearish)
+		},
+	},
+	// Effort/result divergence, which argues against the current trend
+	"effort_result": {
+		defaults: map[string]float64{},
+		evaluate: func(in scoringInputs, p map[string]float64) (float64, string) {
+			if in.wyckoff.EffortResult != "diverging" {
+				return 0, fmt.Sprintf("Effort and result %s", in.wyckoff.EffortResult)
+			}
+			if in.trend.Trend == "uptrend" {
+				return -1, "Effort and result diverging in an uptrend"
+			}
+			if in.trend.Trend == "downtrend" {
+				return 1, "Effort and result diverging in a downtrend"
+			}
+			return 0, "Effort and result diverging without a trend"
+		},
+	},
+}
+
+// ScoringSignals lists the signal names a scoring component can use
+func ScoringSignals() []string {
+	names := make([]string, 0, len(scoringSignals))
+	for name := range scoringSignals {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DefaultScoringModel returns the weights the Analyzer was originally built with
+func DefaultScoringModel() ScoringModel {
+	return ScoringModel{
+		Name:        "default",
+		Description: "Balanced blend of indicators, trend, support/resistance and Wyckoff",
+		Components: []ScoringComponent{
+			{Signal: "rsi", Weight: 2},
+			{Signal: "macd", Weight: 1.5},
+			{Signal: "sma_alignment", Weight: 1.5},
+			{Signal: "bollinger", Weight: 1},
+			{Signal: "candlestick_patterns", Weight: 1},
+			{Signal: "trend", Weight: 2},
+			{Signal: "support_proximity", Weight: 1},
+			{Signal: "resistance_proximity", Weight: 1},
+			{Signal: "wyckoff_phase", Weight: 1},
+			{Signal: "wyckoff_events", Weight: 0.75},
+			{Signal: "effort_result", Weight: 0.25},
+		},
+		Normalizer: 10,
+	}
+}
+
+// BuiltinScoringModels returns the default model and alternatives that lean on
+// momentum, mean reversion and Wyckoff structure
+func BuiltinScoringModels() []ScoringModel {
+	return []ScoringModel{
+		DefaultScoringModel(),
+		{
+			Name:        "momentum",
+			Description: "Follows MACD, moving-average alignment and trend strength",
+			Components: []ScoringComponent{
+				{Signal: "macd", Weight: 2},
+				{Signal: "sma_alignment", Weight: 2},
+				{Signal: "trend", Weight: 3},
+				{Signal: "rsi", Weight: 0.5},
+				{Signal: "wyckoff_phase", Weight: 1},
+			},
+			Normalizer: 8,
+		},
+		{
+			Name:        "mean_reversion",
+			Description: "Buys oversold prices near support and sells overbought prices near resistance",
+			Components: []ScoringComponent{
+				{Signal: "rsi", Weight: 3},
+				{Signal: "bollinger", Weight: 2},
+				{Signal: "support_proximity", Weight: 1.5},
+				{Signal: "resistance_proximity", Weight: 1.5},
+				{Signal: "candlestick_patterns", Weight: 1},
+			},
+			Normalizer: 8,
+		},
+		{
+			Name:        "wyckoff",
+			Description: "Weighs the Wyckoff phase and events over the indicators",
+			Components: []ScoringComponent{
+				{Signal: "wyckoff_phase", Weight: 3},
+				{Signal: "wyckoff_events", Weight: 1.5},
+				{Signal: "effort_result", Weight: 0.5},
+				{Signal: "trend", Weight: 1},
+				{Signal: "rsi", Weight: 1},
+			},
+			Normalizer: 7,
+		},
+	}
+}
+
+// LoadScoringModels reads a ScoringModelSet from a JSON file and validates
+// every model in it
+func LoadScoringModels(path string) (ScoringModelSet, error) {
+	var set ScoringModelSet
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return set, err
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return set, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	names := map[string]bool{}
+	for _, model := range set.Models {
+		if err := model.Validate(); err != nil {
+			return set, err
+		}
+		if names[model.Name] {
+			return set, fmt.Errorf("duplicate scoring model %q", model.Name)
+		}
+		names[model.Name] = true
+	}
+	if set.Default != "" && !names[set.Default] {
+		return set, fmt.Errorf("default scoring model %q is not defined in %s", set.Default, path)
+	}
+	return set, nil
+}
+
+// Validate checks that the model is named, its signals and parameters exist and
+// its thresholds are ordered
+func (m ScoringModel) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("scoring model name is required")
+	}
+	if len(m.Components) == 0 {
+		return fmt.Errorf("scoring model %q has no components", m.Name)
+	}
+	if m.Normalizer <= 0 {
+		return fmt.Errorf("scoring model %q: normalizer must be positive", m.Name)
+	}
+	if (m.BuyThreshold != 0 || m.SellThreshold != 0) && m.BuyThreshold <= m.SellThreshold {
+		return fmt.Errorf("scoring model %q: buy_threshold (%.2f) must be above sell_threshold (%.2f)", m.Name, m.BuyThreshold, m.SellThreshold)
+	}
+	for _, component := range m.Components {
+		signal, ok := scoringSignals[component.Signal]
+		if !ok {
+			return fmt.Errorf("scoring model %q: unknown signal %q", m.Name, component.Signal)
+		}
+		for param := range component.Params {
+			if _, ok := signal.defaults[param]; !ok {
+				return fmt.Errorf("scoring model %q: signal %q has no parameter %q", m.Name, component.Signal, param)
+			}
+		}
+	}
+	return nil
+}
+
+// thresholds returns the model's buy and sell thresholds, falling back to the
+// given ones when the model sets neither
+func (m ScoringModel) thresholds(buy, sell float64) (float64, float64) {
+	if m.BuyThreshold == 0 && m.SellThreshold == 0 {
+		return buy, sell
+	}
+	return m.BuyThreshold, m.SellThreshold
+}
+
+// score evaluates the model and maps the normalized score to a recommendation
+func (m ScoringModel) score(in scoringInputs, buyThreshold, sellThreshold float64) models.ModelScore {
+	contributions := make([]models.ScoreContribution, 0, len(m.Components))
+	for _, component := range m.Components {
+		signal := scoringSignals[component.Signal]
+		params := signal.defaults
+		if len(component.Params) > 0 {
+			params = map[string]float64{}
+			for k, v := range signal.defaults {
+				params[k] = v
+			}
+			for k, v := range component.Params {
+				params[k] = v
+			}
+		}
+		value, condition := signal.evaluate(in, params)
+		contributions = append(contributions, scoreContribution(component.Signal, value, condition, component.Weight))
+	}
+
+	breakdown := newScoreBreakdown(contributions, m.Normalizer)
+	buy, sell := m.thresholds(buyThreshold, sellThreshold)
+
+	recommendation := "hold"
+	if breakdown.Score > buy {
+		recommendation = "buy"
+	} else if breakdown.Score < sell {
+		recommendation = "sell"
+	}
+
+	return models.ModelScore{
+		Model:          m.Name,
+		Description:    m.Description,
+		RawScore:       breakdown.RawScore,
+		Score:          breakdown.Score,
+		Recommendation: recommendation,
+		BuyThreshold:   buy,
+		SellThreshold:  sell,
+		Breakdown:      breakdown,
+	}
+}
+
+// scoreContribution records one weighted component of a score
+func scoreContribution(name string, value float64, condition string, weight float64) models.ScoreContribution {
+	return models.ScoreContribution{
+		Name:         name,
+		Value:        value,
+		Condition:    condition,
+		Met:          value != 0,
+		Weight:       weight,
+		Contribution: weight * value,
+	}
+}
+
+// newScoreBreakdown sums contributions and normalizes the total to [-1, 1]
+func newScoreBreakdown(contributions []models.ScoreContribution, normalizer float64) models.ScoreBreakdown {
+	breakdown := models.ScoreBreakdown{Contributions: contributions, Normalizer: normalizer}
+	for _, c := range contributions {
+		breakdown.RawScore += c.Contribution
+	}
+	if normalizer > 0 {
+		breakdown.Score = math.Max(-1, math.Min(1, breakdown.RawScore/normalizer))
+	}
+	return breakdown
+}
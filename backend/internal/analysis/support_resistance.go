@@ -7,6 +7,12 @@ import (
 )
 
 func DetectSupportResistance(data []models.StockData) models.SupportResistance {
+	return DetectSupportResistanceWithGaps(data, DetectGaps(data))
+}
+
+// DetectSupportResistanceWithGaps is DetectSupportResistance with the gaps of
+// data already detected, so a caller that reports them detects them once
+func DetectSupportResistanceWithGaps(data []models.StockData, gaps []models.PriceGap) models.SupportResistance {
 	if len(data) < 20 {
 		return models.SupportResistance{
 			SupportLevels:    []float64{},
@@ -29,6 +35,11 @@ func DetectSupportResistance(data []models.StockData) models.SupportResistance {
 		}
 	}
 
+	// Unfilled gaps tend to act as support/resistance when price returns to them
+	gapSupports, gapResistances := unfilledGapLevels(gaps, currentPrice)
+	supports = append(supports, gapSupports...)
+	resistances = append(resistances, gapResistances...)
+
 	supports = consolidateLevels(supports)
 	resistances = consolidateLevels(resistances)
 
@@ -0,0 +1,310 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// TRADE PLAN
+// ============================================================================
+
+// Stop-loss methods
+const (
+	StopAuto       = "auto"       // Wyckoff, then structural, then ATR: the first one available
+	StopStructural = "structural" // Below the nearest support under the entries
+	StopWyckoff    = "wyckoff"    // Below the latest Spring low, or the trading range low
+	StopATR        = "atr"        // A multiple of the ATR below the lowest entry
+)
+
+// TradePlanConfig holds the account and risk settings of a trade plan. Zero
+// fields take the defaults of DefaultTradePlanConfig
+type TradePlanConfig struct {
+	AccountEquity      float64 `json:"account_equity"` // VND
+	RiskPercent        float64 `json:"risk_percent"`   // Percent of equity lost when the stop is hit with every tier filled
+	StopMethod         string  `json:"stop_method"`    // "auto", "structural", "wyckoff" or "atr"
+	StopBuffer         float64 `json:"stop_buffer"`    // Percent below a structural or Wyckoff level
+	ATRPeriod          int     `json:"atr_period"`
+	ATRMultiplier      float64 `json:"atr_multiplier"`
+	LotSize            int64   `json:"lot_size"`             // Shares are rounded down to whole lots
+	MaxPositionPercent float64 `json:"max_position_percent"` // Cap on the position value, percent of equity
+}
+
+// DefaultTradePlanConfig risks 1% of a 100M VND account with a stop 1% below
+// structure or 2 ATR(14) below the entries, in 100-share HOSE lots, without
+// leverage
+func DefaultTradePlanConfig() TradePlanConfig {
+	return TradePlanConfig{
+		AccountEquity:      100_000_000,
+		RiskPercent:        1,
+		StopMethod:         StopAuto,
+		StopBuffer:         1,
+		ATRPeriod:          14,
+		ATRMultiplier:      2,
+		LotSize:            100,
+		MaxPositionPercent: 100,
+	}
+}
+
+// withDefaults fills the zero fields from DefaultTradePlanConfig
+func (c TradePlanConfig) withDefaults() TradePlanConfig {
+	defaults := DefaultTradePlanConfig()
+	if c.AccountEquity <= 0 {
+		c.AccountEquity = defaults.AccountEquity
+	}
+	if c.RiskPercent <= 0 {
+		c.RiskPercent = defaults.RiskPercent
+	}
+	if c.StopMethod == "" {
+		c.StopMethod = defaults.StopMethod
+	}
+	if c.StopBuffer <= 0 {
+		c.StopBuffer = defaults.StopBuffer
+	}
+	if c.ATRPeriod <= 0 {
+		c.ATRPeriod = defaults.ATRPeriod
+	}
+	if c.ATRMultiplier <= 0 {
+		c.ATRMultiplier = defaults.ATRMultiplier
+	}
+	if c.LotSize <= 0 {
+		c.LotSize = defaults.LotSize
+	}
+	if c.MaxPositionPercent <= 0 {
+		c.MaxPositionPercent = defaults.MaxPositionPercent
+	}
+	return c
+}
+
+// Validate checks the stop method and that the risk is a sane fraction of equity
+func (c TradePlanConfig) Validate() error {
+	c = c.withDefaults()
+	switch c.StopMethod {
+	case StopAuto, StopStructural, StopWyckoff, StopATR:
+	default:
+		return fmt.Errorf("unknown stop_method %q", c.StopMethod)
+	}
+	if c.RiskPercent > 100 {
+		return fmt.Errorf("risk_percent must be at most 100, got %.2f", c.RiskPercent)
+	}
+	return nil
+}
+
+// BuildTradePlan turns a report's ranges into a long trade: half the position
+// at the top of the half-buy range and half at the top of the buy range, a
+// stop below both, and the position sized so that hitting the stop loses
+// RiskPercent of the equity. Half is taken off at the bottom of the sell range
+// and the rest at its top
+func BuildTradePlan(report *models.AnalysisReport, cfg TradePlanConfig) models.TradePlan {
+	cfg = cfg.withDefaults()
+	plan := models.TradePlan{
+		AccountEquity: cfg.AccountEquity,
+		RiskPercent:   cfg.RiskPercent,
+		RiskBudget:    cfg.AccountEquity * cfg.RiskPercent / 100,
+		LotSize:       cfg.LotSize,
+		Entries:       []models.TradeTier{},
+		Targets:       []models.TradeTier{},
+		Stops:         []models.StopCandidate{},
+		Notes:         []string{},
+	}
+
+	if report.Recommendation != "buy" {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("The recommendation is %s; the plan applies once it turns to buy", report.Recommendation))
+	}
+
+	// Scale in: the half-buy range is the moderate zone, the buy range the strong one
+	entries := []models.TradeTier{
+		{Label: "half_buy", Zone: report.HalfBuyRange, Price: report.HalfBuyRange.Max, Fraction: 0.5},
+		{Label: "buy", Zone: report.BuyRange, Price: report.BuyRange.Max, Fraction: 0.5},
+	}
+	if entries[1].Price <= 0 || entries[1].Price >= entries[0].Price {
+		// The zones coincide; a single tier takes the whole position
+		entries = entries[:1]
+		entries[0].Fraction = 1
+	}
+	if entries[0].Price <= 0 {
+		plan.Notes = append(plan.Notes, "No entry price: the report has no buy ranges")
+		return plan
+	}
+	lowestEntry := entries[len(entries)-1].Price
+
+	plan.Stops = stopCandidates(report, lowestEntry, cfg)
+	stop, ok := chooseStop(plan.Stops, cfg.StopMethod)
+	if !ok {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("No %s stop below the lowest entry %.0f", cfg.StopMethod, lowestEntry))
+		return plan
+	}
+	plan.StopMethod = stop.Method
+	plan.StopLoss = stop.Price
+
+	// Each tier buys the same number of shares per unit of fraction, so the risk
+	// per share is the average entry's distance to the stop
+	averageEntry := 0.0
+	for _, tier := range entries {
+		averageEntry += tier.Price * tier.Fraction
+	}
+	riskPerShare := averageEntry - plan.StopLoss
+	shares := plan.RiskBudget / riskPerShare
+
+	maxValue := cfg.AccountEquity * cfg.MaxPositionPercent / 100
+	if shares*averageEntry > maxValue {
+		shares = maxValue / averageEntry
+		plan.Notes = append(plan.Notes, fmt.Sprintf("Position capped at %.0f%% of equity; the stop risks less than the budget", cfg.MaxPositionPercent))
+	}
+
+	// The rounding remainder goes to the lowest entry, so the filled average is
+	// at or below the sized one and the risk stays within the budget
+	totalLots := int64(shares) / cfg.LotSize
+	for i, lots := range splitLots(totalLots, entries, len(entries)-1) {
+		entries[i].Lots = lots
+		entries[i].Shares = lots * cfg.LotSize
+	}
+	plan.Entries = entries
+
+	cost := 0.0
+	for _, tier := range entries {
+		plan.Shares += tier.Shares
+		cost += float64(tier.Shares) * tier.Price
+	}
+	plan.Lots = plan.Shares / cfg.LotSize
+	if plan.Shares == 0 {
+		plan.Notes = append(plan.Notes, fmt.Sprintf("The risk budget of %.0f does not cover one lot with the stop %.1f%% away", plan.RiskBudget, riskPerShare/averageEntry*100))
+		plan.AverageEntry = averageEntry
+		plan.RiskPerShare = riskPerShare
+		return plan
+	}
+	plan.AverageEntry = cost / float64(plan.Shares)
+	plan.RiskPerShare = plan.AverageEntry - plan.StopLoss
+	plan.PositionValue = cost
+	plan.PositionPercent = cost / cfg.AccountEquity * 100
+	plan.RiskAmount = plan.RiskPerShare * float64(plan.Shares)
+
+	// Scale out: half at the bottom of the sell range, the rest at its top
+	targets := []models.TradeTier{}
+	for _, target := range []models.TradeTier{
+		{Label: "sell_range_low", Zone: report.SellRange, Price: report.SellRange.Min, Fraction: 0.5},
+		{Label: "sell_range_high", Zone: report.SellRange, Price: report.SellRange.Max, Fraction: 0.5},
+	} {
+		if target.Price <= plan.AverageEntry {
+			plan.Notes = append(plan.Notes, fmt.Sprintf("Target %s at %.0f is not above the average entry", target.Label, target.Price))
+			continue
+		}
+		targets = append(targets, target)
+	}
+	if len(targets) == 1 {
+		targets[0].Fraction = 1
+	}
+	for i, lots := range splitLots(plan.Lots, targets, 0) {
+		targets[i].Lots = lots
+		targets[i].Shares = lots * cfg.LotSize
+	}
+
+	reward := 0.0
+	for i := range targets {
+		targets[i].RewardRisk = (targets[i].Price - plan.AverageEntry) / plan.RiskPerShare
+		targets[i].Profit = (targets[i].Price - plan.AverageEntry) * float64(targets[i].Shares)
+		reward += targets[i].Profit
+	}
+	plan.Targets = targets
+	if len(targets) > 0 {
+		plan.RewardRisk = reward / plan.RiskAmount
+	}
+	return plan
+}
+
+// stopCandidates computes every stop method's level below the lowest entry
+func stopCandidates(report *models.AnalysisReport, lowestEntry float64, cfg TradePlanConfig) []models.StopCandidate {
+	buffer := 1 - cfg.StopBuffer/100
+	candidates := []models.StopCandidate{}
+	add := func(method, reason string, price float64) {
+		if price <= 0 || price >= lowestEntry {
+			return
+		}
+		candidates = append(candidates, models.StopCandidate{
+			Method:          method,
+			Price:           price,
+			DistancePercent: (lowestEntry - price) / lowestEntry * 100,
+			Reason:          reason,
+		})
+	}
+
+	// A stop guards a level the entries sit on or above
+	if low, name, ok := springLow(report); ok && low <= lowestEntry {
+		add(StopWyckoff, fmt.Sprintf("%.0f%% below the %s low %.0f", cfg.StopBuffer, name, low), low*buffer)
+	} else if report.Wyckoff.TradingRange.Min > 0 && report.Wyckoff.TradingRange.Min <= lowestEntry {
+		low := report.Wyckoff.TradingRange.Min
+		add(StopWyckoff, fmt.Sprintf("%.0f%% below the trading range low %.0f", cfg.StopBuffer, low), low*buffer)
+	}
+
+	// Supports are sorted nearest first
+	for _, level := range report.SupportResistance.SupportLevels {
+		if level <= lowestEntry {
+			add(StopStructural, fmt.Sprintf("%.0f%% below support %.0f", cfg.StopBuffer, level), level*buffer)
+			break
+		}
+	}
+
+	if atr := CalculateATR(report.PriceHistory, cfg.ATRPeriod); atr > 0 {
+		add(StopATR, fmt.Sprintf("%.1f ATR(%d) of %.0f below the lowest entry", cfg.ATRMultiplier, cfg.ATRPeriod, atr), lowestEntry-cfg.ATRMultiplier*atr)
+	}
+	return candidates
+}
+
+// chooseStop picks the candidate of the method, or for "auto" the first
+// available of Wyckoff, structural and ATR
+func chooseStop(candidates []models.StopCandidate, method string) (models.StopCandidate, bool) {
+	order := []string{method}
+	if method == StopAuto {
+		order = []string{StopWyckoff, StopStructural, StopATR}
+	}
+	for _, want := range order {
+		for _, candidate := range candidates {
+			if candidate.Method == want {
+				return candidate, true
+			}
+		}
+	}
+	return models.StopCandidate{}, false
+}
+
+// springLow returns the lowest low across the latest Spring (or Test of Spring)
+// event's bars
+func springLow(report *models.AnalysisReport) (float64, string, bool) {
+	events := report.Wyckoff.Events
+	for i := len(events) - 1; i >= 0; i-- {
+		event := events[i]
+		if event.Name != "Spring" && event.Name != "Test of Spring" {
+			continue
+		}
+		start, end := event.StartDate, event.EndDate
+		if start.IsZero() {
+			start, end = event.Date, event.Date
+		}
+		low := math.Inf(1)
+		for _, bar := range report.PriceHistory {
+			if !bar.Date.Before(start) && !bar.Date.After(end) {
+				low = math.Min(low, bar.Low)
+			}
+		}
+		if !math.IsInf(low, 1) {
+			return low, event.Name, true
+		}
+	}
+	return 0, "", false
+}
+
+// splitLots divides lots across tiers by their fractions, rounding down and
+// giving the remainder to tiers[remainder]
+func splitLots(lots int64, tiers []models.TradeTier, remainder int) []int64 {
+	split := make([]int64, len(tiers))
+	assigned := int64(0)
+	for i, tier := range tiers {
+		split[i] = int64(float64(lots) * tier.Fraction)
+		assigned += split[i]
+	}
+	if len(split) > 0 {
+		split[remainder] += lots - assigned
+	}
+	return split
+}
@@ -0,0 +1,412 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"stocking-chain/internal/models"
+	"time"
+)
+
+const (
+	defaultTransformATRPeriod = 14
+	defaultPnFReversal        = 3
+	defaultLineBreakLines     = 3
+)
+
+// MinBoxSizePercent is the smallest box size, in percent of the last close, a
+// transform accepts; smaller boxes turn every tick into a brick
+const MinBoxSizePercent = 0.1
+
+// MaxTransformBars caps the bars a transform may produce
+const MaxTransformBars = 5000
+
+// ============================================================================
+// TRANSFORM DISPATCH
+// ============================================================================
+
+// ApplyTransform converts daily bars into the requested alternative bar series.
+// Box sizes left at zero are derived from the ATR of the input data
+func ApplyTransform(data []models.StockData, t models.BarTransform) (*models.TransformedSeries, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("no data to transform")
+	}
+
+	resolved := t
+	if resolved.ATRPeriod == 0 {
+		resolved.ATRPeriod = defaultTransformATRPeriod
+	}
+
+	series := &models.TransformedSeries{
+		Symbol: data[0].Symbol,
+	}
+
+	switch t.Type {
+	case "heikin_ashi":
+		series.Bars = HeikinAshi(data)
+
+	case "renko":
+		box, err := resolveBoxSize(data, resolved)
+		if err != nil {
+			return nil, err
+		}
+		resolved.BoxSize = box
+		series.Bars = Renko(data, box)
+
+	case "kagi":
+		box, err := resolveBoxSize(data, resolved)
+		if err != nil {
+			return nil, err
+		}
+		resolved.BoxSize = box
+		series.Bars = Kagi(data, box)
+
+	case "line_break":
+		if resolved.Lines <= 0 {
+			resolved.Lines = defaultLineBreakLines
+		}
+		series.Bars = LineBreak(data, resolved.Lines)
+
+	case "point_figure":
+		box, err := resolveBoxSize(data, resolved)
+		if err != nil {
+			return nil, err
+		}
+		resolved.BoxSize = box
+		if resolved.Reversal <= 0 {
+			resolved.Reversal = defaultPnFReversal
+		}
+		series.Columns = PointAndFigure(data, box, resolved.Reversal)
+		series.Bars = pointFigureBars(series.Columns, series.Symbol)
+
+	default:
+		return nil, fmt.Errorf("unknown transform type: %s", t.Type)
+	}
+
+	if len(series.Bars) > MaxTransformBars {
+		return nil, fmt.Errorf("transform produced more than %d bars; use a larger box size or fewer days", MaxTransformBars)
+	}
+
+	series.Transform = resolved
+	return series, nil
+}
+
+// resolveBoxSize returns the fixed box size, or the ATR when none was given.
+// Either must be at least MinBoxSizePercent of the last close
+func resolveBoxSize(data []models.StockData, t models.BarTransform) (float64, error) {
+	box := t.BoxSize
+	if box <= 0 {
+		box = CalculateATR(data, t.ATRPeriod)
+		if box <= 0 {
+			return 0, fmt.Errorf("not enough data for a %d-period ATR box size", t.ATRPeriod)
+		}
+	}
+
+	minBox := data[len(data)-1].Close * MinBoxSizePercent / 100
+	if box < minBox {
+		return 0, fmt.Errorf("box size %.4g is below %.1f%% of the last close (%.4g)", box, MinBoxSizePercent, minBox)
+	}
+	return box, nil
+}
+
+// ============================================================================
+// HEIKIN-ASHI
+// ============================================================================
+
+// HeikinAshi returns smoothed Heikin-Ashi candles, one per input bar
+func HeikinAshi(data []models.StockData) []models.StockData {
+	bars := make([]models.StockData, 0, len(data))
+
+	for i, d := range data {
+		haClose := (d.Open + d.High + d.Low + d.Close) / 4
+		haOpen := (d.Open + d.Close) / 2
+		if i > 0 {
+			prev := bars[i-1]
+			haOpen = (prev.Open + prev.Close) / 2
+		}
+
+		bars = append(bars, models.StockData{
+			Symbol:   d.Symbol,
+			Date:     d.Date,
+			Open:     haOpen,
+			High:     math.Max(d.High, math.Max(haOpen, haClose)),
+			Low:      math.Min(d.Low, math.Min(haOpen, haClose)),
+			Close:    haClose,
+			Volume:   d.Volume,
+			AdjClose: haClose,
+		})
+	}
+
+	return bars
+}
+
+// ============================================================================
+// RENKO
+// ============================================================================
+
+// Renko builds close-based bricks of a fixed box size. A reversal needs price
+// to move one full box beyond the opposite side of the last brick. A bar that
+// moves several boxes stamps its bricks a second apart, so every brick keeps a
+// distinct date for the date-keyed event, count and market lookups. Building
+// stops one brick past MaxTransformBars, which ApplyTransform rejects
+func Renko(data []models.StockData, boxSize float64) []models.StockData {
+	bricks := []models.StockData{}
+	if len(data) == 0 || boxSize <= 0 {
+		return bricks
+	}
+
+	top := data[0].Close
+	bottom := data[0].Close
+	volume := int64(0)
+
+	for _, d := range data {
+		volume += d.Volume
+
+		for n := 0; ; n++ {
+			date := d.Date.Add(time.Duration(n) * time.Second)
+			if d.Close >= top+boxSize {
+				bricks = append(bricks, segmentBar(d.Symbol, date, top, top+boxSize, volume))
+				bottom, top = top, top+boxSize
+			} else if d.Close <= bottom-boxSize {
+				bricks = append(bricks, segmentBar(d.Symbol, date, bottom, bottom-boxSize, volume))
+				top, bottom = bottom, bottom-boxSize
+			} else {
+				break
+			}
+			volume = 0
+			if len(bricks) > MaxTransformBars {
+				return bricks
+			}
+		}
+	}
+
+	return bricks
+}
+
+// ============================================================================
+// KAGI
+// ============================================================================
+
+// Kagi builds close-based Kagi lines; a new line starts when price reverses by
+// at least the reversal amount from the current line's extreme. The last line
+// is included even if still in progress
+func Kagi(data []models.StockData, reversal float64) []models.StockData {
+	lines := []models.StockData{}
+	if len(data) == 0 || reversal <= 0 {
+		return lines
+	}
+
+	start := data[0].Close
+	extreme := start
+	direction := 0
+	volume := int64(0)
+	lastDate := data[0].Date
+
+	for _, d := range data {
+		volume += d.Volume
+		price := d.Close
+
+		switch direction {
+		case 0:
+			if math.Abs(price-start) >= reversal {
+				extreme = price
+				lastDate = d.Date
+				if price > start {
+					direction = 1
+				} else {
+					direction = -1
+				}
+			}
+		case 1:
+			if price > extreme {
+				extreme = price
+				lastDate = d.Date
+			} else if extreme-price >= reversal {
+				lines = append(lines, segmentBar(d.Symbol, lastDate, start, extreme, volume))
+				volume = 0
+				start, extreme, direction, lastDate = extreme, price, -1, d.Date
+			}
+		case -1:
+			if price < extreme {
+				extreme = price
+				lastDate = d.Date
+			} else if price-extreme >= reversal {
+				lines = append(lines, segmentBar(d.Symbol, lastDate, start, extreme, volume))
+				volume = 0
+				start, extreme, direction, lastDate = extreme, price, 1, d.Date
+			}
+		}
+	}
+
+	if direction != 0 {
+		lines = append(lines, segmentBar(data[0].Symbol, lastDate, start, extreme, volume))
+	}
+
+	return lines
+}
+
+// ============================================================================
+// LINE BREAK
+// ============================================================================
+
+// LineBreak builds an N-line break chart: a new line is drawn when the close
+// extends the current direction, and a reversal line only when the close breaks
+// the extreme of the last N lines
+func LineBreak(data []models.StockData, lineCount int) []models.StockData {
+	lines := []models.StockData{}
+	if len(data) == 0 || lineCount <= 0 {
+		return lines
+	}
+
+	base := data[0].Close
+	volume := data[0].Volume
+
+	for _, d := range data[1:] {
+		volume += d.Volume
+		price := d.Close
+
+		if len(lines) == 0 {
+			if price != base {
+				lines = append(lines, segmentBar(d.Symbol, d.Date, base, price, volume))
+				volume = 0
+			}
+			continue
+		}
+
+		last := lines[len(lines)-1]
+		rising := last.Close > last.Open
+
+		lookback := lines[max(0, len(lines)-lineCount):]
+		highest := maxHigh(lookback)
+		lowest := minLow(lookback)
+
+		switch {
+		case rising && price > last.Close:
+			lines = append(lines, segmentBar(d.Symbol, d.Date, last.Close, price, volume))
+			volume = 0
+		case rising && price < lowest:
+			lines = append(lines, segmentBar(d.Symbol, d.Date, last.Open, price, volume))
+			volume = 0
+		case !rising && price < last.Close:
+			lines = append(lines, segmentBar(d.Symbol, d.Date, last.Close, price, volume))
+			volume = 0
+		case !rising && price > highest:
+			lines = append(lines, segmentBar(d.Symbol, d.Date, last.Open, price, volume))
+			volume = 0
+		}
+	}
+
+	return lines
+}
+
+// ============================================================================
+// POINT & FIGURE
+// ============================================================================
+
+// PointAndFigure builds high/low Point & Figure columns. Prices are snapped to
+// multiples of the box size, and a new column needs a move of reversal boxes
+func PointAndFigure(data []models.StockData, boxSize float64, reversal int) []models.PointFigureColumn {
+	columns := []models.PointFigureColumn{}
+	if len(data) == 0 || boxSize <= 0 || reversal <= 0 {
+		return columns
+	}
+
+	floorBox := func(p float64) float64 { return math.Floor(p/boxSize+1e-9) * boxSize }
+	ceilBox := func(p float64) float64 { return math.Ceil(p/boxSize-1e-9) * boxSize }
+	reversalDistance := float64(reversal) * boxSize
+
+	anchor := floorBox(data[0].Close)
+	volume := int64(0)
+	startDate := data[0].Date
+
+	for _, d := range data {
+		volume += d.Volume
+
+		if len(columns) == 0 {
+			// Wait for the first move of a full reversal distance to set the direction
+			switch {
+			case floorBox(d.High) >= anchor+reversalDistance:
+				columns = append(columns, models.PointFigureColumn{
+					Direction: "X", Bottom: anchor, Top: floorBox(d.High),
+					StartDate: startDate, EndDate: d.Date, Volume: volume,
+				})
+				volume = 0
+			case ceilBox(d.Low) <= anchor-reversalDistance:
+				columns = append(columns, models.PointFigureColumn{
+					Direction: "O", Top: anchor, Bottom: ceilBox(d.Low),
+					StartDate: startDate, EndDate: d.Date, Volume: volume,
+				})
+				volume = 0
+			}
+			continue
+		}
+
+		col := &columns[len(columns)-1]
+		if col.Direction == "X" {
+			if top := floorBox(d.High); top >= col.Top+boxSize {
+				col.Top = top
+				col.EndDate = d.Date
+				col.Volume += volume
+				volume = 0
+			} else if bottom := ceilBox(d.Low); bottom <= col.Top-reversalDistance {
+				columns = append(columns, models.PointFigureColumn{
+					Direction: "O", Top: col.Top - boxSize, Bottom: bottom,
+					StartDate: d.Date, EndDate: d.Date, Volume: volume,
+				})
+				volume = 0
+			}
+		} else {
+			if bottom := ceilBox(d.Low); bottom <= col.Bottom-boxSize {
+				col.Bottom = bottom
+				col.EndDate = d.Date
+				col.Volume += volume
+				volume = 0
+			} else if top := floorBox(d.High); top >= col.Bottom+reversalDistance {
+				columns = append(columns, models.PointFigureColumn{
+					Direction: "X", Bottom: col.Bottom + boxSize, Top: top,
+					StartDate: d.Date, EndDate: d.Date, Volume: volume,
+				})
+				volume = 0
+			}
+		}
+	}
+
+	for i := range columns {
+		columns[i].Boxes = int(math.Round((columns[i].Top-columns[i].Bottom)/boxSize)) + 1
+	}
+	if len(columns) > 0 {
+		columns[len(columns)-1].Volume += volume
+	}
+
+	return columns
+}
+
+// pointFigureBars converts P&F columns to bars so the analysis can run on them
+func pointFigureBars(columns []models.PointFigureColumn, symbol string) []models.StockData {
+	bars := make([]models.StockData, 0, len(columns))
+	for _, col := range columns {
+		if col.Direction == "X" {
+			bars = append(bars, segmentBar(symbol, col.EndDate, col.Bottom, col.Top, col.Volume))
+		} else {
+			bars = append(bars, segmentBar(symbol, col.EndDate, col.Top, col.Bottom, col.Volume))
+		}
+	}
+	return bars
+}
+
+// ============================================================================
+// HELPERS
+// ============================================================================
+
+// segmentBar builds a bar that moves from one price to another with no wicks
+func segmentBar(symbol string, date time.Time, from, to float64, volume int64) models.StockData {
+	return models.StockData{
+		Symbol:   symbol,
+		Date:     date,
+		Open:     from,
+		High:     math.Max(from, to),
+		Low:      math.Min(from, to),
+		Close:    to,
+		Volume:   volume,
+		AdjClose: to,
+	}
+}
@@ -0,0 +1,208 @@
+package analysis
+
+import (
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// VOLUME SPREAD ANALYSIS (VSA)
+// ============================================================================
+
+const (
+	vsaLookback       = 20 // Bars used for average spread and volume
+	vsaTrendLookback  = 10 // Bars used to judge the background trend
+	vsaSummaryWindow  = 20 // Recent bars tallied into the summary bias
+	vsaNewExtremeBars = 10 // Bars a shakeout or upthrust must exceed
+)
+
+// vsaSignalTypes maps each VSA signal to the side it favors
+var vsaSignalTypes = map[string]string{
+	"No Demand":       "bearish",
+	"No Supply":       "bullish",
+	"Stopping Volume": "bullish",
+	"Upthrust":        "bearish",
+	"Test":            "bullish",
+	"Effort to Rise":  "bullish",
+	"Effort to Fall":  "bearish",
+	"Shakeout":        "bullish",
+	// "Climactic Action" takes its side from the trend it ends
+}
+
+// AnalyzeVSA classifies every bar by spread, close position and relative volume
+// and tags the Volume Spread Analysis signal it shows, if any
+func AnalyzeVSA(data []models.StockData) models.VSAAnalysis {
+	analysis := models.VSAAnalysis{
+		Bars: []models.VSABar{},
+		Summary: models.VSASummary{
+			Counts: map[string]int{},
+			Bias:   "neutral",
+		},
+	}
+
+	if len(data) <= vsaLookback {
+		return analysis
+	}
+
+	for i := vsaLookback; i < len(data); i++ {
+		analysis.Bars = append(analysis.Bars, classifyVSABar(data, i))
+	}
+
+	analysis.Summary = summarizeVSA(analysis.Bars)
+	return analysis
+}
+
+// classifyVSABar describes bar idx and detects its VSA signal
+func classifyVSABar(data []models.StockData, idx int) models.VSABar {
+	current := data[idx]
+	prev := data[idx-1]
+
+	spread := current.High - current.Low
+	avgSpread := calculateAverageRange(data, idx, vsaLookback)
+	spreadRatio := 0.0
+	if avgSpread > 0 {
+		spreadRatio = spread / avgSpread
+	}
+
+	closeLocation := 0.5
+	if spread > 0 {
+		closeLocation = (current.Close - current.Low) / spread
+	}
+
+	relVolume := relativeVolume(data, idx, vsaLookback)
+
+	bar := models.VSABar{
+		Date:           current.Date,
+		Open:           current.Open,
+		High:           current.High,
+		Low:            current.Low,
+		Close:          current.Close,
+		Volume:         current.Volume,
+		SpreadRatio:    spreadRatio,
+		Spread:         classifySpread(spreadRatio),
+		CloseLocation:  closeLocation,
+		ClosePosition:  classifyClosePosition(closeLocation),
+		RelativeVolume: relVolume,
+		VolumeClass:    classifyVolume(relVolume),
+	}
+
+	upBar := current.Close > prev.Close
+	downBar := current.Close < prev.Close
+	uptrend := prev.Close > data[max(0, idx-vsaTrendLookback)].Close
+	lowerVolumeThanPrior := current.Volume < prev.Volume && current.Volume < data[idx-2].Volume
+	recent := data[max(0, idx-vsaNewExtremeBars):idx]
+	highVolume := bar.VolumeClass == "high" || bar.VolumeClass == "ultra_high"
+
+	// Checked from the strongest signal to the weakest; the first match wins
+	switch {
+	case bar.VolumeClass == "ultra_high" && bar.Spread == "wide" &&
+		((uptrend && bar.ClosePosition == "high") || (!uptrend && bar.ClosePosition == "low")):
+		bar.Signal = "Climactic Action"
+		bar.SignalType = "bullish"
+		if uptrend {
+			bar.SignalType = "bearish"
+		}
+
+	case current.Low < minLow(recent) && bar.Spread == "wide" && bar.ClosePosition == "high" && highVolume:
+		bar.Signal = "Shakeout"
+
+	case current.High > maxHigh(recent) && bar.Spread != "narrow" && bar.ClosePosition == "low" && highVolume:
+		bar.Signal = "Upthrust"
+
+	case (downBar || !uptrend) && highVolume && bar.Spread != "narrow" && bar.ClosePosition != "low":
+		bar.Signal = "Stopping Volume"
+
+	case upBar && bar.Spread == "wide" && bar.ClosePosition == "high" && highVolume:
+		bar.Signal = "Effort to Rise"
+
+	case downBar && bar.Spread == "wide" && bar.ClosePosition == "low" && highVolume:
+		bar.Signal = "Effort to Fall"
+
+	case current.Low < prev.Low && bar.Spread == "narrow" && bar.ClosePosition == "high" && bar.VolumeClass == "low":
+		bar.Signal = "Test"
+
+	case downBar && bar.Spread == "narrow" && bar.ClosePosition != "low" && lowerVolumeThanPrior:
+		bar.Signal = "No Supply"
+
+	case upBar && bar.Spread == "narrow" && bar.ClosePosition != "high" && lowerVolumeThanPrior:
+		bar.Signal = "No Demand"
+	}
+
+	if bar.Signal != "" && bar.SignalType == "" {
+		bar.SignalType = vsaSignalTypes[bar.Signal]
+	}
+
+	return bar
+}
+
+// summarizeVSA tallies every signal and derives the bias of the most recent bars
+func summarizeVSA(bars []models.VSABar) models.VSASummary {
+	summary := models.VSASummary{
+		Bars:   len(bars),
+		Counts: map[string]int{},
+		Bias:   "neutral",
+	}
+
+	for i, bar := range bars {
+		if bar.Signal == "" {
+			continue
+		}
+		summary.Counts[bar.Signal]++
+		latest := bars[i]
+		summary.LatestSignal = &latest
+
+		if i < len(bars)-vsaSummaryWindow {
+			continue
+		}
+		if bar.SignalType == "bullish" {
+			summary.RecentBullish++
+		} else if bar.SignalType == "bearish" {
+			summary.RecentBearish++
+		}
+	}
+
+	switch {
+	case summary.RecentBullish > summary.RecentBearish+1:
+		summary.Bias = "bullish"
+	case summary.RecentBearish > summary.RecentBullish+1:
+		summary.Bias = "bearish"
+	}
+
+	return summary
+}
+
+// classifySpread buckets a bar's range relative to the average range
+func classifySpread(ratio float64) string {
+	switch {
+	case ratio < 0.7:
+		return "narrow"
+	case ratio > 1.3:
+		return "wide"
+	}
+	return "average"
+}
+
+// classifyClosePosition buckets where the bar closed within its range
+func classifyClosePosition(location float64) string {
+	switch {
+	case location < 1.0/3:
+		return "low"
+	case location > 2.0/3:
+		return "high"
+	}
+	return "mid"
+}
+
+// classifyVolume buckets volume relative to the average volume
+func classifyVolume(relVolume float64) string {
+	switch {
+	case relVolume == 0:
+		return "unknown"
+	case relVolume < 0.8:
+		return "low"
+	case relVolume > 2.5:
+		return "ultra_high"
+	case relVolume > 1.5:
+		return "high"
+	}
+	return "average"
+}
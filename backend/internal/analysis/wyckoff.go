@@ -1,6 +1,7 @@
 package analysis
 
 import (
+	"fmt"
 	"math"
 	"stocking-chain/internal/models"
 )
@@ -11,19 +12,66 @@ import (
 
 // AnalyzeWyckoff performs complete Wyckoff method analysis on price data
 func AnalyzeWyckoff(data []models.StockData) models.WyckoffAnalysis {
+	return AnalyzeWyckoffWithMarket(data, nil)
+}
+
+// WyckoffOptions holds the tunable settings of the Wyckoff analysis
+type WyckoffOptions struct {
+	BuyThreshold  float64           // Normalized score above which the recommendation is buy
+	SellThreshold float64           // Normalized score below which the recommendation is sell
+	CauseCount    CauseCountOptions // Point & Figure chart of the horizontal count
+}
+
+// DefaultWyckoffOptions returns buy/sell thresholds of ±0.4 and a one-box
+// reversal count on an ATR box
+func DefaultWyckoffOptions() WyckoffOptions {
+	return DefaultAnalyzerConfig().wyckoffOptions()
+}
+
+// AnalyzeWyckoffWithMarket performs Wyckoff analysis and, when market series are
+// given, compares the stock against the index and sector for relative strength.
+// The weekly and monthly series are built from market.History when it is longer
+// than data
+func AnalyzeWyckoffWithMarket(data []models.StockData, market *MarketContext) models.WyckoffAnalysis {
+	return AnalyzeWyckoffWithOptions(data, market, DefaultWyckoffOptions())
+}
+
+// AnalyzeWyckoffWithOptions performs Wyckoff analysis with custom settings
+func AnalyzeWyckoffWithOptions(data []models.StockData, market *MarketContext, opts WyckoffOptions) models.WyckoffAnalysis {
+	history := data
+	if market != nil && len(market.History) > len(data) {
+		history = market.History
+	}
+
+	return analyzeWyckoff(data, market, AnalyzeWyckoffTimeframes(history, opts), opts)
+}
+
+// analyzeWyckoff runs the Wyckoff pipeline on one series. The higher timeframe
+// readings, when given, are aligned with its phase and feed the recommendation
+func analyzeWyckoff(data []models.StockData, market *MarketContext, timeframes []models.WyckoffTimeframe, opts WyckoffOptions) models.WyckoffAnalysis {
+	if timeframes == nil {
+		timeframes = []models.WyckoffTimeframe{}
+	}
+
 	if len(data) < 30 {
 		return models.WyckoffAnalysis{
-			Phase:           "insufficient_data",
-			PhaseConfidence: 0,
-			Events:          []models.WyckoffEvent{},
-			TradingRange:    models.PriceRange{},
-			EffortResult:    "unknown",
-			Recommendation:  "hold",
+			Phase:               "insufficient_data",
+			PhaseConfidence:     0,
+			Events:              []models.WyckoffEvent{},
+			TradingRange:        models.PriceRange{},
+			EffortResult:        "unknown",
+			Recommendation:      "hold",
 			RecommendationScore: 0,
-			BuyZone:         models.PriceRange{},
-			AccumulationZone: models.PriceRange{},
-			DistributionZone: models.PriceRange{},
-			SellZone:        models.PriceRange{},
+			ScoreBreakdown:      models.ScoreBreakdown{Contributions: []models.ScoreContribution{}},
+			BuyZone:             models.PriceRange{},
+			AccumulationZone:    models.PriceRange{},
+			DistributionZone:    models.PriceRange{},
+			SellZone:            models.PriceRange{},
+			PhaseHistory:        []models.WyckoffPhasePeriod{},
+			Ranges:              []models.HistoricalRange{},
+			Trends:              []models.TrendSegment{},
+			VSA:                 AnalyzeVSA(data),
+			Timeframes:          timeframes,
 		}
 	}
 
@@ -36,17 +84,42 @@ func AnalyzeWyckoff(data []models.StockData) models.WyckoffAnalysis {
 	// Determine current phase based on events and price action
 	phase, phaseConfidence := determinePhase(data, events, tradingRange)
 
+	// Walk the events through the schematic phases A-E
+	phaseHistory := buildPhaseHistory(data, events)
+	schematic, schematicPhase := currentSchematicPhase(phaseHistory)
+
 	// Analyze effort vs result (volume vs price movement)
 	effortResult := analyzeEffortVsResult(data)
 
+	// Classify each bar's spread, close and volume (VSA)
+	vsa := AnalyzeVSA(data)
+
+	// Compare against the market: Wyckoff favors stocks stronger than the index
+	var relativeStrength, sectorStrength *models.RelativeStrength
+	if market != nil {
+		if len(market.Index) > 0 {
+			relativeStrength = CalculateRelativeStrength(data, market.Index, market.IndexSymbol)
+		}
+		if len(market.Sector) > 0 {
+			sectorStrength = CalculateRelativeStrength(data, market.Sector, market.SectorSymbol)
+		}
+	}
+
+	// Check whether the weekly and monthly phases agree with the daily phase
+	alignment := alignTimeframes(phase, timeframes)
+
 	// Generate Wyckoff-specific recommendation
-	recommendation, recommendationScore := generateWyckoffRecommendation(
+	recommendation, scoreBreakdown := generateWyckoffRecommendation(
 		data,
 		phase,
 		phaseConfidence,
 		events,
 		tradingRange,
 		effortResult,
+		relativeStrength,
+		sectorStrength,
+		alignment,
+		opts,
 	)
 
 	// Calculate trading zones
@@ -57,18 +130,36 @@ func AnalyzeWyckoff(data []models.StockData) models.WyckoffAnalysis {
 		phase,
 	)
 
+	// Measure the cause built inside the range with a P&F horizontal count
+	causeCount := CountCause(data, events, tradingRange, phase, opts.CauseCount)
+
+	// Segment the full history into trading ranges and trends
+	ranges, trends := DetectHistoricalRanges(data)
+
 	return models.WyckoffAnalysis{
-		Phase:              phase,
-		PhaseConfidence:    phaseConfidence,
-		Events:             events,
-		TradingRange:       tradingRange,
-		EffortResult:       effortResult,
-		Recommendation:     recommendation,
-		RecommendationScore: recommendationScore,
-		BuyZone:            buyZone,
-		AccumulationZone:   accumZone,
-		DistributionZone:   distZone,
-		SellZone:           sellZone,
+		Phase:               phase,
+		PhaseConfidence:     phaseConfidence,
+		Events:              events,
+		TradingRange:        tradingRange,
+		EffortResult:        effortResult,
+		Schematic:           schematic,
+		SchematicPhase:      schematicPhase,
+		PhaseHistory:        phaseHistory,
+		Recommendation:      recommendation,
+		RecommendationScore: scoreBreakdown.Score,
+		ScoreBreakdown:      scoreBreakdown,
+		BuyZone:             buyZone,
+		AccumulationZone:    accumZone,
+		DistributionZone:    distZone,
+		SellZone:            sellZone,
+		CauseCount:          causeCount,
+		Ranges:              ranges,
+		Trends:              trends,
+		RelativeStrength:    relativeStrength,
+		SectorStrength:      sectorStrength,
+		VSA:                 vsa,
+		Timeframes:          timeframes,
+		Alignment:           alignment,
 	}
 }
 
@@ -86,24 +177,30 @@ func detectTradingRange(data []models.StockData) models.PriceRange {
 	lookback := min(60, len(data))
 	recentData := data[len(data)-lookback:]
 
+	return rangeBoundaries(recentData)
+}
+
+// rangeBoundaries averages the swing highs and lows of a consolidation into its
+// resistance (Max) and support (Min), falling back to the extremes when there are none
+func rangeBoundaries(bars []models.StockData) models.PriceRange {
 	// Find swing highs and lows
 	var highs, lows []float64
 
-	for i := 2; i < len(recentData)-2; i++ {
+	for i := 2; i < len(bars)-2; i++ {
 		// Swing high: higher than 2 bars on each side
-		if recentData[i].High > recentData[i-1].High &&
-			recentData[i].High > recentData[i-2].High &&
-			recentData[i].High > recentData[i+1].High &&
-			recentData[i].High > recentData[i+2].High {
-			highs = append(highs, recentData[i].High)
+		if bars[i].High > bars[i-1].High &&
+			bars[i].High > bars[i-2].High &&
+			bars[i].High > bars[i+1].High &&
+			bars[i].High > bars[i+2].High {
+			highs = append(highs, bars[i].High)
 		}
 
 		// Swing low: lower than 2 bars on each side
-		if recentData[i].Low < recentData[i-1].Low &&
-			recentData[i].Low < recentData[i-2].Low &&
-			recentData[i].Low < recentData[i+1].Low &&
-			recentData[i].Low < recentData[i+2].Low {
-			lows = append(lows, recentData[i].Low)
+		if bars[i].Low < bars[i-1].Low &&
+			bars[i].Low < bars[i-2].Low &&
+			bars[i].Low < bars[i+1].Low &&
+			bars[i].Low < bars[i+2].Low {
+			lows = append(lows, bars[i].Low)
 		}
 	}
 
@@ -113,13 +210,13 @@ func detectTradingRange(data []models.StockData) models.PriceRange {
 	if len(highs) > 0 {
 		rangeHigh = averageFloat64(highs)
 	} else {
-		rangeHigh = maxHigh(recentData)
+		rangeHigh = maxHigh(bars)
 	}
 
 	if len(lows) > 0 {
 		rangeLow = averageFloat64(lows)
 	} else {
-		rangeLow = minLow(recentData)
+		rangeLow = minLow(bars)
 	}
 
 	return models.PriceRange{
@@ -147,6 +244,7 @@ func detectWyckoffEvents(data []models.StockData, tradingRange models.PriceRange
 		current := data[i]
 		prev := data[i-1]
 		next := data[i+1]
+		barStart := len(events)
 
 		// Check for Selling Climax (SC) - high volume, wide spread down, near support
 		if sc := detectSellingClimax(data, i, avgVolume, tradingRange); sc != nil {
@@ -177,9 +275,17 @@ func detectWyckoffEvents(data []models.StockData, tradingRange models.PriceRange
 		if sow := detectSignOfWeakness(data, i, avgVolume, tradingRange); sow != nil {
 			events = append(events, *sow)
 		}
+
+		// Drop contradictory or superseded events on this bar before they become context
+		events = append(events[:barStart], resolveExclusiveEvents(events[barStart:])...)
+
+		// Events that depend on the structure so far (PS, AR, ST, LPS, JAC, BU, UTAD, LPSY)
+		events = append(events, detectContextualEvents(data, i, tradingRange, events)...)
+		events = append(events[:barStart], resolveExclusiveEvents(events[barStart:])...)
 	}
 
-	return events
+	// Merge multi-bar climaxes and runs of springs/upthrusts into single events
+	return clusterWyckoffEvents(data, events)
 }
 
 // detectSellingClimax identifies a Selling Climax event
@@ -679,7 +785,26 @@ func calculateConfidence(volumeRatio, rangeRatio, baseConfidence float64) float6
 // WYCKOFF RECOMMENDATION
 // ============================================================================
 
-// generateWyckoffRecommendation calculates buy/sell/hold recommendation based purely on Wyckoff signals
+// wyckoffEventWeights are the weights of recent events in the Wyckoff score;
+// bearish events count negatively. A Secondary Test follows its event type
+var wyckoffEventWeights = map[string]float64{
+	"Spring":                      2.5,
+	"Sign of Strength":            2.0,
+	"Selling Climax":              1.5,
+	"Test of Spring":              2.0,
+	"Last Point of Support":       2.0,
+	"Back-Up":                     2.0,
+	"Jump Across the Creek":       2.0,
+	"Upthrust":                    -2.5,
+	"Upthrust After Distribution": -2.5,
+	"Last Point of Supply":        -2.0,
+	"Secondary Test":              1.0,
+	"Sign of Weakness":            -2.0,
+	"Buying Climax":               -1.5,
+}
+
+// generateWyckoffRecommendation calculates buy/sell/hold recommendation based purely on
+// Wyckoff signals, and the contribution of each signal to the score
 func generateWyckoffRecommendation(
 	data []models.StockData,
 	phase string,
@@ -687,39 +812,51 @@ func generateWyckoffRecommendation(
 	events []models.WyckoffEvent,
 	tradingRange models.PriceRange,
 	effortResult string,
-) (string, float64) {
+	relativeStrength *models.RelativeStrength,
+	sectorStrength *models.RelativeStrength,
+	alignment *models.TimeframeAlignment,
+	opts WyckoffOptions,
+) (string, models.ScoreBreakdown) {
 	if len(data) == 0 || phase == "insufficient_data" || phase == "unknown" {
-		return "hold", 0
+		return "hold", models.ScoreBreakdown{Contributions: []models.ScoreContribution{}}
 	}
 
 	currentPrice := data[len(data)-1].Close
-	score := 0.0
+	contributions := []models.ScoreContribution{}
 
 	// 1. Phase Scoring (primary signal, weight: 3.0)
+	phaseCondition := fmt.Sprintf("%s phase, confidence %.2f", phase, phaseConfidence)
 	switch phase {
 	case "accumulation":
-		score += 3.0 * phaseConfidence
+		contributions = append(contributions, scoreContribution("phase", phaseConfidence, phaseCondition, 3.0))
 	case "markup":
-		score += 1.5 * phaseConfidence
+		contributions = append(contributions, scoreContribution("phase", phaseConfidence, phaseCondition, 1.5))
 	case "distribution":
-		score -= 3.0 * phaseConfidence
+		contributions = append(contributions, scoreContribution("phase", -phaseConfidence, phaseCondition, 3.0))
 	case "markdown":
-		score -= 1.5 * phaseConfidence
+		contributions = append(contributions, scoreContribution("phase", -phaseConfidence, phaseCondition, 1.5))
+	default:
+		contributions = append(contributions, scoreContribution("phase", 0, phaseCondition, 3.0))
 	}
 
 	// 2. Trading Range Position (secondary signal, weight: 2.0)
 	rangeSize := tradingRange.Max - tradingRange.Min
 	if rangeSize > 0 {
 		pricePosition := (currentPrice - tradingRange.Min) / rangeSize
+		condition := fmt.Sprintf("Price at %.0f%% of the trading range", pricePosition*100)
 
+		value := 0.0
 		if pricePosition < 0.3 {
 			// Price in lower 30% of range - accumulation zone
-			score += 2.0
+			value = 1
+			condition += " (lower 30%)"
 		} else if pricePosition > 0.7 {
 			// Price in upper 30% of range - distribution zone
-			score -= 2.0
+			value = -1
+			condition += " (upper 30%)"
 		}
 		// Middle 40% contributes 0
+		contributions = append(contributions, scoreContribution("range_position", value, condition, 2.0))
 	}
 
 	// 3. Recent Events (tertiary signal, look back 10 bars)
@@ -728,22 +865,16 @@ func generateWyckoffRecommendation(
 		recentDate := data[len(data)-recentThreshold].Date
 
 		for _, event := range events {
-			if event.Date.After(recentDate) || event.Date.Equal(recentDate) {
-				switch event.Name {
-				case "Spring":
-					score += 2.5 * event.Confidence
-				case "Sign of Strength":
-					score += 2.0 * event.Confidence
-				case "Selling Climax":
-					score += 1.5 * event.Confidence
-				case "Upthrust":
-					score -= 2.5 * event.Confidence
-				case "Sign of Weakness":
-					score -= 2.0 * event.Confidence
-				case "Buying Climax":
-					score -= 1.5 * event.Confidence
-				}
+			weight, ok := wyckoffEventWeights[event.Name]
+			if !ok || event.Date.Before(recentDate) {
+				continue
 			}
+			value := event.Confidence
+			if weight < 0 || (event.Name == "Secondary Test" && event.Type != "accumulation") {
+				value = -value
+			}
+			condition := fmt.Sprintf("%s on %s, confidence %.2f", event.Name, event.Date.Format("2006-01-02"), event.Confidence)
+			contributions = append(contributions, scoreContribution("event", value, condition, math.Abs(weight)))
 		}
 	}
 
@@ -757,31 +888,51 @@ func generateWyckoffRecommendation(
 
 			if isUptrending {
 				// Diverging in uptrend = reversal warning
-				score -= 1.5
+				contributions = append(contributions, scoreContribution("effort_result", -1, "Effort and result diverging after a 10-bar rise", 1.5))
 			} else {
 				// Diverging in downtrend = reversal opportunity
-				score += 1.5
+				contributions = append(contributions, scoreContribution("effort_result", 1, "Effort and result diverging after a 10-bar decline", 1.5))
 			}
 		}
 	} else if effortResult == "confirming" {
 		// Trend is healthy
-		score += 0.5
+		contributions = append(contributions, scoreContribution("effort_result", 1, "Effort and result confirming", 0.5))
+	}
+
+	// 5. Relative strength versus the market (and sector, at half weight)
+	maxScore := 9.0
+	if relativeStrength != nil {
+		contributions = append(contributions, scoreContribution("relative_strength",
+			relativeStrengthScore(relativeStrength, 1.0), relativeStrengthCondition(relativeStrength), 1.0))
+		maxScore += 2.0
+	}
+	if sectorStrength != nil {
+		contributions = append(contributions, scoreContribution("sector_strength",
+			relativeStrengthScore(sectorStrength, 1.0), relativeStrengthCondition(sectorStrength), 0.5))
+		maxScore += 1.0
+	}
+
+	// 6. Higher timeframes: favor setups the weekly and monthly phases agree with
+	if alignment != nil {
+		condition := fmt.Sprintf("%d higher timeframes agree with the daily %s bias, %d conflict", alignment.Agreeing, alignment.DailyBias, alignment.Conflicting)
+		contributions = append(contributions, scoreContribution("timeframe_alignment", alignment.Score, condition, 2.0))
+		maxScore += 2.0
 	}
 
 	// Normalize score to [-1, 1]
-	// Max possible score: ~3.0 + 2.0 + 2.5 + 1.5 = 9.0
-	// Min possible score: ~-3.0 + -2.0 + -2.5 + -1.5 = -9.0
-	normalizedScore := math.Max(-1, math.Min(1, score/9.0))
+	// Max possible score: ~3.0 + 2.0 + 2.5 + 1.5 = 9.0, plus up to 3.0 for relative strength
+	// and 2.0 for higher-timeframe alignment (and the same below zero)
+	breakdown := newScoreBreakdown(contributions, maxScore)
 
 	// Determine recommendation
 	recommendation := "hold"
-	if normalizedScore > 0.4 {
+	if breakdown.Score > opts.BuyThreshold {
 		recommendation = "buy"
-	} else if normalizedScore < -0.4 {
+	} else if breakdown.Score < opts.SellThreshold {
 		recommendation = "sell"
 	}
 
-	return recommendation, normalizedScore
+	return recommendation, breakdown
 }
 
 // ============================================================================
@@ -864,4 +1015,3 @@ func calculateWyckoffZones(
 
 	return buyZone, accumZone, distZone, sellZone
 }
-
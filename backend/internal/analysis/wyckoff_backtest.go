@@ -0,0 +1,335 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"stocking-chain/internal/models"
+	"strings"
+)
+
+// ============================================================================
+// WYCKOFF EVENT BACKTEST
+// ============================================================================
+
+// wyckoffConfirmationBars is how many bars after an event the detectors need
+// before it is reported: the event scan stops two bars short of the last bar
+// because Springs, Upthrusts and climaxes are judged by the bar that follows
+const wyckoffConfirmationBars = 2
+
+// Bounds of WyckoffBacktestConfig.Window: the detectors need 30 bars, and each
+// bar of the backtest re-analyzes a whole window
+const (
+	minWyckoffBacktestWindow = 30
+	maxWyckoffBacktestWindow = 1000
+)
+
+// WyckoffBacktestConfig selects the entries and exits of a Wyckoff backtest.
+// Trades are long only; each entry trigger is traded independently so its
+// results are not affected by the others
+type WyckoffBacktestConfig struct {
+	Window            int      `json:"window"`              // Bars of history each point-in-time analysis sees
+	EntryEvents       []string `json:"entry_events"`        // Events that open a position
+	EntryZones        []string `json:"entry_zones"`         // "buy", "accumulation": open when the close enters the zone
+	MinConfidence     float64  `json:"min_confidence"`      // Ignore entry and exit events below this confidence
+	ExitEvents        []string `json:"exit_events"`         // Events that close a position
+	ExitZones         []string `json:"exit_zones"`          // "distribution", "sell": close when the close enters the zone
+	StopLossPercent   float64  `json:"stop_loss_percent"`   // Percent below entry; 0 disables
+	TakeProfitPercent float64  `json:"take_profit_percent"` // Percent above entry; 0 disables
+	MaxHoldBars       int      `json:"max_hold_bars"`       // 0 holds until another exit fires
+}
+
+// DefaultWyckoffBacktestConfig enters on the bullish events and the buy zone and
+// exits on the bearish events, the sell zone, a 7% stop, a 15% target or 40 bars
+func DefaultWyckoffBacktestConfig() WyckoffBacktestConfig {
+	return WyckoffBacktestConfig{
+		Window: 200,
+		EntryEvents: []string{
+			"Selling Climax", "Spring", "Test of Spring", "Secondary Test", "Sign of Strength",
+			"Last Point of Support", "Jump Across the Creek", "Back-Up",
+		},
+		EntryZones:    []string{"buy"},
+		MinConfidence: 0.5,
+		ExitEvents: []string{
+			"Buying Climax", "Upthrust", "Upthrust After Distribution", "Sign of Weakness", "Last Point of Supply",
+		},
+		ExitZones:         []string{"sell"},
+		StopLossPercent:   7,
+		TakeProfitPercent: 15,
+		MaxHoldBars:       40,
+	}
+}
+
+// Validate reports a window outside 30 to 1000 bars, a confidence outside 0 to 1,
+// or a negative or impossible stop, target or holding period
+func (c WyckoffBacktestConfig) Validate() error {
+	if c.Window < minWyckoffBacktestWindow || c.Window > maxWyckoffBacktestWindow {
+		return fmt.Errorf("window must be between %d and %d bars, got %d", minWyckoffBacktestWindow, maxWyckoffBacktestWindow, c.Window)
+	}
+	if c.MinConfidence < 0 || c.MinConfidence > 1 {
+		return fmt.Errorf("min_confidence must be between 0 and 1, got %g", c.MinConfidence)
+	}
+	if c.StopLossPercent < 0 || c.StopLossPercent >= 100 {
+		return fmt.Errorf("stop_loss_percent must be at least 0 and below 100, got %g", c.StopLossPercent)
+	}
+	if c.TakeProfitPercent < 0 {
+		return fmt.Errorf("take_profit_percent must not be negative, got %g", c.TakeProfitPercent)
+	}
+	if c.MaxHoldBars < 0 {
+		return fmt.Errorf("max_hold_bars must not be negative, got %d", c.MaxHoldBars)
+	}
+	return nil
+}
+
+// wyckoffSnapshot is what a Wyckoff analysis knows at the close of one bar
+type wyckoffSnapshot struct {
+	newEvents []models.WyckoffEvent // Events first confirmed on this bar
+	zones     map[string]models.PriceRange
+}
+
+// wyckoffPosition is an open backtest trade
+type wyckoffPosition struct {
+	trade    models.WyckoffBacktestTrade
+	entryIdx int
+}
+
+// BacktestWyckoff walks the history bar by bar. At each close it analyzes only
+// the trailing cfg.Window bars, so no detector sees a later bar, and acts on the
+// events confirmed on that bar. Entries and event/zone exits fill at that close;
+// stops and targets fill intrabar on later bars, at the open when it gaps past them
+func BacktestWyckoff(data []models.StockData, cfg WyckoffBacktestConfig) models.WyckoffBacktestResult {
+	result := models.WyckoffBacktestResult{
+		Bars:      len(data),
+		Trades:    []models.WyckoffBacktestTrade{},
+		ByTrigger: []models.WyckoffTriggerStats{},
+	}
+	if len(data) == 0 {
+		return result
+	}
+	result.StartDate = data[0].Date
+	result.EndDate = data[len(data)-1].Date
+
+	window := max(cfg.Window, 30)
+	open := map[string]*wyckoffPosition{}
+	inZone := map[string]bool{}
+
+	for i := 29; i < len(data); i++ {
+		bar := data[i]
+		snapshot := wyckoffSnapshotAt(data[max(0, i+1-window) : i+1])
+
+		// Stops, targets and time limits on positions opened before this bar
+		for trigger, pos := range open {
+			if i == pos.entryIdx {
+				continue
+			}
+			if price, reason := wyckoffPriceExit(bar, pos, cfg, i); reason != "" {
+				result.Trades = append(result.Trades, closeWyckoffTrade(pos, bar, price, reason, i))
+				delete(open, trigger)
+			}
+		}
+
+		// Bearish events and zones known at this close
+		exitReason := ""
+		for _, event := range snapshot.newEvents {
+			if event.Type != "accumulation" && event.Confidence >= cfg.MinConfidence && slices.Contains(cfg.ExitEvents, event.Name) {
+				exitReason = "exit_event"
+			}
+		}
+		for _, zone := range cfg.ExitZones {
+			if z, ok := snapshot.zones[zone]; ok && bar.Close >= z.Min && bar.Close <= z.Max {
+				exitReason = "exit_zone"
+			}
+		}
+		if exitReason != "" {
+			for trigger, pos := range open {
+				if i == pos.entryIdx {
+					continue
+				}
+				result.Trades = append(result.Trades, closeWyckoffTrade(pos, bar, bar.Close, exitReason, i))
+				delete(open, trigger)
+			}
+		}
+
+		// New entries, one open position per trigger
+		for _, event := range snapshot.newEvents {
+			if event.Type == "distribution" || event.Confidence < cfg.MinConfidence || !slices.Contains(cfg.EntryEvents, event.Name) {
+				continue
+			}
+			if _, ok := open[event.Name]; ok || exitReason != "" {
+				continue
+			}
+			eventDate := event.Date
+			open[event.Name] = &wyckoffPosition{
+				trade: models.WyckoffBacktestTrade{
+					Trigger:    event.Name,
+					EventDate:  &eventDate,
+					EntryDate:  bar.Date,
+					EntryPrice: bar.Close,
+				},
+				entryIdx: i,
+			}
+		}
+		for _, zone := range cfg.EntryZones {
+			z, ok := snapshot.zones[zone]
+			inside := ok && bar.Close >= z.Min && bar.Close <= z.Max
+			entered := inside && !inZone[zone]
+			inZone[zone] = inside
+
+			trigger := zoneTrigger(zone)
+			if _, ok := open[trigger]; !entered || ok || exitReason != "" {
+				continue
+			}
+			open[trigger] = &wyckoffPosition{
+				trade: models.WyckoffBacktestTrade{
+					Trigger:    trigger,
+					EntryDate:  bar.Date,
+					EntryPrice: bar.Close,
+				},
+				entryIdx: i,
+			}
+		}
+	}
+
+	// Mark positions still open at the end to the last close
+	last := len(data) - 1
+	for _, pos := range open {
+		result.Trades = append(result.Trades, closeWyckoffTrade(pos, data[last], data[last].Close, "end_of_data", last))
+	}
+
+	sort.SliceStable(result.Trades, func(a, b int) bool {
+		return result.Trades[a].EntryDate.Before(result.Trades[b].EntryDate)
+	})
+
+	byTrigger := map[string][]models.WyckoffBacktestTrade{}
+	for _, trade := range result.Trades {
+		byTrigger[trade.Trigger] = append(byTrigger[trade.Trigger], trade)
+	}
+	for trigger, trades := range byTrigger {
+		result.ByTrigger = append(result.ByTrigger, wyckoffTradeStats(trigger, trades))
+	}
+	sort.Slice(result.ByTrigger, func(a, b int) bool {
+		if result.ByTrigger[a].Expectancy != result.ByTrigger[b].Expectancy {
+			return result.ByTrigger[a].Expectancy > result.ByTrigger[b].Expectancy
+		}
+		return result.ByTrigger[a].Trigger < result.ByTrigger[b].Trigger
+	})
+	result.Overall = wyckoffTradeStats("all", result.Trades)
+
+	return result
+}
+
+// wyckoffSnapshotAt analyzes a window that ends at the current bar and returns
+// the events confirmed on that bar, and the Wyckoff zones as of its close
+func wyckoffSnapshotAt(window []models.StockData) wyckoffSnapshot {
+	snapshot := wyckoffSnapshot{zones: map[string]models.PriceRange{}}
+	if len(window) < 30 {
+		return snapshot
+	}
+
+	tr := detectTradingRange(window)
+	events := detectWyckoffEvents(window, tr)
+
+	// The scan reaches the bar wyckoffConfirmationBars back from the close; an
+	// event (or the latest bar of a clustered run) on that bar is new today
+	newest := window[len(window)-1-wyckoffConfirmationBars].Date
+	for _, event := range events {
+		if event.EndDate.Equal(newest) {
+			snapshot.newEvents = append(snapshot.newEvents, event)
+		}
+	}
+
+	phase, _ := determinePhase(window, events, tr)
+	buy, accumulation, distribution, sell := calculateWyckoffZones(window, tr, events, phase)
+	snapshot.zones["buy"] = buy
+	snapshot.zones["accumulation"] = accumulation
+	snapshot.zones["distribution"] = distribution
+	snapshot.zones["sell"] = sell
+
+	return snapshot
+}
+
+// wyckoffPriceExit checks the stop, target and holding limit of pos against bar
+func wyckoffPriceExit(bar models.StockData, pos *wyckoffPosition, cfg WyckoffBacktestConfig, idx int) (float64, string) {
+	entry := pos.trade.EntryPrice
+
+	// Assume the stop is hit first when a bar spans both levels
+	if cfg.StopLossPercent > 0 {
+		stop := entry * (1 - cfg.StopLossPercent/100)
+		if bar.Low <= stop {
+			return math.Min(stop, bar.Open), "stop_loss"
+		}
+	}
+	if cfg.TakeProfitPercent > 0 {
+		target := entry * (1 + cfg.TakeProfitPercent/100)
+		if bar.High >= target {
+			return math.Max(target, bar.Open), "take_profit"
+		}
+	}
+	if cfg.MaxHoldBars > 0 && idx-pos.entryIdx >= cfg.MaxHoldBars {
+		return bar.Close, "max_hold"
+	}
+	return 0, ""
+}
+
+// closeWyckoffTrade completes pos at price on bar idx
+func closeWyckoffTrade(pos *wyckoffPosition, bar models.StockData, price float64, reason string, idx int) models.WyckoffBacktestTrade {
+	trade := pos.trade
+	trade.ExitDate = bar.Date
+	trade.ExitPrice = price
+	trade.ExitReason = reason
+	trade.Bars = idx - pos.entryIdx
+	trade.ReturnPercent = percentChange(trade.EntryPrice, price)
+	return trade
+}
+
+// wyckoffTradeStats summarizes the trades of one trigger. Expectancy is the
+// average return per trade; drawdown is measured on the compounded trade sequence
+func wyckoffTradeStats(trigger string, trades []models.WyckoffBacktestTrade) models.WyckoffTriggerStats {
+	stats := models.WyckoffTriggerStats{Trigger: trigger, Trades: len(trades)}
+	if len(trades) == 0 {
+		return stats
+	}
+
+	grossWin, grossLoss, bars := 0.0, 0.0, 0
+	equity, peak := 1.0, 1.0
+	for _, trade := range trades {
+		if trade.ReturnPercent > 0 {
+			stats.Wins++
+			grossWin += trade.ReturnPercent
+		} else {
+			stats.Losses++
+			grossLoss += trade.ReturnPercent
+		}
+		bars += trade.Bars
+
+		equity *= 1 + trade.ReturnPercent/100
+		peak = math.Max(peak, equity)
+		stats.MaxDrawdown = math.Max(stats.MaxDrawdown, (peak-equity)/peak*100)
+	}
+
+	n := float64(len(trades))
+	stats.WinRate = float64(stats.Wins) / n * 100
+	if stats.Wins > 0 {
+		stats.AvgWin = grossWin / float64(stats.Wins)
+	}
+	if stats.Losses > 0 {
+		stats.AvgLoss = grossLoss / float64(stats.Losses)
+	}
+	stats.Expectancy = (grossWin + grossLoss) / n
+	stats.TotalReturn = (equity - 1) * 100
+	stats.AvgBars = float64(bars) / n
+	if grossLoss < 0 {
+		stats.ProfitFactor = grossWin / -grossLoss
+	}
+
+	return stats
+}
+
+// zoneTrigger names the trigger of a zone entry, e.g. "Buy Zone"
+func zoneTrigger(zone string) string {
+	if zone == "" {
+		return "Zone"
+	}
+	return strings.ToUpper(zone[:1]) + zone[1:] + " Zone"
+}
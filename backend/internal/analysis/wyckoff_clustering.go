@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"sort"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// WYCKOFF EVENT CLUSTERING AND EXCLUSIVITY
+// ============================================================================
+
+// wyckoffClusterGap is the most bars allowed between two same-name events for
+// them to be merged into one cluster
+const wyckoffClusterGap = 3
+
+// wyckoffSupersedes lists events that replace another event on the same bar,
+// because they describe the same action with more context
+var wyckoffSupersedes = map[string][]string{
+	"Upthrust After Distribution": {"Upthrust"},
+	"Test of Spring":              {"Spring"},
+	"Jump Across the Creek":       {"Sign of Strength"},
+	"Selling Climax":              {"Preliminary Support", "Sign of Weakness"},
+	"Buying Climax":               {"Preliminary Supply", "Sign of Strength"},
+}
+
+// wyckoffExclusive lists pairs of events that cannot both happen on one bar.
+// When both fire, only the more confident one is kept
+var wyckoffExclusive = [][2]string{
+	{"Selling Climax", "Buying Climax"},
+	{"Spring", "Upthrust"},
+	{"Sign of Strength", "Sign of Weakness"},
+	{"Preliminary Support", "Preliminary Supply"},
+	{"Automatic Rally", "Automatic Reaction"},
+	{"Last Point of Support", "Last Point of Supply"},
+}
+
+// resolveExclusiveEvents removes events on a single bar that are superseded by,
+// or logically contradict, another event on that bar
+func resolveExclusiveEvents(barEvents []models.WyckoffEvent) []models.WyckoffEvent {
+	if len(barEvents) < 2 {
+		return barEvents
+	}
+
+	present := map[string]models.WyckoffEvent{}
+	for _, event := range barEvents {
+		present[event.Name] = event
+	}
+
+	dropped := map[string]bool{}
+	for name, superseded := range wyckoffSupersedes {
+		if _, ok := present[name]; !ok {
+			continue
+		}
+		for _, s := range superseded {
+			dropped[s] = true
+		}
+	}
+
+	for _, pair := range wyckoffExclusive {
+		a, okA := present[pair[0]]
+		b, okB := present[pair[1]]
+		if !okA || !okB || dropped[pair[0]] || dropped[pair[1]] {
+			continue
+		}
+		if a.Confidence >= b.Confidence {
+			dropped[pair[1]] = true
+		} else {
+			dropped[pair[0]] = true
+		}
+	}
+
+	kept := []models.WyckoffEvent{}
+	for _, event := range barEvents {
+		if !dropped[event.Name] {
+			kept = append(kept, event)
+		}
+	}
+	return kept
+}
+
+// clusterWyckoffEvents merges runs of same-name events that sit within
+// wyckoffClusterGap bars of each other. The merged event keeps the span of the
+// run in StartDate/EndDate and takes its date, price, volume and confidence
+// from the peak bar (highest confidence, then highest volume)
+func clusterWyckoffEvents(data []models.StockData, events []models.WyckoffEvent) []models.WyckoffEvent {
+	clusters := []models.WyckoffEvent{}
+	lastBar := []int{} // Bar index of the latest member of each cluster
+	open := map[string]int{}
+
+	for _, event := range events {
+		idx := barIndexForDate(data, event.Date)
+		key := event.Name + "|" + event.Type
+
+		if c, ok := open[key]; ok && idx-lastBar[c] <= wyckoffClusterGap {
+			cluster := &clusters[c]
+			cluster.EndDate = event.Date
+			cluster.Bars++
+			lastBar[c] = idx
+
+			if event.Confidence > cluster.Confidence ||
+				(event.Confidence == cluster.Confidence && event.Volume > cluster.Volume) {
+				cluster.Date = event.Date
+				cluster.Price = event.Price
+				cluster.Volume = event.Volume
+				cluster.Confidence = event.Confidence
+			}
+			continue
+		}
+
+		event.StartDate = event.Date
+		event.EndDate = event.Date
+		event.Bars = 1
+		open[key] = len(clusters)
+		clusters = append(clusters, event)
+		lastBar = append(lastBar, idx)
+	}
+
+	sort.SliceStable(clusters, func(i, j int) bool {
+		return clusters[i].Date.Before(clusters[j].Date)
+	})
+
+	return clusters
+}
@@ -0,0 +1,256 @@
+package analysis
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"stocking-chain/internal/models"
+	"time"
+)
+
+// ============================================================================
+// WYCKOFF CAUSE AND EFFECT (POINT & FIGURE COUNT)
+// ============================================================================
+
+// CauseCountOptions controls the Point & Figure chart used for the horizontal count
+type CauseCountOptions struct {
+	BoxSize   float64 `json:"box_size"`   // Fixed box size; 0 uses the ATR of the counted bars
+	ATRPeriod int     `json:"atr_period"` // ATR period when BoxSize is 0
+	Reversal  int     `json:"reversal"`   // Reversal in boxes; the classic Wyckoff count uses 1
+}
+
+// DefaultCauseCountOptions returns a one-box reversal chart with an ATR box size
+func DefaultCauseCountOptions() CauseCountOptions {
+	return CauseCountOptions{
+		BoxSize:   0,
+		ATRPeriod: 14,
+		Reversal:  1,
+	}
+}
+
+// Validate reports a negative box size, or an ATR period or reversal outside a usable range
+func (o CauseCountOptions) Validate() error {
+	if o.BoxSize < 0 {
+		return fmt.Errorf("cause_count box_size must not be negative, got %g", o.BoxSize)
+	}
+	if o.ATRPeriod < 2 || o.ATRPeriod > 200 {
+		return fmt.Errorf("cause_count atr_period must be between 2 and 200, got %d", o.ATRPeriod)
+	}
+	if o.Reversal < 1 || o.Reversal > 10 {
+		return fmt.Errorf("cause_count reversal must be between 1 and 10 boxes, got %d", o.Reversal)
+	}
+	return nil
+}
+
+// Events that open and close a count, in order of preference
+var (
+	accumulationCountStart = []string{"Preliminary Support", "Selling Climax"}
+	accumulationCountEnd   = []string{"Last Point of Support", "Test of Spring", "Spring"}
+	distributionCountStart = []string{"Preliminary Supply", "Buying Climax"}
+	distributionCountEnd   = []string{"Last Point of Supply", "Upthrust After Distribution", "Upthrust"}
+)
+
+// CountCause measures the horizontal Point & Figure count across the current
+// trading range and projects the cause it has built into price targets.
+// The count runs from the PS/SC (or PSY/BC) to the last LPS (or LPSY), falling
+// back to the start of the range and the latest bar when those events are missing
+func CountCause(
+	data []models.StockData,
+	events []models.WyckoffEvent,
+	tr models.PriceRange,
+	phase string,
+	opts CauseCountOptions,
+) *models.CauseCount {
+	if len(data) < 20 || tr.Max <= tr.Min {
+		return nil
+	}
+
+	structure := "accumulation"
+	startNames, endNames := accumulationCountStart, accumulationCountEnd
+	if phase == "distribution" || phase == "markdown" {
+		structure = "distribution"
+		startNames, endNames = distributionCountStart, distributionCountEnd
+	}
+
+	// Only events inside the window the trading range was measured on can bound the count
+	windowStart := len(data) - min(60, len(data))
+
+	endIdx, endEvent := len(data)-1, "Latest Bar"
+	if idx, event := lastEventIndex(data, events, endNames, windowStart, len(data)-1); idx >= 0 {
+		endIdx, endEvent = idx, event.Name
+	}
+
+	startIdx, startEvent := rangeEntryIndex(data, tr, windowStart, endIdx), "Range Start"
+	if idx, event := firstEventIndex(data, events, startNames, windowStart, endIdx); idx >= 0 {
+		startIdx, startEvent = idx, event.Name
+	}
+
+	if endIdx-startIdx < 5 {
+		return nil
+	}
+
+	counted := data[startIdx : endIdx+1]
+
+	if opts.Reversal <= 0 {
+		opts.Reversal = 1
+	}
+	if opts.ATRPeriod <= 0 {
+		opts.ATRPeriod = 14
+	}
+	boxSize := opts.BoxSize
+	if boxSize <= 0 {
+		boxSize = CalculateATR(data[:endIdx+1], opts.ATRPeriod)
+	}
+	// A fixed box far below the price would chart every tick as a column
+	boxSize = max(boxSize, data[endIdx].Close*MinBoxSizePercent/100)
+	if boxSize <= 0 {
+		return nil
+	}
+
+	columns := PointAndFigure(counted, boxSize, opts.Reversal)
+	if len(columns) == 0 {
+		return nil
+	}
+
+	// The count line sits at the LPS/LPSY row when one was found, otherwise at
+	// the row crossed by the most columns
+	countLine := 0.0
+	if endEvent != "Latest Bar" {
+		countLine = math.Round(data[endIdx].Close/boxSize) * boxSize
+	} else {
+		countLine = densestRow(columns, boxSize)
+	}
+
+	width := 0
+	for _, col := range columns {
+		if col.Bottom <= countLine+boxSize/2 && col.Top >= countLine-boxSize/2 {
+			width++
+		}
+	}
+	if width == 0 {
+		width = len(columns)
+	}
+
+	cause := float64(width) * boxSize * float64(opts.Reversal)
+	currentPrice := data[len(data)-1].Close
+
+	bias := "upside"
+	if structure == "distribution" {
+		bias = "downside"
+	}
+
+	return &models.CauseCount{
+		Structure:  structure,
+		Bias:       bias,
+		BoxSize:    boxSize,
+		Reversal:   opts.Reversal,
+		StartDate:  data[startIdx].Date,
+		EndDate:    data[endIdx].Date,
+		StartEvent: startEvent,
+		EndEvent:   endEvent,
+		CountLine:  countLine,
+		Columns:    width,
+		Cause:      cause,
+		Targets: []models.CauseTarget{
+			causeTarget("upside", "count_line", countLine+cause, currentPrice),
+			causeTarget("upside", "range_extreme", tr.Min+cause, currentPrice),
+			causeTarget("downside", "count_line", math.Max(0, countLine-cause), currentPrice),
+			causeTarget("downside", "range_extreme", math.Max(0, tr.Max-cause), currentPrice),
+		},
+	}
+}
+
+// firstEventIndex returns the bar index of the earliest named event within [from, to]
+func firstEventIndex(data []models.StockData, events []models.WyckoffEvent, names []string, from, to int) (int, models.WyckoffEvent) {
+	best := -1
+	var found models.WyckoffEvent
+	for _, event := range events {
+		if !slices.Contains(names, event.Name) {
+			continue
+		}
+		idx := barIndexForDate(data, event.Date)
+		if idx < from || idx > to {
+			continue
+		}
+		if best < 0 || idx < best {
+			best, found = idx, event
+		}
+	}
+	return best, found
+}
+
+// lastEventIndex returns the bar index of the latest event within [from, to],
+// preferring names earlier in the list when several exist
+func lastEventIndex(data []models.StockData, events []models.WyckoffEvent, names []string, from, to int) (int, models.WyckoffEvent) {
+	for _, name := range names {
+		best := -1
+		var found models.WyckoffEvent
+		for _, event := range events {
+			if event.Name != name {
+				continue
+			}
+			idx := barIndexForDate(data, event.Date)
+			if idx >= from && idx <= to && idx > best {
+				best, found = idx, event
+			}
+		}
+		if best >= 0 {
+			return best, found
+		}
+	}
+	return -1, models.WyckoffEvent{}
+}
+
+// rangeEntryIndex returns the first bar from `from` whose close sits inside the trading range
+func rangeEntryIndex(data []models.StockData, tr models.PriceRange, from, to int) int {
+	for i := from; i <= to; i++ {
+		if data[i].Close >= tr.Min && data[i].Close <= tr.Max {
+			return i
+		}
+	}
+	return from
+}
+
+// densestRow returns the P&F row price crossed by the most columns
+func densestRow(columns []models.PointFigureColumn, boxSize float64) float64 {
+	counts := map[int]int{}
+	for _, col := range columns {
+		for row := int(math.Round(col.Bottom / boxSize)); row <= int(math.Round(col.Top/boxSize)); row++ {
+			counts[row]++
+		}
+	}
+
+	bestRow, bestCount := 0, -1
+	for row, count := range counts {
+		if count > bestCount || (count == bestCount && row < bestRow) {
+			bestRow, bestCount = row, count
+		}
+	}
+	return float64(bestRow) * boxSize
+}
+
+// barIndexForDate returns the index of the bar on the given date, or -1 if absent
+func barIndexForDate(data []models.StockData, date time.Time) int {
+	idx := sort.Search(len(data), func(i int) bool {
+		return !data[i].Date.Before(date)
+	})
+	if idx < len(data) && data[idx].Date.Equal(date) {
+		return idx
+	}
+	return -1
+}
+
+// causeTarget builds a projected target with its distance from the current price
+func causeTarget(direction, basis string, price, currentPrice float64) models.CauseTarget {
+	change := 0.0
+	if currentPrice > 0 {
+		change = (price - currentPrice) / currentPrice * 100
+	}
+	return models.CauseTarget{
+		Direction: direction,
+		Basis:     basis,
+		Price:     price,
+		Change:    change,
+	}
+}
@@ -0,0 +1,476 @@
+package analysis
+
+import (
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// CONTEXTUAL WYCKOFF EVENTS
+// ============================================================================
+
+// detectContextualEvents finds the events that only make sense relative to the
+// structure built so far: PS/PSY, AR, ST, Test of Spring, LPS, JAC, BU, UTAD and LPSY.
+// events holds everything detected on earlier bars, in chronological order
+func detectContextualEvents(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) []models.WyckoffEvent {
+	found := []models.WyckoffEvent{}
+
+	detectors := []func([]models.StockData, int, models.PriceRange, []models.WyckoffEvent) *models.WyckoffEvent{
+		detectPreliminarySupport,
+		detectPreliminarySupply,
+		detectAutomaticRally,
+		detectAutomaticReaction,
+		detectSecondaryTest,
+		detectTestOfSpring,
+		detectLastPointOfSupport,
+		detectJumpAcrossTheCreek,
+		detectBackUp,
+		detectUpthrustAfterDistribution,
+		detectLastPointOfSupply,
+	}
+
+	for _, detect := range detectors {
+		if event := detect(data, idx, tr, events); event != nil {
+			found = append(found, *event)
+		}
+	}
+
+	return found
+}
+
+// detectPreliminarySupport identifies the first heavy buying after a prolonged decline
+func detectPreliminarySupport(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 20 || idx >= len(data)-1 {
+		return nil
+	}
+
+	// Only the first support in a new structure counts
+	if recent := lastEventIndexOf(data, events, "Preliminary Support", "Selling Climax"); recent >= 0 && idx-recent <= 20 {
+		return nil
+	}
+
+	current := data[idx]
+	decline := (data[idx-1].Close - data[idx-20].Close) / data[idx-20].Close
+	volumeRatio := relativeVolume(data, idx, 20)
+	position, rangeRatio := barShape(data, idx)
+
+	// Buyers absorb supply: heavy volume, close off the lows, and the next bar holds the low
+	if decline < -0.08 && volumeRatio > 1.5 && position >= 0.5 && data[idx+1].Low >= current.Low {
+		return &models.WyckoffEvent{
+			Name:       "Preliminary Support",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: calculateConfidence(volumeRatio, rangeRatio, 0.6),
+		}
+	}
+
+	return nil
+}
+
+// detectPreliminarySupply identifies the first heavy selling after a prolonged advance
+func detectPreliminarySupply(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 20 || idx >= len(data)-1 {
+		return nil
+	}
+
+	if recent := lastEventIndexOf(data, events, "Preliminary Supply", "Buying Climax"); recent >= 0 && idx-recent <= 20 {
+		return nil
+	}
+
+	current := data[idx]
+	advance := (data[idx-1].Close - data[idx-20].Close) / data[idx-20].Close
+	volumeRatio := relativeVolume(data, idx, 20)
+	position, rangeRatio := barShape(data, idx)
+
+	// Sellers meet demand: heavy volume, close off the highs, and the next bar fails to exceed the high
+	if advance > 0.08 && volumeRatio > 1.5 && position <= 0.5 && data[idx+1].High <= current.High {
+		return &models.WyckoffEvent{
+			Name:       "Preliminary Supply",
+			Type:       "distribution",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: calculateConfidence(volumeRatio, rangeRatio, 0.6),
+		}
+	}
+
+	return nil
+}
+
+// detectAutomaticRally identifies the rally high that follows a Selling Climax.
+// Its high marks the top of the new trading range
+func detectAutomaticRally(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 1 || idx >= len(data)-1 {
+		return nil
+	}
+
+	scIdx := lastEventIndexOf(data, events, "Selling Climax")
+	if scIdx < 0 || idx-scIdx < 2 || idx-scIdx > 15 {
+		return nil
+	}
+	if lastEventIndexOf(data, events, "Automatic Rally") > scIdx {
+		return nil
+	}
+
+	current := data[idx]
+	climaxLow := data[scIdx].Low
+	rally := (current.High - climaxLow) / climaxLow
+	avgRange := calculateAverageRange(data, idx, 10)
+
+	isSwingHigh := current.High > data[idx-1].High && data[idx+1].High < current.High
+	isHighestSinceClimax := current.High >= maxHigh(data[scIdx:idx+1])
+
+	if isSwingHigh && isHighestSinceClimax && rally >= 0.03 && current.High-climaxLow >= 2*avgRange {
+		return &models.WyckoffEvent{
+			Name:       "Automatic Rally",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: 0.7,
+		}
+	}
+
+	return nil
+}
+
+// detectAutomaticReaction identifies the reaction low that follows a Buying Climax.
+// Its low marks the bottom of the new trading range
+func detectAutomaticReaction(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 1 || idx >= len(data)-1 {
+		return nil
+	}
+
+	bcIdx := lastEventIndexOf(data, events, "Buying Climax")
+	if bcIdx < 0 || idx-bcIdx < 2 || idx-bcIdx > 15 {
+		return nil
+	}
+	if lastEventIndexOf(data, events, "Automatic Reaction") > bcIdx {
+		return nil
+	}
+
+	current := data[idx]
+	climaxHigh := data[bcIdx].High
+	reaction := (climaxHigh - current.Low) / climaxHigh
+	avgRange := calculateAverageRange(data, idx, 10)
+
+	isSwingLow := current.Low < data[idx-1].Low && data[idx+1].Low > current.Low
+	isLowestSinceClimax := current.Low <= minLow(data[bcIdx:idx+1])
+
+	if isSwingLow && isLowestSinceClimax && reaction >= 0.03 && climaxHigh-current.Low >= 2*avgRange {
+		return &models.WyckoffEvent{
+			Name:       "Automatic Reaction",
+			Type:       "distribution",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: 0.7,
+		}
+	}
+
+	return nil
+}
+
+// detectSecondaryTest identifies a revisit of the climax area on lighter volume
+// after the automatic rally (accumulation) or reaction (distribution)
+func detectSecondaryTest(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx >= len(data)-1 {
+		return nil
+	}
+
+	if recent := lastEventIndexOf(data, events, "Secondary Test"); recent >= 0 && idx-recent <= 3 {
+		return nil
+	}
+
+	current := data[idx]
+	next := data[idx+1]
+
+	// Accumulation: retest the Selling Climax low with less supply
+	scIdx := lastEventIndexOf(data, events, "Selling Climax")
+	arIdx := lastEventIndexOf(data, events, "Automatic Rally")
+	if scIdx >= 0 && arIdx > scIdx && idx > arIdx && idx-scIdx <= 40 {
+		climax := data[scIdx]
+		nearLow := current.Low <= climax.Low*1.03 && current.Low >= climax.Low*0.97
+		lighter := float64(current.Volume) < float64(climax.Volume)*0.8
+		held := next.Low >= current.Low
+
+		if nearLow && lighter && held {
+			return &models.WyckoffEvent{
+				Name:       "Secondary Test",
+				Type:       "accumulation",
+				Date:       current.Date,
+				Price:      current.Close,
+				Volume:     current.Volume,
+				Confidence: secondaryTestConfidence(current.Volume, climax.Volume),
+			}
+		}
+	}
+
+	// Distribution: retest the Buying Climax high with less demand
+	bcIdx := lastEventIndexOf(data, events, "Buying Climax")
+	reactionIdx := lastEventIndexOf(data, events, "Automatic Reaction")
+	if bcIdx >= 0 && reactionIdx > bcIdx && idx > reactionIdx && idx-bcIdx <= 40 {
+		climax := data[bcIdx]
+		nearHigh := current.High >= climax.High*0.97 && current.High <= climax.High*1.03
+		lighter := float64(current.Volume) < float64(climax.Volume)*0.8
+		held := next.High <= current.High
+
+		if nearHigh && lighter && held {
+			return &models.WyckoffEvent{
+				Name:       "Secondary Test",
+				Type:       "distribution",
+				Date:       current.Date,
+				Price:      current.Close,
+				Volume:     current.Volume,
+				Confidence: secondaryTestConfidence(current.Volume, climax.Volume),
+			}
+		}
+	}
+
+	return nil
+}
+
+// detectTestOfSpring identifies a low-volume retest of a Spring's low that holds
+func detectTestOfSpring(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	springIdx := lastEventIndexOf(data, events, "Spring")
+	if springIdx < 0 || idx-springIdx < 2 || idx-springIdx > 10 {
+		return nil
+	}
+	if lastEventIndexOf(data, events, "Test of Spring") > springIdx {
+		return nil
+	}
+
+	current := data[idx]
+	spring := data[springIdx]
+	position, _ := barShape(data, idx)
+
+	nearSpringLow := current.Low <= spring.Low*1.03 && current.Low >= spring.Low*0.99
+	lighter := float64(current.Volume) < float64(spring.Volume)*0.8
+
+	if nearSpringLow && lighter && position >= 0.5 {
+		confidence := 0.75
+		if float64(current.Volume) < float64(spring.Volume)*0.6 {
+			confidence = 0.85
+		}
+
+		return &models.WyckoffEvent{
+			Name:       "Test of Spring",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: confidence,
+		}
+	}
+
+	return nil
+}
+
+// detectLastPointOfSupport identifies a quiet higher low after a Spring or Sign of Strength
+func detectLastPointOfSupport(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 1 || idx >= len(data)-1 {
+		return nil
+	}
+
+	ctxIdx := lastEventIndexOf(data, events, "Sign of Strength", "Jump Across the Creek", "Back-Up", "Test of Spring", "Spring")
+	if ctxIdx < 0 || idx-ctxIdx < 2 || idx-ctxIdx > 20 {
+		return nil
+	}
+	if recent := lastEventIndexOf(data, events, "Last Point of Support"); recent >= 0 && idx-recent <= 5 {
+		return nil
+	}
+
+	current := data[idx]
+	isSwingLow := current.Low < data[idx-1].Low && current.Low <= data[idx+1].Low
+	quiet := relativeVolume(data, idx, 20) < 0.9 && current.High-current.Low <= calculateAverageRange(data, idx, 10)
+	higherLow := current.Low > tr.Min && current.Low > data[ctxIdx].Low
+
+	if isSwingLow && quiet && higherLow {
+		return &models.WyckoffEvent{
+			Name:       "Last Point of Support",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: 0.75,
+		}
+	}
+
+	return nil
+}
+
+// detectJumpAcrossTheCreek identifies a decisive close above range resistance
+// (the creek) once accumulation events have been seen inside the range
+func detectJumpAcrossTheCreek(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 1 || tr.Max == 0 {
+		return nil
+	}
+
+	ctxIdx := lastEventIndexOf(data, events, "Selling Climax", "Secondary Test", "Spring", "Test of Spring", "Preliminary Support")
+	if ctxIdx < 0 || idx-ctxIdx > 60 {
+		return nil
+	}
+	if recent := lastEventIndexOf(data, events, "Jump Across the Creek"); recent >= 0 && idx-recent <= 20 {
+		return nil
+	}
+
+	current := data[idx]
+	creek := tr.Max
+	position, rangeRatio := barShape(data, idx)
+	volumeRatio := relativeVolume(data, idx, 20)
+
+	crossed := data[idx-1].Close <= creek && current.Close > creek*1.01
+
+	if crossed && current.Close > current.Open && position > 0.6 && volumeRatio > 1.2 && rangeRatio > 1.2 {
+		return &models.WyckoffEvent{
+			Name:       "Jump Across the Creek",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: calculateConfidence(volumeRatio, rangeRatio, 0.75),
+		}
+	}
+
+	return nil
+}
+
+// detectBackUp identifies the low-volume pullback to the creek after a Jump Across the Creek
+func detectBackUp(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	jacIdx := lastEventIndexOf(data, events, "Jump Across the Creek")
+	if jacIdx < 0 || idx-jacIdx < 2 || idx-jacIdx > 15 {
+		return nil
+	}
+	if lastEventIndexOf(data, events, "Back-Up") > jacIdx {
+		return nil
+	}
+
+	current := data[idx]
+	creek := tr.Max
+	position, _ := barShape(data, idx)
+
+	backAtCreek := current.Low <= creek*1.02 && current.Close >= creek*0.99
+	quiet := relativeVolume(data, idx, 20) < 1.0
+
+	if backAtCreek && quiet && position >= 0.5 {
+		return &models.WyckoffEvent{
+			Name:       "Back-Up",
+			Type:       "accumulation",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: 0.8,
+		}
+	}
+
+	return nil
+}
+
+// detectUpthrustAfterDistribution identifies a failed breakout above resistance
+// late in a distribution range, after a Buying Climax or Preliminary Supply
+func detectUpthrustAfterDistribution(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx >= len(data)-1 || tr.Max == 0 {
+		return nil
+	}
+
+	ctxIdx := lastEventIndexOf(data, events, "Buying Climax", "Preliminary Supply", "Automatic Reaction")
+	if ctxIdx < 0 || idx-ctxIdx < 10 || idx-ctxIdx > 60 {
+		return nil
+	}
+	if recent := lastEventIndexOf(data, events, "Upthrust After Distribution"); recent >= 0 && idx-recent <= 10 {
+		return nil
+	}
+
+	current := data[idx]
+	next := data[idx+1]
+	penetration := (current.High - tr.Max) / tr.Max
+
+	failed := current.High > tr.Max && penetration <= 0.05 && current.Close < tr.Max && next.Close < current.Close
+
+	if failed {
+		confidence := 0.75
+		if relativeVolume(data, idx, 20) > 1.5 {
+			confidence = 0.85
+		}
+
+		return &models.WyckoffEvent{
+			Name:       "Upthrust After Distribution",
+			Type:       "distribution",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: confidence,
+		}
+	}
+
+	return nil
+}
+
+// detectLastPointOfSupply identifies a weak, low-volume rally that fails after a Sign of Weakness
+func detectLastPointOfSupply(data []models.StockData, idx int, tr models.PriceRange, events []models.WyckoffEvent) *models.WyckoffEvent {
+	if idx < 1 || idx >= len(data)-1 {
+		return nil
+	}
+
+	ctxIdx := lastEventIndexOf(data, events, "Sign of Weakness", "Upthrust After Distribution")
+	if ctxIdx < 0 || idx-ctxIdx < 2 || idx-ctxIdx > 20 {
+		return nil
+	}
+	if recent := lastEventIndexOf(data, events, "Last Point of Supply"); recent >= 0 && idx-recent <= 5 {
+		return nil
+	}
+
+	current := data[idx]
+	isSwingHigh := current.High > data[idx-1].High && current.High >= data[idx+1].High
+	quiet := relativeVolume(data, idx, 20) < 0.9 && current.High-current.Low <= calculateAverageRange(data, idx, 10)
+	lowerHigh := current.High < tr.Max && current.High < data[ctxIdx].High
+
+	if isSwingHigh && quiet && lowerHigh {
+		return &models.WyckoffEvent{
+			Name:       "Last Point of Supply",
+			Type:       "distribution",
+			Date:       current.Date,
+			Price:      current.Close,
+			Volume:     current.Volume,
+			Confidence: 0.75,
+		}
+	}
+
+	return nil
+}
+
+// ============================================================================
+// CONTEXT HELPERS
+// ============================================================================
+
+// lastEventIndexOf returns the bar index of the most recent event with any of the names, or -1
+func lastEventIndexOf(data []models.StockData, events []models.WyckoffEvent, names ...string) int {
+	for i := len(events) - 1; i >= 0; i-- {
+		for _, name := range names {
+			if events[i].Name == name {
+				return barIndexForDate(data, events[i].Date)
+			}
+		}
+	}
+	return -1
+}
+
+// barShape returns where a bar closed within its range (0 = low, 1 = high)
+// and its range relative to the previous 10 bars
+func barShape(data []models.StockData, idx int) (closePosition, rangeRatio float64) {
+	current := data[idx]
+	priceRange := current.High - current.Low
+	if priceRange == 0 {
+		return 0.5, 0
+	}
+	return (current.Close - current.Low) / priceRange, priceRange / calculateAverageRange(data, idx, 10)
+}
+
+// secondaryTestConfidence rewards tests that come on much less volume than the climax
+func secondaryTestConfidence(testVolume, climaxVolume int64) float64 {
+	if climaxVolume > 0 && float64(testVolume) < float64(climaxVolume)*0.5 {
+		return 0.8
+	}
+	return 0.65
+}
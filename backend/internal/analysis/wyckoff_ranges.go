@@ -0,0 +1,242 @@
+package analysis
+
+import (
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// HISTORICAL TRADING RANGES
+// ============================================================================
+
+const (
+	minRangeBars         = 20  // Shortest consolidation treated as a trading range
+	maxRangeHeightATR    = 8.0 // Tallest consolidation, in ATRs
+	maxRangeEfficiency   = 0.3 // Kaufman efficiency ratio above which the window is trending
+	breakoutBufferATR    = 0.5 // Close beyond the range by this many ATRs to count as outside
+	breakoutConfirmBars  = 2   // Consecutive outside closes that end the range
+	breakoutFailBars     = 10  // A close back inside within this many bars fails the breakout
+	breakoutTargetBars   = 40  // Bars allowed to reach the measured-move target
+	priorTrendLookback   = 20  // Bars before the range used to judge the prior trend
+	priorTrendMinPercent = 5.0 // Minimum move for the prior trend to count as up/down
+)
+
+// DetectHistoricalRanges segments the full history into trading ranges and the
+// trends between them. Each range records its creek (resistance) and ice
+// (support), how it was left and what happened after the breakout
+func DetectHistoricalRanges(data []models.StockData) ([]models.HistoricalRange, []models.TrendSegment) {
+	ranges := []models.HistoricalRange{}
+	trends := []models.TrendSegment{}
+
+	if len(data) < minRangeBars+priorTrendLookback {
+		return ranges, trends
+	}
+
+	trendStart := 0
+	i := 0
+	for i+minRangeBars <= len(data) {
+		end := i + minRangeBars - 1
+		atr := rangeATR(data, end)
+		high := maxHigh(data[i : end+1])
+		low := minLow(data[i : end+1])
+
+		if atr <= 0 || (high-low)/atr > maxRangeHeightATR || efficiencyRatio(data[i:end+1]) > maxRangeEfficiency {
+			i++
+			continue
+		}
+
+		// Extend the range until price closes outside it for breakoutConfirmBars bars
+		buffer := breakoutBufferATR * atr
+		outside, direction := 0, ""
+		j := end + 1
+		for ; j < len(data); j++ {
+			price := data[j].Close
+			switch {
+			case price > high+buffer:
+				if direction != "up" {
+					outside = 0
+				}
+				direction = "up"
+				outside++
+			case price < low-buffer:
+				if direction != "down" {
+					outside = 0
+				}
+				direction = "down"
+				outside++
+			default:
+				outside, direction = 0, ""
+			}
+			if outside >= breakoutConfirmBars {
+				break
+			}
+		}
+
+		rangeEnd := j - outside
+		if j >= len(data) {
+			rangeEnd = len(data) - 1
+			direction = ""
+		}
+
+		if i-1 > trendStart {
+			trends = append(trends, trendSegment(data, trendStart, i-1))
+		}
+
+		r := buildHistoricalRange(data, i, rangeEnd, direction)
+		ranges = append(ranges, r)
+
+		if direction == "" {
+			trendStart = len(data)
+			break
+		}
+		trendStart = rangeEnd + 1
+		i = rangeEnd + 1
+	}
+
+	if trendStart < len(data)-1 {
+		trends = append(trends, trendSegment(data, trendStart, len(data)-1))
+	}
+
+	return ranges, trends
+}
+
+// buildHistoricalRange describes the range data[start:end+1] and, when it was
+// left in direction ("up"/"down"), the breakout and its outcome
+func buildHistoricalRange(data []models.StockData, start, end int, direction string) models.HistoricalRange {
+	bars := data[start : end+1]
+	boundaries := rangeBoundaries(bars)
+
+	r := models.HistoricalRange{
+		StartDate:  data[start].Date,
+		EndDate:    data[end].Date,
+		Bars:       end - start + 1,
+		Creek:      boundaries.Max,
+		Ice:        boundaries.Min,
+		High:       maxHigh(bars),
+		Low:        minLow(bars),
+		PriorTrend: priorTrend(data, start),
+		Breakout:   "none",
+		Outcome:    "active",
+		Structure:  "undetermined",
+		Active:     direction == "",
+	}
+
+	if direction == "" {
+		return r
+	}
+
+	breakoutIdx := end + 1
+	breakoutDate := data[breakoutIdx].Date
+	r.Breakout = direction
+	r.BreakoutDate = &breakoutDate
+	r.BreakoutPrice = data[breakoutIdx].Close
+	r.Outcome = breakoutOutcome(data, breakoutIdx, r)
+
+	switch {
+	case direction == "up" && r.PriorTrend == "up":
+		r.Structure = "reaccumulation"
+	case direction == "up":
+		r.Structure = "accumulation"
+	case direction == "down" && r.PriorTrend == "down":
+		r.Structure = "redistribution"
+	default:
+		r.Structure = "distribution"
+	}
+
+	return r
+}
+
+// breakoutOutcome follows price after a breakout: "failed" when it closes back
+// inside the range soon after, "target_reached" when it covers the range height
+// (measured move), "follow_through" when it held without reaching the target,
+// and "pending" when there are not yet enough bars to tell
+func breakoutOutcome(data []models.StockData, breakoutIdx int, r models.HistoricalRange) string {
+	height := r.Creek - r.Ice
+	up := r.Breakout == "up"
+	target := r.Ice - height
+	if up {
+		target = r.Creek + height
+	}
+
+	for k := breakoutIdx; k < len(data) && k-breakoutIdx <= breakoutTargetBars; k++ {
+		bar := data[k]
+		if k-breakoutIdx <= breakoutFailBars {
+			if (up && bar.Close < r.Creek) || (!up && bar.Close > r.Ice) {
+				return "failed"
+			}
+		}
+		if (up && bar.High >= target) || (!up && bar.Low <= target) {
+			return "target_reached"
+		}
+	}
+
+	if len(data)-1-breakoutIdx < breakoutTargetBars {
+		return "pending"
+	}
+	return "follow_through"
+}
+
+// trendSegment describes the move between two ranges
+func trendSegment(data []models.StockData, start, end int) models.TrendSegment {
+	startPrice := data[start].Close
+	endPrice := data[end].Close
+	change := 0.0
+	if startPrice > 0 {
+		change = (endPrice - startPrice) / startPrice * 100
+	}
+
+	direction := "sideways"
+	if change >= priorTrendMinPercent {
+		direction = "up"
+	} else if change <= -priorTrendMinPercent {
+		direction = "down"
+	}
+
+	return models.TrendSegment{
+		StartDate: data[start].Date,
+		EndDate:   data[end].Date,
+		Bars:      end - start + 1,
+		Direction: direction,
+		Change:    change,
+	}
+}
+
+// priorTrend classifies the move into a range over the preceding bars
+func priorTrend(data []models.StockData, start int) string {
+	from := max(0, start-priorTrendLookback)
+	if from == start || data[from].Close == 0 {
+		return "none"
+	}
+	change := (data[start].Close - data[from].Close) / data[from].Close * 100
+	switch {
+	case change >= priorTrendMinPercent:
+		return "up"
+	case change <= -priorTrendMinPercent:
+		return "down"
+	}
+	return "none"
+}
+
+// rangeATR returns the ATR at idx, falling back to the average bar range early in the data
+func rangeATR(data []models.StockData, idx int) float64 {
+	if atr := CalculateATR(data[:idx+1], 14); atr > 0 {
+		return atr
+	}
+	return calculateAverageRange(data, idx+1, idx+1)
+}
+
+// efficiencyRatio is the net close-to-close move divided by the total path length
+// (Kaufman). Values near 0 mean chop, near 1 mean a straight trend
+func efficiencyRatio(bars []models.StockData) float64 {
+	if len(bars) < 2 {
+		return 0
+	}
+	path := 0.0
+	for k := 1; k < len(bars); k++ {
+		path += math.Abs(bars[k].Close - bars[k-1].Close)
+	}
+	if path == 0 {
+		return 0
+	}
+	return math.Abs(bars[len(bars)-1].Close-bars[0].Close) / path
+}
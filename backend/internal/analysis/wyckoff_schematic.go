@@ -0,0 +1,211 @@
+package analysis
+
+import (
+	"slices"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// WYCKOFF SCHEMATIC PHASES (A-E)
+// ============================================================================
+
+// schematicBreakoutBars is how many consecutive closes outside the range after
+// Phase D confirm the trend has left the range (Phase E)
+const schematicBreakoutBars = 5
+
+// schematicMaxIdleBars drops a structure that has not advanced a phase for this long
+const schematicMaxIdleBars = 120
+
+// schematicState tracks the structure currently being walked through
+type schematicState struct {
+	side      string // "accumulation" or "distribution"
+	schematic string // side, or "reaccumulation"/"redistribution"
+	phase     string // "A" through "E"
+	rangeLow  float64
+	rangeHigh float64
+	outside   int // Consecutive closes beyond the range in the breakout direction being watched
+	since     int // Bar index the current phase started on
+}
+
+// Events that start a new structure on each side
+var (
+	accumulationStartEvents = []string{"Preliminary Support", "Selling Climax"}
+	distributionStartEvents = []string{"Preliminary Supply", "Buying Climax"}
+)
+
+// accumulationEventPhases maps events to the accumulation phase they begin
+var accumulationEventPhases = map[string]string{
+	"Preliminary Support":   "A",
+	"Selling Climax":        "A",
+	"Automatic Rally":       "A",
+	"Secondary Test":        "B",
+	"Spring":                "C",
+	"Test of Spring":        "C",
+	"Sign of Strength":      "D",
+	"Last Point of Support": "D",
+	"Jump Across the Creek": "D",
+	"Back-Up":               "E",
+}
+
+// distributionEventPhases maps events to the distribution phase they begin
+var distributionEventPhases = map[string]string{
+	"Preliminary Supply":          "A",
+	"Buying Climax":               "A",
+	"Automatic Reaction":          "A",
+	"Secondary Test":              "B",
+	"Upthrust After Distribution": "C",
+	"Sign of Weakness":            "D",
+	"Last Point of Supply":        "D",
+}
+
+// buildPhaseHistory walks the bars and events chronologically and assigns
+// Wyckoff schematic phases A-E. Phases only move forward within a structure.
+// A start event after Phase D closes the current structure and opens a new one,
+// and a structure that breaks out the wrong way before Phase D is dropped as failed.
+// An accumulation that follows a completed accumulation (i.e. during markup) is
+// reported as reaccumulation, and likewise for redistribution
+func buildPhaseHistory(data []models.StockData, events []models.WyckoffEvent) []models.WyckoffPhasePeriod {
+	history := []models.WyckoffPhasePeriod{}
+	if len(data) == 0 {
+		return history
+	}
+
+	eventsByBar := map[int][]models.WyckoffEvent{}
+	for _, event := range events {
+		if idx := barIndexForDate(data, event.Date); idx >= 0 {
+			eventsByBar[idx] = append(eventsByBar[idx], event)
+		}
+	}
+
+	var state *schematicState
+	lastSide, lastPhase := "", ""
+
+	enter := func(idx int, phase, trigger string) {
+		if len(history) > 0 && history[len(history)-1].Current {
+			history[len(history)-1].EndDate = data[idx].Date
+			history[len(history)-1].Current = false
+		}
+		state.phase = phase
+		state.since = idx
+		history = append(history, models.WyckoffPhasePeriod{
+			Schematic: state.schematic,
+			Phase:     phase,
+			StartDate: data[idx].Date,
+			EndDate:   data[len(data)-1].Date,
+			Trigger:   trigger,
+			Current:   true,
+		})
+	}
+
+	for i, bar := range data {
+		for _, event := range eventsByBar[i] {
+			side := ""
+			switch {
+			case slices.Contains(accumulationStartEvents, event.Name):
+				side = "accumulation"
+			case slices.Contains(distributionStartEvents, event.Name):
+				side = "distribution"
+			}
+
+			// A start event opens a new structure when none is active, or the active one
+			// has reached Phase D/E. Inside a developing range (A-C) it is just noise
+			if side != "" && (state == nil || state.phase >= "D") {
+				if state != nil {
+					lastSide, lastPhase = state.side, state.phase
+				}
+				schematic := side
+				if lastSide == side && lastPhase >= "D" {
+					schematic = "re" + side
+				}
+				state = &schematicState{
+					side:      side,
+					schematic: schematic,
+					rangeLow:  bar.Low,
+					rangeHigh: bar.High,
+				}
+				enter(i, "A", event.Name)
+				continue
+			}
+
+			if state == nil || (event.Type != "" && event.Type != state.side) {
+				continue
+			}
+
+			phases := accumulationEventPhases
+			if state.side == "distribution" {
+				phases = distributionEventPhases
+			}
+			next, ok := phases[event.Name]
+			if !ok || next <= state.phase {
+				continue
+			}
+
+			// Phase A ends with the Secondary Test, and the markup/markdown signs of
+			// Phase D only count once the range has had a Phase B or C to build a cause
+			if (next == "B" && state.phase != "A") || (next == "D" && state.phase < "B") {
+				continue
+			}
+			enter(i, next, event.Name)
+		}
+
+		if state == nil {
+			continue
+		}
+
+		// A structure that stops developing is no longer a structure
+		if state.phase != "E" && i-state.since > schematicMaxIdleBars {
+			history[len(history)-1].EndDate = bar.Date
+			history[len(history)-1].Current = false
+			state = nil
+			lastSide, lastPhase = "", ""
+			continue
+		}
+
+		// Phase A defines the range the rest of the structure is measured against
+		if state.phase == "A" {
+			state.rangeLow = min(state.rangeLow, bar.Low)
+			state.rangeHigh = max(state.rangeHigh, bar.High)
+			continue
+		}
+
+		// Before Phase D, a sustained break the wrong way out of the range means the
+		// structure failed; in Phase D, a sustained break the right way starts Phase E
+		if state.phase == "E" {
+			continue
+		}
+		above, below := bar.Close > state.rangeHigh, bar.Close < state.rangeLow
+		leaving := (state.side == "accumulation" && below) || (state.side == "distribution" && above)
+		if state.phase == "D" {
+			leaving = (state.side == "accumulation" && above) || (state.side == "distribution" && below)
+		}
+		if leaving {
+			state.outside++
+		} else {
+			state.outside = 0
+		}
+		if state.outside < schematicBreakoutBars {
+			continue
+		}
+
+		if state.phase == "D" {
+			enter(i, "E", "Range Breakout")
+			continue
+		}
+
+		history[len(history)-1].EndDate = bar.Date
+		history[len(history)-1].Current = false
+		state = nil
+		lastSide, lastPhase = "", ""
+	}
+
+	return history
+}
+
+// currentSchematicPhase returns the structure and phase of the active history period
+func currentSchematicPhase(history []models.WyckoffPhasePeriod) (string, string) {
+	if len(history) == 0 || !history[len(history)-1].Current {
+		return "", ""
+	}
+	last := history[len(history)-1]
+	return last.Schematic, last.Phase
+}
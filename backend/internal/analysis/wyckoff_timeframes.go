@@ -0,0 +1,96 @@
+package analysis
+
+import (
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// MULTI-TIMEFRAME WYCKOFF ANALYSIS
+// ============================================================================
+
+// HigherTimeframeHistoryDays is how many calendar days of daily history to fetch
+// so the monthly series has enough bars for a Wyckoff analysis
+const HigherTimeframeHistoryDays = 3 * 365
+
+// AnalyzeWyckoffTimeframes runs the Wyckoff pipeline on the weekly and monthly
+// candles aggregated from the daily history
+func AnalyzeWyckoffTimeframes(history []models.StockData, opts WyckoffOptions) []models.WyckoffTimeframe {
+	return []models.WyckoffTimeframe{
+		analyzeWyckoffTimeframe("weekly", aggregateToWeeklyCandles(history), opts),
+		analyzeWyckoffTimeframe("monthly", aggregateToMonthlyCandles(history), opts),
+	}
+}
+
+// analyzeWyckoffTimeframe summarizes the Wyckoff analysis of one aggregated series
+func analyzeWyckoffTimeframe(timeframe string, bars []models.StockData, opts WyckoffOptions) models.WyckoffTimeframe {
+	wyckoff := analyzeWyckoff(bars, nil, nil, opts)
+
+	return models.WyckoffTimeframe{
+		Timeframe:           timeframe,
+		Bars:                len(bars),
+		Phase:               wyckoff.Phase,
+		PhaseConfidence:     wyckoff.PhaseConfidence,
+		Schematic:           wyckoff.Schematic,
+		SchematicPhase:      wyckoff.SchematicPhase,
+		TradingRange:        wyckoff.TradingRange,
+		Events:              wyckoff.Events,
+		Recommendation:      wyckoff.Recommendation,
+		RecommendationScore: wyckoff.RecommendationScore,
+		Bias:                phaseBias(wyckoff.Phase),
+	}
+}
+
+// alignTimeframes compares the daily phase with the higher timeframes. The score
+// rewards higher timeframes agreeing with the daily bias and penalizes those
+// opposing it, weighted by their phase confidence, and points in the daily
+// bias's direction: agreement with a bullish day favors buying, agreement with
+// a bearish day favors selling. A neutral day scores zero. Returns nil when no
+// higher timeframe had enough bars to determine a phase
+func alignTimeframes(dailyPhase string, timeframes []models.WyckoffTimeframe) *models.TimeframeAlignment {
+	alignment := &models.TimeframeAlignment{DailyBias: phaseBias(dailyPhase)}
+
+	analyzed := 0
+	agreement := 0.0
+	for _, tf := range timeframes {
+		if tf.Phase == "insufficient_data" {
+			continue
+		}
+		analyzed++
+
+		// Phase confidence can exceed 1 when several signals stack up
+		confidence := min(tf.PhaseConfidence, 1)
+		switch {
+		case tf.Bias == "neutral" || alignment.DailyBias == "neutral":
+		case tf.Bias == alignment.DailyBias:
+			alignment.Agreeing++
+			agreement += confidence
+		default:
+			alignment.Conflicting++
+			agreement -= confidence
+		}
+	}
+
+	if analyzed == 0 {
+		return nil
+	}
+
+	switch alignment.DailyBias {
+	case "bullish":
+		alignment.Score = agreement / float64(analyzed)
+	case "bearish":
+		alignment.Score = -agreement / float64(analyzed)
+	}
+	alignment.Aligned = alignment.DailyBias != "neutral" && alignment.Agreeing == analyzed
+	return alignment
+}
+
+// phaseBias maps a Wyckoff phase to the direction it favors
+func phaseBias(phase string) string {
+	switch phase {
+	case "accumulation", "markup":
+		return "bullish"
+	case "distribution", "markdown":
+		return "bearish"
+	}
+	return "neutral"
+}
@@ -2,29 +2,108 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"log"
 	"net/http"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"stocking-chain/internal/analysis"
+	"stocking-chain/internal/backtest"
+	"stocking-chain/internal/models"
+	"stocking-chain/internal/rules"
 	"stocking-chain/pkg/ssi"
 )
 
 type Handler struct {
-	ssiClient *ssi.Client
-	analyzer  *analysis.Analyzer
+	ssiClient     *ssi.Client
+	analyzer      *analysis.Analyzer
+	scoringModels map[string]analysis.ScoringModel
 }
 
 func NewHandler(ssiClient *ssi.Client, analyzer *analysis.Analyzer) *Handler {
-	return &Handler{
-		ssiClient: ssiClient,
-		analyzer:  analyzer,
+	h := &Handler{
+		ssiClient:     ssiClient,
+		analyzer:      analyzer,
+		scoringModels: map[string]analysis.ScoringModel{},
+	}
+	h.RegisterScoringModels(analysis.BuiltinScoringModels())
+	h.RegisterScoringModels([]analysis.ScoringModel{analyzer.Config().ScoringModel})
+	return h
+}
+
+// RegisterScoringModels makes models selectable by name in analyze requests,
+// replacing any registered model of the same name. Call it before serving
+func (h *Handler) RegisterScoringModels(models []analysis.ScoringModel) {
+	for _, model := range models {
+		h.scoringModels[model.Name] = model
 	}
 }
 
 type AnalyzeRequest struct {
-	Symbol     string `json:"symbol"`
-	DaysBack   int    `json:"days_back,omitempty"`
+	Symbol       string               `json:"symbol"`
+	DaysBack     int                  `json:"days_back,omitempty"`
+	Transform    *models.BarTransform `json:"transform,omitempty"`     // Analyze transformed bars instead of daily bars
+	IndexSymbol  string               `json:"index_symbol,omitempty"`  // Market benchmark (defaults to the VN-Index)
+	SectorSymbol string               `json:"sector_symbol,omitempty"` // Optional sector index for relative strength
+	AsOf         string               `json:"as_of,omitempty"`         // YYYY-MM-DD: analyze only what was known at that day's close
+
+	ScoringModel  string                  `json:"scoring_model,omitempty"`  // Registered or inline model that makes the recommendation
+	CompareModels []string                `json:"compare_models,omitempty"` // Models to score side by side; "all" adds every registered model
+	Models        []analysis.ScoringModel `json:"models,omitempty"`         // Inline models, usable by name in this request
+
+	PatternVolume *bool `json:"pattern_volume,omitempty"` // Adjust candlestick pattern confidence for volume; omitted keeps the server setting
+	PatternTrend  *bool `json:"pattern_trend,omitempty"`  // Adjust candlestick pattern confidence for the preceding trend
+
+	Signals   []rules.Signal            `json:"signals,omitempty"`    // Custom rules checked against the analyzed bars
+	TradePlan *analysis.TradePlanConfig `json:"trade_plan,omitempty"` // Size a trade for this account; zero fields take the defaults
+	Forecast  *analysis.ForecastConfig  `json:"forecast,omitempty"`   // Forecast price bands; zero fields take the defaults
+}
+
+type ScoringModelsResponse struct {
+	Default string                  `json:"default"`
+	Models  []analysis.ScoringModel `json:"models"`
+	Signals []string                `json:"signals"`
+}
+
+type TransformRequest struct {
+	Symbol    string              `json:"symbol"`
+	DaysBack  int                 `json:"days_back,omitempty"`
+	Transform models.BarTransform `json:"transform"`
+}
+
+type WyckoffBacktestRequest struct {
+	Symbol   string                         `json:"symbol"`
+	DaysBack int                            `json:"days_back,omitempty"`
+	Config   analysis.WyckoffBacktestConfig `json:"config"` // Omitted fields keep their default values
+}
+
+type BacktestRequest struct {
+	Symbol      string                     `json:"symbol"`
+	DaysBack    int                        `json:"days_back,omitempty"`
+	Window      int                        `json:"window,omitempty"`       // Bars each point-in-time analysis sees (default 200)
+	IndexSymbol string                     `json:"index_symbol,omitempty"` // Benchmark index (defaults to the VN-Index)
+	Config      backtest.Config            `json:"config"`                 // Omitted fields keep their default values
+	MonteCarlo  *backtest.MonteCarloConfig `json:"monte_carlo,omitempty"`  // Resample the strategy's results when set
+	EntryRule   string                     `json:"entry_rule,omitempty"`   // Trade this rule instead of the recommendations
+	ExitRule    string                     `json:"exit_rule,omitempty"`    // Defaults to the entry rule no longer holding
+}
+
+type ScreenRequest struct {
+	Symbols  []string `json:"symbols"`
+	Rule     string   `json:"rule"`
+	DaysBack int      `json:"days_back,omitempty"`
+}
+
+type OptimizeRequest struct {
+	Symbol   string                  `json:"symbol"`
+	DaysBack int                     `json:"days_back,omitempty"`
+	Window   int                     `json:"window,omitempty"` // Bars each point-in-time analysis sees (default 200)
+	Signal   string                  `json:"signal,omitempty"` // "recommendation" (default) or "wyckoff"
+	Config   backtest.Config         `json:"config"`           // Omitted fields keep their default values
+	Optimize backtest.OptimizeConfig `json:"optimize"`         // Omitted fields keep their default values
 }
 
 type ErrorResponse struct {
@@ -52,31 +131,118 @@ func (h *Handler) AnalyzeStock(w http.ResponseWriter, r *http.Request) {
 		req.DaysBack = 200
 	}
 
+	if _, err := rules.ParseSignals(req.Signals); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid signal: "+err.Error())
+		return
+	}
+
+	if req.TradePlan != nil {
+		if err := req.TradePlan.Validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid trade plan: "+err.Error())
+			return
+		}
+	}
+
+	if req.Forecast != nil {
+		if err := req.Forecast.Validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid forecast: "+err.Error())
+			return
+		}
+	}
+
 	toDate := time.Now()
+	var asOf *time.Time
+	if req.AsOf != "" {
+		parsed, err := time.Parse("2006-01-02", req.AsOf)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "as_of must be a date in YYYY-MM-DD format")
+			return
+		}
+		asOf = &parsed
+		toDate = parsed.AddDate(0, 0, 1)
+	}
 	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
 
-	log.Printf("Fetching data for %s from %s to %s", req.Symbol, fromDate.Format("2006-01-02"), toDate.Format("2006-01-02"))
+	// Weekly and monthly Wyckoff analysis needs a longer history than the daily view
+	historyFrom := fromDate
+	if req.DaysBack < analysis.HigherTimeframeHistoryDays {
+		historyFrom = toDate.AddDate(0, 0, -analysis.HigherTimeframeHistoryDays)
+	}
 
-	stockData, err := h.ssiClient.GetHistoricalData(req.Symbol, fromDate, toDate)
+	log.Printf("Fetching data for %s from %s to %s", req.Symbol, historyFrom.Format("2006-01-02"), toDate.Format("2006-01-02"))
+
+	history, err := h.ssiClient.GetHistoricalData(req.Symbol, historyFrom, toDate)
 	if err != nil {
 		log.Printf("Error fetching stock data: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to fetch stock data: "+err.Error())
 		return
 	}
 
+	stockData := barsSince(history, fromDate)
 	if len(stockData) == 0 {
 		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol)
 		return
 	}
 
+	if req.Transform != nil {
+		series, err := analysis.ApplyTransform(stockData, *req.Transform)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Failed to transform bars: "+err.Error())
+			return
+		}
+		if len(series.Bars) == 0 {
+			respondWithError(w, http.StatusUnprocessableEntity, "Transform produced no bars; try a smaller box size or more days")
+			return
+		}
+		log.Printf("Transformed %d daily bars into %d %s bars", len(stockData), len(series.Bars), series.Transform.Type)
+		stockData = series.Bars
+	}
+
+	analyzer, err := h.analyzerFor(req)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	market := h.fetchMarketContext(req.IndexSymbol, req.SectorSymbol, fromDate, toDate)
+	market.History = history
+
 	log.Printf("Analyzing %d data points for %s", len(stockData), req.Symbol)
 
-	report, err := h.analyzer.Analyze(req.Symbol, stockData)
+	var report *models.AnalysisReport
+	if asOf != nil {
+		report, err = analyzer.AnalyzeAsOf(req.Symbol, stockData, market, *asOf)
+	} else {
+		report, err = analyzer.AnalyzeWithMarket(req.Symbol, stockData, market)
+	}
 	if err != nil {
 		log.Printf("Error analyzing stock: %v", err)
 		respondWithError(w, http.StatusInternalServerError, "Failed to analyze stock")
 		return
 	}
+	if report == nil {
+		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol+" as of "+req.AsOf)
+		return
+	}
+	report.Transform = req.Transform
+
+	if len(req.Signals) > 0 {
+		report.CustomSignals, err = rules.EvaluateSignals(req.Signals, barsThrough(stockData, report.Date))
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid signal: "+err.Error())
+			return
+		}
+	}
+
+	if req.TradePlan != nil {
+		plan := analysis.BuildTradePlan(report, *req.TradePlan)
+		report.TradePlan = &plan
+	}
+
+	if req.Forecast != nil {
+		forecast := analysis.ForecastPrices(report, *req.Forecast)
+		report.Forecast = &forecast
+	}
 
 	// Fetch company info (non-blocking - continue even if it fails)
 	stockInfo, err := h.ssiClient.GetStockInfo(req.Symbol)
@@ -99,6 +265,517 @@ func (h *Handler) AnalyzeStock(w http.ResponseWriter, r *http.Request) {
 	respondWithJSON(w, http.StatusOK, report)
 }
 
+// analyzerFor returns the Analyzer with the scoring models and pattern context
+// the request selects
+func (h *Handler) analyzerFor(req AnalyzeRequest) (*analysis.Analyzer, error) {
+	if req.ScoringModel == "" && len(req.CompareModels) == 0 && req.PatternVolume == nil && req.PatternTrend == nil {
+		return h.analyzer, nil
+	}
+
+	available := map[string]analysis.ScoringModel{}
+	for name, model := range h.scoringModels {
+		available[name] = model
+	}
+	for _, model := range req.Models {
+		if err := model.Validate(); err != nil {
+			return nil, err
+		}
+		available[model.Name] = model
+	}
+	lookup := func(name string) (analysis.ScoringModel, error) {
+		model, ok := available[name]
+		if !ok {
+			return model, fmt.Errorf("unknown scoring model %q", name)
+		}
+		return model, nil
+	}
+
+	config := h.analyzer.Config()
+	if req.PatternVolume != nil {
+		config.PatternContext.UseVolume = *req.PatternVolume
+	}
+	if req.PatternTrend != nil {
+		config.PatternContext.UseTrend = *req.PatternTrend
+	}
+	if req.ScoringModel != "" {
+		model, err := lookup(req.ScoringModel)
+		if err != nil {
+			return nil, err
+		}
+		config.ScoringModel = model
+	}
+
+	all := make([]string, 0, len(available))
+	for name := range available {
+		all = append(all, name)
+	}
+	sort.Strings(all)
+
+	names := []string{}
+	for _, name := range req.CompareModels {
+		if name == "all" {
+			names = append(names, all...)
+		} else {
+			names = append(names, name)
+		}
+	}
+	config.CompareModels = nil
+	seen := map[string]bool{config.ScoringModel.Name: true}
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		model, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		config.CompareModels = append(config.CompareModels, model)
+	}
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return analysis.NewAnalyzerWithConfig(config), nil
+}
+
+// barsSince returns the bars dated on or after from
+func barsSince(data []models.StockData, from time.Time) []models.StockData {
+	for i, bar := range data {
+		if !bar.Date.Before(from) {
+			return data[i:]
+		}
+	}
+	return []models.StockData{}
+}
+
+// barsThrough returns the bars dated on or before to
+func barsThrough(data []models.StockData, to time.Time) []models.StockData {
+	for i := len(data) - 1; i >= 0; i-- {
+		if !data[i].Date.After(to) {
+			return data[:i+1]
+		}
+	}
+	return []models.StockData{}
+}
+
+// fetchMarketContext loads the index (and optional sector) series for relative
+// strength. Failures are logged and skipped so the analysis can still run
+func (h *Handler) fetchMarketContext(indexSymbol, sectorSymbol string, fromDate, toDate time.Time) *analysis.MarketContext {
+	if indexSymbol == "" {
+		indexSymbol = ssi.VN_INDEX_SYMBOL
+	}
+
+	market := &analysis.MarketContext{IndexSymbol: indexSymbol, SectorSymbol: sectorSymbol}
+
+	index, err := h.ssiClient.GetHistoricalData(indexSymbol, fromDate, toDate)
+	if err != nil {
+		log.Printf("Warning: Could not fetch index %s: %v", indexSymbol, err)
+	} else {
+		market.Index = index
+	}
+
+	if sectorSymbol != "" {
+		sector, err := h.ssiClient.GetHistoricalData(sectorSymbol, fromDate, toDate)
+		if err != nil {
+			log.Printf("Warning: Could not fetch sector index %s: %v", sectorSymbol, err)
+		} else {
+			market.Sector = sector
+		}
+	}
+
+	return market
+}
+
+func (h *Handler) TransformBars(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req TransformRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Symbol == "" {
+		respondWithError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+
+	if req.DaysBack == 0 {
+		req.DaysBack = 200
+	}
+
+	toDate := time.Now()
+	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
+
+	stockData, err := h.ssiClient.GetHistoricalData(req.Symbol, fromDate, toDate)
+	if err != nil {
+		log.Printf("Error fetching stock data: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch stock data: "+err.Error())
+		return
+	}
+
+	if len(stockData) == 0 {
+		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol)
+		return
+	}
+
+	series, err := analysis.ApplyTransform(stockData, req.Transform)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to transform bars: "+err.Error())
+		return
+	}
+	series.Symbol = req.Symbol
+
+	respondWithJSON(w, http.StatusOK, series)
+}
+
+// maxWyckoffBacktestDays caps the history of a Wyckoff backtest at ten years,
+// since every bar re-analyzes a full window
+const maxWyckoffBacktestDays = 3650
+
+// BacktestWyckoff replays the Wyckoff event detectors bar by bar over the
+// requested history and reports the results of each entry trigger
+func (h *Handler) BacktestWyckoff(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := WyckoffBacktestRequest{Config: analysis.DefaultWyckoffBacktestConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Symbol == "" {
+		respondWithError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+
+	if req.DaysBack == 0 {
+		req.DaysBack = 730
+	}
+	if req.DaysBack < 0 || req.DaysBack > maxWyckoffBacktestDays {
+		respondWithError(w, http.StatusBadRequest, fmt.Sprintf("days_back must be between 1 and %d", maxWyckoffBacktestDays))
+		return
+	}
+	if err := req.Config.Validate(); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid config: "+err.Error())
+		return
+	}
+
+	toDate := time.Now()
+	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
+
+	stockData, err := h.ssiClient.GetHistoricalData(req.Symbol, fromDate, toDate)
+	if err != nil {
+		log.Printf("Error fetching stock data: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch stock data: "+err.Error())
+		return
+	}
+
+	if len(stockData) == 0 {
+		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol)
+		return
+	}
+
+	log.Printf("Backtesting Wyckoff entries on %d bars for %s", len(stockData), req.Symbol)
+
+	result := analysis.BacktestWyckoff(stockData, req.Config)
+	result.Symbol = req.Symbol
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// Backtest replays the Analyzer's recommendations, or the entry and exit rules
+// when given, over the requested history and compares the result with
+// buy-and-hold and the market index
+func (h *Handler) Backtest(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := BacktestRequest{Config: backtest.DefaultConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Symbol == "" {
+		respondWithError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+
+	if req.DaysBack == 0 {
+		req.DaysBack = 730
+	}
+
+	if req.MonteCarlo != nil {
+		if err := req.MonteCarlo.Validate(); err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid monte_carlo: "+err.Error())
+			return
+		}
+	}
+
+	var entryRule, exitRule *rules.Rule
+	if req.EntryRule != "" {
+		rule, err := rules.Parse(req.EntryRule)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid entry rule: "+err.Error())
+			return
+		}
+		entryRule = rule
+	}
+	if req.ExitRule != "" {
+		if entryRule == nil {
+			respondWithError(w, http.StatusBadRequest, "exit_rule needs an entry_rule")
+			return
+		}
+		rule, err := rules.Parse(req.ExitRule)
+		if err != nil {
+			respondWithError(w, http.StatusBadRequest, "Invalid exit rule: "+err.Error())
+			return
+		}
+		exitRule = rule
+	}
+
+	toDate := time.Now()
+	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
+
+	stockData, err := h.ssiClient.GetHistoricalData(req.Symbol, fromDate, toDate)
+	if err != nil {
+		log.Printf("Error fetching stock data: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch stock data: "+err.Error())
+		return
+	}
+
+	if len(stockData) == 0 {
+		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol)
+		return
+	}
+
+	market := h.fetchMarketContext(req.IndexSymbol, "", fromDate, toDate)
+	market.History = stockData
+
+	var strategy backtest.Strategy
+	if entryRule != nil {
+		strategy = backtest.NewRuleStrategy(entryRule, exitRule)
+		log.Printf("Backtesting rule %q on %d bars for %s", entryRule.Source, len(stockData), req.Symbol)
+	} else {
+		analyzerStrategy := backtest.NewAnalyzerStrategy(h.analyzer, req.Symbol, market)
+		if req.Window > 0 {
+			analyzerStrategy.Window = req.Window
+		}
+		strategy = analyzerStrategy
+		log.Printf("Backtesting analyzer recommendations on %d bars for %s", len(stockData), req.Symbol)
+	}
+
+	result := backtest.Run(stockData, strategy, req.Config)
+	buyAndHold := backtest.Run(stockData, backtest.BuyAndHold{}, req.Config)
+	comparison := models.BacktestComparison{
+		Symbol:             req.Symbol,
+		Strategy:           result,
+		BuyAndHold:         buyAndHold,
+		ExcessVsBuyAndHold: result.Metrics.TotalReturn - buyAndHold.Metrics.TotalReturn,
+	}
+	comparison.Strategy.Symbol = req.Symbol
+	comparison.BuyAndHold.Symbol = req.Symbol
+
+	if len(market.Index) > 0 {
+		index := backtest.Benchmark("index", market.Index, req.Config)
+		index.Symbol = market.IndexSymbol
+		comparison.Index = &index
+		comparison.ExcessVsIndex = result.Metrics.TotalReturn - index.Metrics.TotalReturn
+	}
+
+	if req.MonteCarlo != nil {
+		monteCarlo := backtest.MonteCarlo(comparison.Strategy, req.Config, *req.MonteCarlo)
+		comparison.MonteCarlo = &monteCarlo
+	}
+
+	respondWithJSON(w, http.StatusOK, comparison)
+}
+
+// Optimize searches the Analyzer's parameters with walk-forward validation and
+// reports the out-of-sample performance of the parameters chosen in each fold
+func (h *Handler) Optimize(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	req := OptimizeRequest{Config: backtest.DefaultConfig(), Optimize: backtest.DefaultOptimizeConfig()}
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if req.Symbol == "" {
+		respondWithError(w, http.StatusBadRequest, "Symbol is required")
+		return
+	}
+
+	if req.DaysBack == 0 {
+		req.DaysBack = 1095
+	}
+
+	toDate := time.Now()
+	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
+
+	stockData, err := h.ssiClient.GetHistoricalData(req.Symbol, fromDate, toDate)
+	if err != nil {
+		log.Printf("Error fetching stock data: %v", err)
+		respondWithError(w, http.StatusInternalServerError, "Failed to fetch stock data: "+err.Error())
+		return
+	}
+
+	if len(stockData) == 0 {
+		respondWithError(w, http.StatusNotFound, "No data found for symbol: "+req.Symbol)
+		return
+	}
+
+	market := h.fetchMarketContext("", "", fromDate, toDate)
+	market.History = stockData
+
+	log.Printf("Optimizing %d parameters on %d bars for %s", len(req.Optimize.Parameters), len(stockData), req.Symbol)
+
+	factory := backtest.AnalyzerStrategyFactory(h.analyzer.Config(), req.Symbol, market, req.Window, req.Signal)
+	result, err := backtest.Optimize(stockData, factory, req.Config, req.Optimize)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Failed to optimize: "+err.Error())
+		return
+	}
+	result.Symbol = req.Symbol
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// screenWorkers bounds the concurrent history fetches of a screen
+const screenWorkers = 4
+
+// Screen checks a rule against the latest bar of each symbol
+func (h *Handler) Screen(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var req ScreenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	if len(req.Symbols) == 0 {
+		respondWithError(w, http.StatusBadRequest, "Symbols are required")
+		return
+	}
+
+	rule, err := rules.Parse(req.Rule)
+	if err != nil {
+		respondWithError(w, http.StatusBadRequest, "Invalid rule: "+err.Error())
+		return
+	}
+
+	if req.DaysBack == 0 {
+		req.DaysBack = 400
+	}
+
+	toDate := time.Now()
+	fromDate := toDate.AddDate(0, 0, -req.DaysBack)
+
+	log.Printf("Screening %d symbols with rule %q", len(req.Symbols), rule.Source)
+
+	result := models.ScreenResult{
+		Rule:    rule.Source,
+		Matches: []string{},
+		Results: make([]models.ScreenRow, len(req.Symbols)),
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(screenWorkers, len(req.Symbols)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				symbol := strings.ToUpper(strings.TrimSpace(req.Symbols[i]))
+				result.Results[i] = h.screenSymbol(symbol, rule, fromDate, toDate)
+			}
+		}()
+	}
+	for i := range req.Symbols {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+
+	for _, row := range result.Results {
+		if row.Matched {
+			result.Matches = append(result.Matches, row.Symbol)
+		}
+	}
+
+	respondWithJSON(w, http.StatusOK, result)
+}
+
+// screenSymbol fetches one symbol's history and checks the rule at its last bar.
+// A parsed rule holds no state, so the workers share it. It runs on a worker
+// goroutine, where net/http cannot recover a panic, so a panic becomes the row's error
+func (h *Handler) screenSymbol(symbol string, rule *rules.Rule, fromDate, toDate time.Time) (row models.ScreenRow) {
+	row = models.ScreenRow{Symbol: symbol}
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Screening %s panicked: %v", symbol, r)
+			row.Matched = false
+			row.Error = fmt.Sprintf("Failed to evaluate rule: %v", r)
+		}
+	}()
+
+	stockData, err := h.ssiClient.GetHistoricalData(symbol, fromDate, toDate)
+	if err != nil {
+		row.Error = "Failed to fetch stock data: " + err.Error()
+		return row
+	}
+	if len(stockData) == 0 {
+		row.Error = "No data found"
+		return row
+	}
+
+	last := stockData[len(stockData)-1]
+	row.Date = &last.Date
+	row.Close = last.Close
+	row.Matched = rule.Evaluate(stockData)
+	return row
+}
+
+// ScoringModels lists the registered scoring models and the signals a model can use
+func (h *Handler) ScoringModels(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	response := ScoringModelsResponse{
+		Default: h.analyzer.Config().ScoringModel.Name,
+		Models:  []analysis.ScoringModel{},
+		Signals: analysis.ScoringSignals(),
+	}
+	for _, model := range h.scoringModels {
+		response.Models = append(response.Models, model)
+	}
+	sort.Slice(response.Models, func(i, j int) bool {
+		return response.Models[i].Name < response.Models[j].Name
+	})
+
+	respondWithJSON(w, http.StatusOK, response)
+}
+
 func (h *Handler) GetStockPrice(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodGet {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
@@ -153,6 +830,12 @@ func (h *Handler) RegisterRoutes() http.Handler {
 	mux.HandleFunc("/api/health", h.HealthCheck)
 	mux.HandleFunc("/api/analyze", h.AnalyzeStock)
 	mux.HandleFunc("/api/price", h.GetStockPrice)
+	mux.HandleFunc("/api/transform", h.TransformBars)
+	mux.HandleFunc("/api/backtest", h.Backtest)
+	mux.HandleFunc("/api/optimize", h.Optimize)
+	mux.HandleFunc("/api/scoring-models", h.ScoringModels)
+	mux.HandleFunc("/api/screen", h.Screen)
+	mux.HandleFunc("/api/wyckoff/backtest", h.BacktestWyckoff)
 
 	return enableCORS(mux)
 }
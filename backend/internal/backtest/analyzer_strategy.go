@@ -0,0 +1,108 @@
+package backtest
+
+import (
+	"stocking-chain/internal/analysis"
+)
+
+// ============================================================================
+// ANALYZER RECOMMENDATION STRATEGY
+// ============================================================================
+
+// AnalyzerStrategy trades the Analyzer's buy/sell/hold recommendation, or the
+// Wyckoff recommendation when Signal is "wyckoff". At each bar it analyzes only
+// the bars so far (the last Window of them) and the market series cut at that
+// bar. A change to "buy" places a limit buy at the top of the BuyRange, renewed
+// each session until it fills or the recommendation changes. While holding, a
+// limit sell sits at the bottom of the SellRange when that is above the cost,
+// and a change to "sell" exits at the next open
+type AnalyzerStrategy struct {
+	Analyzer   *analysis.Analyzer
+	Symbol     string
+	Market     *analysis.MarketContext // Index, sector and longer history; cut at each bar
+	Window     int                     // Bars each analysis sees; 0 sees every bar so far
+	WarmupBars int                     // Bars before the first analysis
+	Signal     string                  // "recommendation" (overall, default) or "wyckoff"
+
+	recommendation string
+	wantPosition   bool
+	held           bool
+}
+
+// NewAnalyzerStrategy analyzes the last 200 bars at each bar after a 60-bar warmup
+func NewAnalyzerStrategy(analyzer *analysis.Analyzer, symbol string, market *analysis.MarketContext) *AnalyzerStrategy {
+	return &AnalyzerStrategy{
+		Analyzer:   analyzer,
+		Symbol:     symbol,
+		Market:     market,
+		Window:     200,
+		WarmupBars: 60,
+	}
+}
+
+func (s *AnalyzerStrategy) Name() string {
+	return "analyzer_recommendation"
+}
+
+// HistoryBars is how many bars a full analysis window needs
+func (s *AnalyzerStrategy) HistoryBars() int {
+	return max(s.Window, s.WarmupBars)
+}
+
+func (s *AnalyzerStrategy) OnBar(ctx *Context) []Order {
+	if len(ctx.Bars) < s.WarmupBars {
+		return nil
+	}
+
+	bars := ctx.Bars
+	if s.Window > 0 && len(bars) > s.Window {
+		bars = bars[len(bars)-s.Window:]
+	}
+	bar := ctx.Bar()
+
+	report, err := s.Analyzer.AnalyzeWithMarket(s.Symbol, bars, s.Market.Through(bar.Date))
+	if err != nil || report == nil {
+		return nil
+	}
+
+	// A position closed at the sell range waits for the next buy signal
+	if s.held && ctx.Position.Shares == 0 {
+		s.wantPosition = false
+	}
+	s.held = ctx.Position.Shares > 0
+
+	recommendation := report.Recommendation
+	if s.Signal == "wyckoff" {
+		recommendation = report.Wyckoff.Recommendation
+	}
+
+	changed := recommendation != s.recommendation
+	s.recommendation = recommendation
+	if changed {
+		switch recommendation {
+		case "buy":
+			s.wantPosition = true
+		case "sell":
+			s.wantPosition = false
+		}
+	}
+
+	if s.wantPosition && ctx.Position.Shares == 0 {
+		limit := min(report.BuyRange.Max, bar.Close)
+		if limit <= 0 {
+			limit = bar.Close
+		}
+		return []Order{{Side: Buy, Fraction: 1, LimitPrice: limit, Reason: "recommendation buy"}}
+	}
+
+	if ctx.Position.Sellable == 0 {
+		return nil
+	}
+	if !s.wantPosition {
+		return []Order{{Side: Sell, Fraction: 1, Reason: "recommendation sell"}}
+	}
+	// The sell range is a take-profit, so it must sit above the price and the cost
+	if report.SellRange.Min > max(bar.Close, ctx.Position.AvgCost) {
+		return []Order{{Side: Sell, Fraction: 1, LimitPrice: report.SellRange.Min, Reason: "sell range reached"}}
+	}
+	return nil
+}
@@ -0,0 +1,344 @@
+package backtest
+
+import (
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// BACKTEST ENGINE
+// ============================================================================
+
+// Config holds the account and market rules of a backtest. The defaults model
+// the Ho Chi Minh Stock Exchange (HOSE)
+type Config struct {
+	InitialCapital     float64 `json:"initial_capital"`       // VND
+	CommissionRate     float64 `json:"commission_rate"`       // Broker commission per side, fraction of trade value
+	SellTaxRate        float64 `json:"sell_tax_rate"`         // Tax on sell value
+	SettlementDays     int     `json:"settlement_days"`       // Trading days before bought shares can be sold (T+2)
+	PriceLimit         float64 `json:"price_limit"`           // Daily band around the reference (previous) close; 0 disables
+	LotSize            int64   `json:"lot_size"`              // Orders are rounded down to whole lots
+	RiskFreeRate       float64 `json:"risk_free_rate"`        // Annual, for Sharpe and Sortino
+	TradingDaysPerYear float64 `json:"trading_days_per_year"` // Annualization factor
+}
+
+// DefaultConfig returns HOSE rules: 0.15% commission, 0.1% sell tax, T+2
+// settlement, a ±7% daily price band and 100-share lots
+func DefaultConfig() Config {
+	return Config{
+		InitialCapital:     100_000_000,
+		CommissionRate:     0.0015,
+		SellTaxRate:        0.001,
+		SettlementDays:     2,
+		PriceLimit:         0.07,
+		LotSize:            100,
+		RiskFreeRate:       0,
+		TradingDaysPerYear: 250,
+	}
+}
+
+// Order sides
+const (
+	Buy  = "buy"
+	Sell = "sell"
+)
+
+// Order is placed at a bar close and executes during the next session only.
+// A zero LimitPrice is a market order filled at the open
+type Order struct {
+	Side       string
+	Shares     int64   // Shares to trade; 0 uses Fraction
+	Fraction   float64 // With Shares == 0: fraction of cash to spend (buy) or of sellable shares to sell
+	LimitPrice float64
+	Reason     string
+}
+
+// Position is the strategy's view of its holding
+type Position struct {
+	Shares   int64   // Shares held, settled or not
+	Sellable int64   // Shares that have settled
+	AvgCost  float64 // Average cost per share, including buy commission
+}
+
+// Context is what a strategy sees at a bar close. Bars ends at the current
+// bar, so a strategy cannot look ahead
+type Context struct {
+	Bars     []models.StockData
+	Cash     float64
+	Equity   float64
+	Position Position
+}
+
+// Bar returns the current (latest) bar
+func (c *Context) Bar() models.StockData {
+	return c.Bars[len(c.Bars)-1]
+}
+
+// Strategy decides what to trade at each bar close
+type Strategy interface {
+	Name() string
+	OnBar(ctx *Context) []Order
+}
+
+// lot is a block of bought shares and the bar index they become sellable on
+type lot struct {
+	shares      int64
+	sellableIdx int
+}
+
+// account tracks cash and holdings during a run
+type account struct {
+	cash    float64
+	lots    []lot
+	avgCost float64
+}
+
+func (a *account) shares() int64 {
+	total := int64(0)
+	for _, l := range a.lots {
+		total += l.shares
+	}
+	return total
+}
+
+func (a *account) sellable(idx int) int64 {
+	total := int64(0)
+	for _, l := range a.lots {
+		if l.sellableIdx <= idx {
+			total += l.shares
+		}
+	}
+	return total
+}
+
+// Run replays data bar by bar. After each close the strategy sees the bars so
+// far and places orders; they fill during the next bar subject to the price
+// band, lot size, available cash and settled shares
+func Run(data []models.StockData, strategy Strategy, cfg Config) models.BacktestResult {
+	result := models.BacktestResult{
+		Strategy:       strategy.Name(),
+		InitialCapital: cfg.InitialCapital,
+		FinalEquity:    cfg.InitialCapital,
+		EquityCurve:    []models.EquityPoint{},
+		Trades:         []models.BacktestTrade{},
+	}
+	rejections := map[string]int{}
+	if len(data) == 0 {
+		result.Metrics = calculateMetrics(result, cfg, rejections)
+		return result
+	}
+	result.StartDate = data[0].Date
+	result.EndDate = data[len(data)-1].Date
+
+	acct := &account{cash: cfg.InitialCapital}
+	pending := []Order{}
+	peak := cfg.InitialCapital
+
+	for i, bar := range data {
+		for _, order := range pending {
+			trade, reason := execute(order, data, i, acct, cfg)
+			if reason != "" {
+				rejections[reason]++
+				continue
+			}
+			result.Trades = append(result.Trades, trade)
+		}
+
+		shares := acct.shares()
+		positionValue := float64(shares) * bar.Close
+		equity := acct.cash + positionValue
+		peak = math.Max(peak, equity)
+		result.EquityCurve = append(result.EquityCurve, models.EquityPoint{
+			Date:          bar.Date,
+			Equity:        equity,
+			Cash:          acct.cash,
+			PositionValue: positionValue,
+			Shares:        shares,
+			Drawdown:      (peak - equity) / peak * 100,
+		})
+
+		pending = strategy.OnBar(&Context{
+			Bars:   data[:i+1],
+			Cash:   acct.cash,
+			Equity: equity,
+			Position: Position{
+				Shares:   shares,
+				Sellable: acct.sellable(i + 1), // What can be sold in the session the orders execute in
+				AvgCost:  acct.avgCost,
+			},
+		})
+	}
+
+	result.FinalEquity = result.EquityCurve[len(result.EquityCurve)-1].Equity
+	result.Metrics = calculateMetrics(result, cfg, rejections)
+	return result
+}
+
+// Benchmark is a frictionless buy-and-hold of data's closes scaled to the
+// initial capital, for series such as an index that cannot be traded directly
+func Benchmark(name string, data []models.StockData, cfg Config) models.BacktestResult {
+	result := models.BacktestResult{
+		Strategy:       name,
+		InitialCapital: cfg.InitialCapital,
+		FinalEquity:    cfg.InitialCapital,
+		EquityCurve:    []models.EquityPoint{},
+		Trades:         []models.BacktestTrade{},
+	}
+	if len(data) == 0 || data[0].Close <= 0 {
+		result.Metrics = calculateMetrics(result, cfg, map[string]int{})
+		return result
+	}
+	result.StartDate = data[0].Date
+	result.EndDate = data[len(data)-1].Date
+
+	peak := cfg.InitialCapital
+	for _, bar := range data {
+		equity := cfg.InitialCapital * bar.Close / data[0].Close
+		peak = math.Max(peak, equity)
+		result.EquityCurve = append(result.EquityCurve, models.EquityPoint{
+			Date:          bar.Date,
+			Equity:        equity,
+			PositionValue: equity,
+			Shares:        1,
+			Drawdown:      (peak - equity) / peak * 100,
+		})
+	}
+
+	result.FinalEquity = result.EquityCurve[len(result.EquityCurve)-1].Equity
+	result.Metrics = calculateMetrics(result, cfg, map[string]int{})
+	return result
+}
+
+// execute fills order against bar idx, or returns why it could not fill
+func execute(order Order, data []models.StockData, idx int, acct *account, cfg Config) (models.BacktestTrade, string) {
+	bar := data[idx]
+	trade := models.BacktestTrade{Date: bar.Date, Side: order.Side, Reason: order.Reason}
+
+	// The session's price band is set from the previous close
+	floor, ceiling := 0.0, math.Inf(1)
+	if cfg.PriceLimit > 0 && idx > 0 {
+		reference := data[idx-1].Close
+		floor = reference * (1 - cfg.PriceLimit)
+		ceiling = reference * (1 + cfg.PriceLimit)
+	}
+	if order.LimitPrice > 0 && (order.LimitPrice < floor || order.LimitPrice > ceiling) {
+		return trade, "outside_price_band"
+	}
+
+	// A session that traded only at the ceiling (floor) had no sellers (buyers)
+	tolerance := bar.Close * 0.001
+	lockedUp := bar.Low >= ceiling-tolerance
+	lockedDown := bar.High <= floor+tolerance
+
+	var price float64
+	switch order.Side {
+	case Buy:
+		if lockedUp {
+			return trade, "limit_up"
+		}
+		price = bar.Open
+		if order.LimitPrice > 0 {
+			if bar.Low > order.LimitPrice {
+				return trade, "limit_not_reached"
+			}
+			price = math.Min(bar.Open, order.LimitPrice)
+		}
+	case Sell:
+		if lockedDown {
+			return trade, "limit_down"
+		}
+		price = bar.Open
+		if order.LimitPrice > 0 {
+			if bar.High < order.LimitPrice {
+				return trade, "limit_not_reached"
+			}
+			price = math.Max(bar.Open, order.LimitPrice)
+		}
+	default:
+		return trade, "invalid_side"
+	}
+	price = math.Max(floor, math.Min(ceiling, price))
+	if price <= 0 {
+		return trade, "invalid_price"
+	}
+
+	lotSize := max(cfg.LotSize, 1)
+	shares := order.Shares
+	if order.Side == Buy {
+		if shares == 0 {
+			budget := acct.cash * order.Fraction
+			shares = int64(budget / (price * (1 + cfg.CommissionRate)))
+		}
+		shares = shares / lotSize * lotSize
+		if shares <= 0 {
+			return trade, "below_lot_size"
+		}
+		cost := float64(shares) * price
+		commission := cost * cfg.CommissionRate
+		if cost+commission > acct.cash {
+			return trade, "insufficient_cash"
+		}
+
+		held := float64(acct.shares())
+		acct.avgCost = (acct.avgCost*held + cost + commission) / (held + float64(shares))
+		acct.cash -= cost + commission
+		acct.lots = append(acct.lots, lot{shares: shares, sellableIdx: idx + cfg.SettlementDays})
+
+		trade.Shares = shares
+		trade.Price = price
+		trade.Value = cost
+		trade.Commission = commission
+		return trade, ""
+	}
+
+	sellable := acct.sellable(idx)
+	if shares == 0 {
+		shares = int64(float64(sellable) * order.Fraction)
+	}
+	// Selling everything sellable may leave an odd lot, which HOSE lets go in one order
+	if shares < sellable {
+		shares = shares / lotSize * lotSize
+	}
+	if shares <= 0 {
+		if acct.shares() > 0 && sellable == 0 {
+			return trade, "unsettled_shares"
+		}
+		return trade, "no_shares"
+	}
+	if shares > sellable {
+		return trade, "unsettled_shares"
+	}
+
+	value := float64(shares) * price
+	commission := value * cfg.CommissionRate
+	tax := value * cfg.SellTaxRate
+	acct.cash += value - commission - tax
+	acct.removeShares(shares, idx)
+
+	trade.Shares = shares
+	trade.Price = price
+	trade.Value = value
+	trade.Commission = commission
+	trade.Tax = tax
+	trade.RealizedPnL = value - commission - tax - acct.avgCost*float64(shares)
+	if acct.shares() == 0 {
+		acct.avgCost = 0
+	}
+	return trade, ""
+}
+
+// removeShares takes shares out of the settled lots, oldest first
+func (a *account) removeShares(shares int64, idx int) {
+	kept := a.lots[:0]
+	for _, l := range a.lots {
+		if shares > 0 && l.sellableIdx <= idx {
+			taken := min(shares, l.shares)
+			l.shares -= taken
+			shares -= taken
+		}
+		if l.shares > 0 {
+			kept = append(kept, l)
+		}
+	}
+	a.lots = kept
+}
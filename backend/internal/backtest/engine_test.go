@@ -0,0 +1,134 @@
+package backtest
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"stocking-chain/internal/models"
+)
+
+// scripted places fixed orders at the close of the given bar indices
+type scripted map[int][]Order
+
+func (s scripted) Name() string { return "scripted" }
+
+func (s scripted) OnBar(ctx *Context) []Order {
+	return s[len(ctx.Bars)-1]
+}
+
+// flatBars is n bars opening, trading and closing at price
+func flatBars(n int, price float64) []models.StockData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]models.StockData, n)
+	for i := range data {
+		data[i] = models.StockData{
+			Date:   start.AddDate(0, 0, i),
+			Open:   price,
+			High:   price * 1.01,
+			Low:    price * 0.99,
+			Close:  price,
+			Volume: 100000,
+		}
+	}
+	return data
+}
+
+// withBar replaces bar idx's prices
+func withBar(data []models.StockData, idx int, open, high, low, close float64) []models.StockData {
+	data[idx].Open, data[idx].High, data[idx].Low, data[idx].Close = open, high, low, close
+	return data
+}
+
+func TestRunMarketRules(t *testing.T) {
+	buy := Order{Side: Buy, Shares: 1050}
+	sellAll := Order{Side: Sell, Fraction: 1}
+
+	cases := []struct {
+		name        string
+		data        []models.StockData
+		orders      scripted
+		wantTrades  []models.BacktestTrade
+		wantRejects map[string]int
+		wantCash    float64
+	}{
+		{
+			// Bought in session 1, the shares settle on T+2 = session 3
+			name:   "sell on T+1 is rejected",
+			data:   flatBars(4, 10000),
+			orders: scripted{0: {buy}, 1: {sellAll}, 2: {sellAll}},
+			wantTrades: []models.BacktestTrade{
+				{Side: Buy, Shares: 1000, Price: 10000, Value: 10_000_000, Commission: 15_000},
+				{Side: Sell, Shares: 1000, Price: 10000, Value: 10_000_000, Commission: 15_000, Tax: 10_000, RealizedPnL: -40_000},
+			},
+			wantRejects: map[string]int{"unsettled_shares": 1},
+			wantCash:    100_000_000 - 40_000,
+		},
+		{
+			// The band is ±7% of the previous close of 10000: 9300 to 10700
+			name: "limit outside the price band does not fill",
+			data: flatBars(3, 10000),
+			orders: scripted{
+				0: {{Side: Buy, Shares: 100, LimitPrice: 9200}},
+				1: {{Side: Buy, Shares: 100, LimitPrice: 10800}},
+			},
+			wantTrades:  nil,
+			wantRejects: map[string]int{"outside_price_band": 2},
+			wantCash:    100_000_000,
+		},
+		{
+			// Session 4 trades only at the floor, so there are no buyers
+			name:   "locked limit down cannot be sold",
+			data:   withBar(flatBars(5, 10000), 4, 9300, 9300, 9300, 9300),
+			orders: scripted{0: {buy}, 3: {sellAll}},
+			wantTrades: []models.BacktestTrade{
+				{Side: Buy, Shares: 1000, Price: 10000, Value: 10_000_000, Commission: 15_000},
+			},
+			wantRejects: map[string]int{"limit_down": 1},
+			wantCash:    100_000_000 - 10_015_000,
+		},
+		{
+			// 1050 shares round down to 10 lots. Selling 1000 × 10800 pays 0.15%
+			// commission (16200) and 0.1% tax (10800) against a cost of 10015000
+			name:   "fees match a hand-computed fill",
+			data:   withBar(withBar(flatBars(5, 10000), 3, 10400, 10500, 10300, 10500), 4, 10800, 11000, 10700, 10900),
+			orders: scripted{0: {buy}, 3: {sellAll}},
+			wantTrades: []models.BacktestTrade{
+				{Side: Buy, Shares: 1000, Price: 10000, Value: 10_000_000, Commission: 15_000},
+				{Side: Sell, Shares: 1000, Price: 10800, Value: 10_800_000, Commission: 16_200, Tax: 10_800, RealizedPnL: 758_000},
+			},
+			wantRejects: map[string]int{},
+			wantCash:    100_000_000 + 758_000,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			result := Run(tc.data, tc.orders, DefaultConfig())
+
+			if len(result.Trades) != len(tc.wantTrades) {
+				t.Fatalf("got %d trades %+v, want %d", len(result.Trades), result.Trades, len(tc.wantTrades))
+			}
+			for i, want := range tc.wantTrades {
+				got := result.Trades[i]
+				if got.Side != want.Side || got.Shares != want.Shares ||
+					!near(got.Price, want.Price) || !near(got.Value, want.Value) ||
+					!near(got.Commission, want.Commission) || !near(got.Tax, want.Tax) ||
+					!near(got.RealizedPnL, want.RealizedPnL) {
+					t.Errorf("trade %d = %+v, want %+v", i, got, want)
+				}
+			}
+			if !reflect.DeepEqual(result.Metrics.Rejections, tc.wantRejects) {
+				t.Errorf("rejections = %v, want %v", result.Metrics.Rejections, tc.wantRejects)
+			}
+			if cash := result.EquityCurve[len(result.EquityCurve)-1].Cash; !near(cash, tc.wantCash) {
+				t.Errorf("cash = %.2f, want %.2f", cash, tc.wantCash)
+			}
+		})
+	}
+}
+
+func near(got, want float64) bool {
+	return math.Abs(got-want) < 1e-6
+}
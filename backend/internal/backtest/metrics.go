@@ -0,0 +1,131 @@
+package backtest
+
+import (
+	"math"
+	"stocking-chain/internal/models"
+)
+
+// ============================================================================
+// PERFORMANCE METRICS
+// ============================================================================
+
+// calculateMetrics derives return, risk and activity statistics from a run
+func calculateMetrics(result models.BacktestResult, cfg Config, rejections map[string]int) models.BacktestMetrics {
+	metrics := models.BacktestMetrics{
+		Trades:     len(result.Trades),
+		Rejections: rejections,
+	}
+
+	for _, trade := range result.Trades {
+		metrics.TotalCommission += trade.Commission
+		metrics.TotalTax += trade.Tax
+		if trade.Side == Sell {
+			metrics.RoundTrips++
+			if trade.RealizedPnL > 0 {
+				metrics.WinRate++
+			}
+		}
+	}
+	if metrics.RoundTrips > 0 {
+		metrics.WinRate = metrics.WinRate / float64(metrics.RoundTrips) * 100
+	}
+
+	curve := result.EquityCurve
+	if len(curve) == 0 || result.InitialCapital <= 0 {
+		return metrics
+	}
+
+	metrics.TotalReturn = (result.FinalEquity/result.InitialCapital - 1) * 100
+
+	years := result.EndDate.Sub(result.StartDate).Hours() / 24 / 365.25
+	if years > 0 && result.FinalEquity > 0 {
+		metrics.CAGR = (math.Pow(result.FinalEquity/result.InitialCapital, 1/years) - 1) * 100
+	}
+
+	returns := EquityReturns(curve)
+	periodsPerYear := cfg.TradingDaysPerYear
+	if periodsPerYear <= 0 {
+		periodsPerYear = 250
+	}
+	metrics.Volatility = stdDev(returns) * math.Sqrt(periodsPerYear) * 100
+	metrics.Sharpe, metrics.Sortino = sharpeSortino(returns, cfg.RiskFreeRate, periodsPerYear)
+
+	exposed, underwater := 0, 0
+	for _, point := range curve {
+		if point.Shares > 0 {
+			exposed++
+		}
+		metrics.MaxDrawdown = math.Max(metrics.MaxDrawdown, point.Drawdown)
+		if point.Drawdown > 0 {
+			underwater++
+			metrics.MaxDrawdownBars = max(metrics.MaxDrawdownBars, underwater)
+		} else {
+			underwater = 0
+		}
+	}
+	metrics.Exposure = float64(exposed) / float64(len(curve)) * 100
+
+	return metrics
+}
+
+// EquityReturns returns the bar-to-bar returns of an equity curve as fractions
+func EquityReturns(curve []models.EquityPoint) []float64 {
+	returns := make([]float64, 0, max(len(curve)-1, 0))
+	for i := 1; i < len(curve); i++ {
+		if curve[i-1].Equity > 0 {
+			returns = append(returns, curve[i].Equity/curve[i-1].Equity-1)
+		}
+	}
+	return returns
+}
+
+// sharpeSortino annualizes the excess return over total and downside deviation
+func sharpeSortino(returns []float64, riskFreeRate, periodsPerYear float64) (float64, float64) {
+	if len(returns) < 2 {
+		return 0, 0
+	}
+
+	perPeriod := riskFreeRate / periodsPerYear
+	excess := make([]float64, len(returns))
+	downside := 0.0
+	for i, r := range returns {
+		excess[i] = r - perPeriod
+		if excess[i] < 0 {
+			downside += excess[i] * excess[i]
+		}
+	}
+
+	mean := average(excess)
+	sharpe, sortino := 0.0, 0.0
+	if sd := stdDev(excess); sd > 0 {
+		sharpe = mean / sd * math.Sqrt(periodsPerYear)
+	}
+	if dd := math.Sqrt(downside / float64(len(excess))); dd > 0 {
+		sortino = mean / dd * math.Sqrt(periodsPerYear)
+	}
+	return sharpe, sortino
+}
+
+func average(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+// stdDev is the sample standard deviation
+func stdDev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	mean := average(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(sum / float64(len(values)-1))
+}
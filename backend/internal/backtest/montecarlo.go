@@ -0,0 +1,289 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"stocking-chain/internal/models"
+	"time"
+)
+
+// ============================================================================
+// MONTE CARLO ROBUSTNESS
+// ============================================================================
+
+// Monte Carlo methods
+const (
+	TradeResample  = "trade_resample"
+	BlockBootstrap = "block_bootstrap"
+)
+
+// MonteCarloConfig controls the resampling of a backtest. Zero fields take the
+// defaults of DefaultMonteCarloConfig
+type MonteCarloConfig struct {
+	Simulations     int     `json:"simulations"`
+	BlockSize       int     `json:"block_size"`       // Bars per bootstrap block; keeps volatility clustering intact
+	RuinLoss        float64 `json:"ruin_loss"`        // Percent of the initial capital lost that counts as ruin
+	ConfidenceLevel float64 `json:"confidence_level"` // Width of the reported confidence intervals, e.g. 0.9
+	Seed            int64   `json:"seed"`
+}
+
+// DefaultMonteCarloConfig runs 1000 paths per method with 20-bar blocks, treats
+// losing half the capital as ruin and reports 90% confidence intervals
+func DefaultMonteCarloConfig() MonteCarloConfig {
+	return MonteCarloConfig{
+		Simulations:     1000,
+		BlockSize:       20,
+		RuinLoss:        50,
+		ConfidenceLevel: 0.9,
+		Seed:            1,
+	}
+}
+
+// maxMonteCarloSimulations bounds the paths per method; every path is a full
+// equity curve, so memory grows with simulations × bars
+const maxMonteCarloSimulations = 10000
+
+// Validate checks that the number of simulations and the ruin loss are in range
+func (mc MonteCarloConfig) Validate() error {
+	if mc.Simulations > maxMonteCarloSimulations {
+		return fmt.Errorf("simulations must be at most %d, got %d", maxMonteCarloSimulations, mc.Simulations)
+	}
+	if mc.RuinLoss > 100 {
+		return fmt.Errorf("ruin_loss must be at most 100, got %.2f", mc.RuinLoss)
+	}
+	return nil
+}
+
+// bandPercentiles are the percentiles of the equity fan chart
+var bandPercentiles = []float64{5, 25, 50, 75, 95}
+
+// MonteCarlo estimates how much of a backtest's outcome is luck of ordering.
+// Trade resampling draws the round trips' returns with replacement; the block
+// bootstrap rebuilds the bar-to-bar equity returns from random runs of
+// consecutive bars. Both report drawdown, return and ruin distributions
+func MonteCarlo(result models.BacktestResult, cfg Config, mc MonteCarloConfig) models.MonteCarloResult {
+	defaults := DefaultMonteCarloConfig()
+	if mc.Simulations <= 0 {
+		mc.Simulations = defaults.Simulations
+	}
+	if mc.BlockSize <= 0 {
+		mc.BlockSize = defaults.BlockSize
+	}
+	if mc.RuinLoss <= 0 {
+		mc.RuinLoss = defaults.RuinLoss
+	}
+	if mc.ConfidenceLevel <= 0 || mc.ConfidenceLevel >= 1 {
+		mc.ConfidenceLevel = defaults.ConfidenceLevel
+	}
+
+	mcResult := models.MonteCarloResult{
+		Symbol:          result.Symbol,
+		Strategy:        result.Strategy,
+		Simulations:     mc.Simulations,
+		BlockSize:       mc.BlockSize,
+		RuinLoss:        mc.RuinLoss,
+		ConfidenceLevel: mc.ConfidenceLevel,
+		Original:        result.Metrics,
+	}
+	if len(result.EquityCurve) < 2 || result.InitialCapital <= 0 {
+		return mcResult
+	}
+
+	years := result.EndDate.Sub(result.StartDate).Hours() / 24 / 365.25
+	rng := rand.New(rand.NewSource(mc.Seed))
+
+	if returns := tradeReturns(result); len(returns) > 0 {
+		paths := make([][]float64, mc.Simulations)
+		for s := range paths {
+			sample := make([]float64, len(returns))
+			for i := range sample {
+				sample[i] = returns[rng.Intn(len(returns))]
+			}
+			paths[s] = compoundPath(result.InitialCapital, sample)
+		}
+		sim := summarizePaths(TradeResample, len(returns), paths, result.InitialCapital, years, nil, mc)
+		mcResult.TradeResample = &sim
+	}
+
+	if returns := EquityReturns(result.EquityCurve); len(returns) > 1 {
+		periodsPerYear := cfg.TradingDaysPerYear
+		if periodsPerYear <= 0 {
+			periodsPerYear = 250
+		}
+		paths := make([][]float64, mc.Simulations)
+		sharpes := make([]float64, mc.Simulations)
+		for s := range paths {
+			sample := blockResample(returns, mc.BlockSize, rng)
+			paths[s] = compoundPath(result.InitialCapital, sample)
+			sharpes[s], _ = sharpeSortino(sample, cfg.RiskFreeRate, periodsPerYear)
+		}
+		sim := summarizePaths(BlockBootstrap, len(returns), paths, result.InitialCapital, years, sharpes, mc)
+
+		// Bootstrap paths have one point per bar, so the bands line up with the dates
+		for i := range sim.EquityBands {
+			date := result.EquityCurve[i].Date
+			sim.EquityBands[i].Date = &date
+		}
+		mcResult.BlockBootstrap = &sim
+	}
+
+	return mcResult
+}
+
+// tradeReturns converts each sell's realized PnL into a return on the equity
+// before the round trip. The strategy's cash is idle while a position is open,
+// so that equity is the equity after the sell less its PnL
+func tradeReturns(result models.BacktestResult) []float64 {
+	equityAt := map[time.Time]float64{}
+	for _, point := range result.EquityCurve {
+		equityAt[point.Date] = point.Equity
+	}
+
+	returns := []float64{}
+	for _, trade := range result.Trades {
+		if trade.Side != Sell {
+			continue
+		}
+		before := equityAt[trade.Date] - trade.RealizedPnL
+		if before > 0 {
+			returns = append(returns, trade.RealizedPnL/before)
+		}
+	}
+	return returns
+}
+
+// blockResample draws a series as long as returns from blocks of consecutive
+// returns with random starts, wrapping around the end (circular block bootstrap)
+func blockResample(returns []float64, blockSize int, rng *rand.Rand) []float64 {
+	n := len(returns)
+	blockSize = min(blockSize, n)
+	sample := make([]float64, 0, n)
+	for len(sample) < n {
+		start := rng.Intn(n)
+		for j := 0; j < blockSize && len(sample) < n; j++ {
+			sample = append(sample, returns[(start+j)%n])
+		}
+	}
+	return sample
+}
+
+// compoundPath turns returns into an equity path starting at initial
+func compoundPath(initial float64, returns []float64) []float64 {
+	path := make([]float64, len(returns)+1)
+	path[0] = initial
+	for i, r := range returns {
+		path[i+1] = math.Max(path[i]*(1+r), 0)
+	}
+	return path
+}
+
+// summarizePaths measures every simulated path and collects the distributions.
+// sharpes is nil when the paths are not bar by bar
+func summarizePaths(method string, samples int, paths [][]float64, initial, years float64, sharpes []float64, mc MonteCarloConfig) models.MonteCarloSimulation {
+	sim := models.MonteCarloSimulation{Method: method, Samples: samples}
+
+	n := len(paths)
+	finals := make([]float64, n)
+	totalReturns := make([]float64, n)
+	cagrs := make([]float64, n)
+	drawdowns := make([]float64, n)
+	ruinLevel := initial * (1 - mc.RuinLoss/100)
+	ruined, losses := 0, 0
+
+	for s, path := range paths {
+		peak, maxDrawdown, hitRuin := path[0], 0.0, false
+		for _, equity := range path {
+			peak = math.Max(peak, equity)
+			if peak > 0 {
+				maxDrawdown = math.Max(maxDrawdown, (peak-equity)/peak*100)
+			}
+			if equity <= ruinLevel {
+				hitRuin = true
+			}
+		}
+
+		final := path[len(path)-1]
+		finals[s] = final
+		totalReturns[s] = (final/initial - 1) * 100
+		if years > 0 && final > 0 {
+			cagrs[s] = (math.Pow(final/initial, 1/years) - 1) * 100
+		} else if final <= 0 {
+			cagrs[s] = -100
+		}
+		drawdowns[s] = maxDrawdown
+		if hitRuin {
+			ruined++
+		}
+		if final < initial {
+			losses++
+		}
+	}
+
+	sim.FinalEquity = distribution(finals, mc.ConfidenceLevel)
+	sim.TotalReturn = distribution(totalReturns, mc.ConfidenceLevel)
+	sim.CAGR = distribution(cagrs, mc.ConfidenceLevel)
+	sim.MaxDrawdown = distribution(drawdowns, mc.ConfidenceLevel)
+	if sharpes != nil {
+		sharpe := distribution(sharpes, mc.ConfidenceLevel)
+		sim.Sharpe = &sharpe
+	}
+	sim.ProbabilityOfRuin = float64(ruined) / float64(n) * 100
+	sim.ProbabilityOfLoss = float64(losses) / float64(n) * 100
+
+	// Fan chart: the percentiles of equity across paths at every step
+	steps := len(paths[0])
+	column := make([]float64, n)
+	sim.EquityBands = make([]models.EquityBand, steps)
+	for i := 0; i < steps; i++ {
+		for s, path := range paths {
+			column[s] = path[i]
+		}
+		sort.Float64s(column)
+		values := make([]float64, len(bandPercentiles))
+		for j, p := range bandPercentiles {
+			values[j] = percentile(column, p)
+		}
+		sim.EquityBands[i] = models.EquityBand{
+			Step: i,
+			P5:   values[0],
+			P25:  values[1],
+			P50:  values[2],
+			P75:  values[3],
+			P95:  values[4],
+		}
+	}
+
+	return sim
+}
+
+// distribution summarizes values with a two-sided confidence interval
+func distribution(values []float64, confidence float64) models.Distribution {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	tail := (1 - confidence) / 2 * 100
+	return models.Distribution{
+		Mean:   average(sorted),
+		StdDev: stdDev(sorted),
+		Min:    sorted[0],
+		Lower:  percentile(sorted, tail),
+		Median: percentile(sorted, 50),
+		Upper:  percentile(sorted, 100-tail),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+// percentile interpolates the p-th percentile (0-100) of sorted values
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p / 100 * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+	if lower == upper {
+		return sorted[lower]
+	}
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
@@ -0,0 +1,582 @@
+package backtest
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
+	"stocking-chain/internal/analysis"
+	"stocking-chain/internal/models"
+	"strings"
+	"sync"
+)
+
+// ============================================================================
+// PARAMETER OPTIMIZATION WITH WALK-FORWARD VALIDATION
+// ============================================================================
+
+// heatmapBins is how many cells an axis gets when its values do not form a small grid
+const heatmapBins = 5
+
+// Parameter is one dimension of a search space, named by its AnalyzerConfig
+// JSON field (e.g. "buy_threshold", "rsi_period")
+type Parameter struct {
+	Name string  `json:"name"`
+	Min  float64 `json:"min"`
+	Max  float64 `json:"max"`
+	Step float64 `json:"step"` // Grid spacing; random draws snap to it when set
+}
+
+// OptimizeConfig describes the search and the walk-forward folds. Each fold
+// optimizes over InSampleBars and then trades the winner over the following
+// OutOfSampleBars; folds roll forward by OutOfSampleBars
+type OptimizeConfig struct {
+	Parameters      []Parameter `json:"parameters"`
+	Method          string      `json:"method"`  // "grid" or "random"
+	Samples         int         `json:"samples"` // Random search draws
+	Seed            int64       `json:"seed"`
+	Objective       string      `json:"objective"` // "sharpe", "sortino", "cagr", "total_return", "calmar"
+	InSampleBars    int         `json:"in_sample_bars"`
+	OutOfSampleBars int         `json:"out_of_sample_bars"`
+	WarmupBars      int         `json:"warmup_bars"` // History before each segment the strategy sees but does not trade; raised to the strategy's window
+	Workers         int         `json:"workers"`     // 0 uses every CPU core
+}
+
+// DefaultOptimizeConfig grid-searches the overall buy/sell thresholds for the
+// best Sharpe ratio over one year in-sample and three months out-of-sample
+func DefaultOptimizeConfig() OptimizeConfig {
+	return OptimizeConfig{
+		Parameters: []Parameter{
+			{Name: "buy_threshold", Min: 0.2, Max: 0.4, Step: 0.1},
+			{Name: "sell_threshold", Min: -0.4, Max: -0.2, Step: 0.1},
+		},
+		Method:          "grid",
+		Samples:         20,
+		Seed:            1,
+		Objective:       "sharpe",
+		InSampleBars:    250,
+		OutOfSampleBars: 60,
+	}
+}
+
+// StrategyFactory builds a fresh strategy for one parameter set
+type StrategyFactory func(params map[string]float64) (Strategy, error)
+
+// historyStrategy is a Strategy that needs a number of earlier bars before its
+// first signal means anything, such as an analysis window
+type historyStrategy interface {
+	HistoryBars() int
+}
+
+// AnalyzerStrategyFactory builds AnalyzerStrategies whose AnalyzerConfig is base
+// with params applied, so the tuned thresholds belong to the deployed scoring model
+func AnalyzerStrategyFactory(base analysis.AnalyzerConfig, symbol string, market *analysis.MarketContext, window int, signal string) StrategyFactory {
+	return func(params map[string]float64) (Strategy, error) {
+		config := base
+		if err := ApplyParameters(&config, params); err != nil {
+			return nil, err
+		}
+		if err := config.Validate(); err != nil {
+			return nil, err
+		}
+
+		strategy := NewAnalyzerStrategy(analysis.NewAnalyzerWithConfig(config), symbol, market)
+		if window > 0 {
+			strategy.Window = window
+		}
+		strategy.Signal = signal
+		return strategy, nil
+	}
+}
+
+// ApplyParameters sets the fields of the struct target points to whose JSON
+// names appear in params. Integer fields are rounded
+func ApplyParameters(target any, params map[string]float64) error {
+	v := reflect.ValueOf(target).Elem()
+	t := v.Type()
+
+	applied := map[string]bool{}
+	for i := 0; i < t.NumField(); i++ {
+		name := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		value, ok := params[name]
+		if !ok {
+			continue
+		}
+		field := v.Field(i)
+		switch field.Kind() {
+		case reflect.Int, reflect.Int64:
+			field.SetInt(int64(math.Round(value)))
+		case reflect.Float64:
+			field.SetFloat(value)
+		default:
+			return fmt.Errorf("parameter %s is not numeric", name)
+		}
+		applied[name] = true
+	}
+
+	for name := range params {
+		if !applied[name] {
+			return fmt.Errorf("unknown parameter %s", name)
+		}
+	}
+	return nil
+}
+
+// segment is the range of bars data[from:to] a candidate trades
+type segment struct {
+	from, to int
+}
+
+// evaluation is one backtest of a parameter set over a segment
+type evaluation struct {
+	fold, candidate int
+	result          models.BacktestResult
+	err             error
+}
+
+// segmentStrategy hides the warmup bars from a strategy: it is first called on
+// the bar before tradeFrom, so its first orders fill on tradeFrom
+type segmentStrategy struct {
+	Strategy
+	tradeFrom int
+}
+
+func (s segmentStrategy) OnBar(ctx *Context) []Order {
+	if len(ctx.Bars) < s.tradeFrom {
+		return nil
+	}
+	return s.Strategy.OnBar(ctx)
+}
+
+// Optimize searches the parameter space with walk-forward validation. Every
+// candidate is backtested on every in-sample segment in parallel; the best of
+// each fold is then traded on the out-of-sample segment that follows it
+func Optimize(data []models.StockData, factory StrategyFactory, cfg Config, opt OptimizeConfig) (models.OptimizationResult, error) {
+	result := models.OptimizationResult{
+		Method:     opt.Method,
+		Objective:  opt.Objective,
+		Candidates: []models.OptimizationCandidate{},
+		Folds:      []models.WalkForwardFold{},
+		Heatmaps:   []models.ParameterHeatmap{},
+	}
+
+	if len(opt.Parameters) == 0 {
+		return result, fmt.Errorf("at least one parameter is required")
+	}
+	if opt.InSampleBars <= 0 || opt.OutOfSampleBars <= 0 {
+		return result, fmt.Errorf("in_sample_bars and out_of_sample_bars must be positive")
+	}
+	if _, err := objectiveScore(models.BacktestMetrics{}, opt.Objective); err != nil {
+		return result, err
+	}
+
+	candidates, err := buildCandidates(opt)
+	if err != nil {
+		return result, err
+	}
+	// Every segment gets the history the hungriest candidate needs, so no fold
+	// trades on a truncated analysis window
+	warmup := max(opt.WarmupBars, 0)
+	for _, params := range candidates {
+		strategy, err := factory(params)
+		if err != nil {
+			return result, fmt.Errorf("invalid parameters %v: %w", params, err)
+		}
+		if h, ok := strategy.(historyStrategy); ok {
+			warmup = max(warmup, h.HistoryBars())
+		}
+	}
+
+	inSample, outOfSample := []segment{}, []segment{}
+	for start := warmup; start+opt.InSampleBars+opt.OutOfSampleBars <= len(data); start += opt.OutOfSampleBars {
+		split := start + opt.InSampleBars
+		inSample = append(inSample, segment{from: start, to: split})
+		outOfSample = append(outOfSample, segment{from: split, to: split + opt.OutOfSampleBars})
+	}
+	if len(inSample) == 0 {
+		return result, fmt.Errorf("need at least %d bars for one fold, have %d", warmup+opt.InSampleBars+opt.OutOfSampleBars, len(data))
+	}
+
+	workers := opt.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	result.Workers = workers
+
+	// In-sample: every candidate on every fold
+	jobs := []evaluation{}
+	for f := range inSample {
+		for c := range candidates {
+			jobs = append(jobs, evaluation{fold: f, candidate: c})
+		}
+	}
+	runEvaluations(jobs, workers, func(e *evaluation) {
+		e.result, e.err = evaluateSegment(data, inSample[e.fold], warmup, factory, candidates[e.candidate], cfg)
+	})
+
+	scores := make([][]float64, len(inSample))
+	for f := range scores {
+		scores[f] = make([]float64, len(candidates))
+	}
+	for _, job := range jobs {
+		if job.err != nil {
+			return result, job.err
+		}
+		scores[job.fold][job.candidate], _ = objectiveScore(job.result.Metrics, opt.Objective)
+	}
+
+	// Out-of-sample: the best in-sample candidate of each fold
+	best := make([]int, len(inSample))
+	oosJobs := []evaluation{}
+	for f := range inSample {
+		for c := range candidates {
+			if scores[f][c] > scores[f][best[f]] {
+				best[f] = c
+			}
+		}
+		oosJobs = append(oosJobs, evaluation{fold: f, candidate: best[f]})
+	}
+	runEvaluations(oosJobs, workers, func(e *evaluation) {
+		e.result, e.err = evaluateSegment(data, outOfSample[e.fold], warmup, factory, candidates[e.candidate], cfg)
+	})
+	result.Evaluations = len(jobs) + len(oosJobs)
+
+	segments := []models.BacktestResult{}
+	for f, job := range oosJobs {
+		if job.err != nil {
+			return result, job.err
+		}
+		oosScore, _ := objectiveScore(job.result.Metrics, opt.Objective)
+		result.Folds = append(result.Folds, models.WalkForwardFold{
+			InSampleStart:    data[inSample[f].from].Date,
+			InSampleEnd:      data[inSample[f].to-1].Date,
+			OutOfSampleStart: data[outOfSample[f].from].Date,
+			OutOfSampleEnd:   data[outOfSample[f].to-1].Date,
+			Params:           candidates[best[f]],
+			InSampleScore:    scores[f][best[f]],
+			OutOfSampleScore: oosScore,
+			OutOfSample:      job.result.Metrics,
+		})
+		result.MeanInSampleScore += scores[f][best[f]]
+		result.MeanOutOfSampleScore += oosScore
+		segments = append(segments, job.result)
+	}
+	result.MeanInSampleScore /= float64(len(result.Folds))
+	result.MeanOutOfSampleScore /= float64(len(result.Folds))
+	if result.MeanInSampleScore != 0 {
+		result.WalkForwardEfficiency = result.MeanOutOfSampleScore / result.MeanInSampleScore
+	}
+	result.OutOfSample = chainSegments(segments, cfg)
+
+	// Rank the candidates by their mean in-sample score across the folds
+	for c, params := range candidates {
+		perFold := make([]float64, len(inSample))
+		selected := 0
+		for f := range inSample {
+			perFold[f] = scores[f][c]
+			if best[f] == c {
+				selected++
+			}
+		}
+		result.Candidates = append(result.Candidates, models.OptimizationCandidate{
+			Params:        params,
+			MeanScore:     average(perFold),
+			ScoreStdDev:   stdDev(perFold),
+			TimesSelected: selected,
+		})
+	}
+	sort.SliceStable(result.Candidates, func(a, b int) bool {
+		return result.Candidates[a].MeanScore > result.Candidates[b].MeanScore
+	})
+	result.Recommended = result.Candidates[0].Params
+
+	for i := 0; i < len(opt.Parameters); i++ {
+		for j := i + 1; j < len(opt.Parameters); j++ {
+			result.Heatmaps = append(result.Heatmaps, buildHeatmap(opt.Parameters[i], opt.Parameters[j], candidates, scores))
+		}
+	}
+
+	return result, nil
+}
+
+// runEvaluations runs fn on every job using a fixed number of goroutines
+func runEvaluations(jobs []evaluation, workers int, fn func(*evaluation)) {
+	next := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < min(workers, len(jobs)); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				fn(&jobs[i])
+			}
+		}()
+	}
+	for i := range jobs {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+}
+
+// evaluateSegment backtests params over seg, letting the strategy see up to
+// warmup bars of earlier history, and returns the result for the traded bars only
+func evaluateSegment(data []models.StockData, seg segment, warmup int, factory StrategyFactory, params map[string]float64, cfg Config) (models.BacktestResult, error) {
+	strategy, err := factory(params)
+	if err != nil {
+		return models.BacktestResult{}, err
+	}
+	if h, ok := strategy.(historyStrategy); ok && min(seg.from, warmup) < h.HistoryBars() {
+		return models.BacktestResult{}, fmt.Errorf("segment starting at bar %d has %d bars of history, %v needs %d", seg.from, min(seg.from, warmup), params, h.HistoryBars())
+	}
+
+	start := max(0, seg.from-warmup)
+	offset := seg.from - start
+	run := Run(data[start:seg.to], segmentStrategy{Strategy: strategy, tradeFrom: offset}, cfg)
+	run.Strategy = strategy.Name()
+	return trimResult(run, offset, cfg), nil
+}
+
+// trimResult drops the first offset bars of a run, which were warmup without trades
+func trimResult(run models.BacktestResult, offset int, cfg Config) models.BacktestResult {
+	if offset <= 0 || offset >= len(run.EquityCurve) {
+		return run
+	}
+
+	trimmed := run
+	trimmed.EquityCurve = run.EquityCurve[offset:]
+	trimmed.StartDate = trimmed.EquityCurve[0].Date
+	trimmed.InitialCapital = run.EquityCurve[offset-1].Equity
+	trimmed.Metrics = calculateMetrics(trimmed, cfg, run.Metrics.Rejections)
+	return trimmed
+}
+
+// chainSegments joins consecutive out-of-sample runs into one equity curve, each
+// segment compounding on the equity the previous one ended with
+func chainSegments(segments []models.BacktestResult, cfg Config) models.BacktestResult {
+	chained := models.BacktestResult{
+		Strategy:       "walk_forward",
+		InitialCapital: cfg.InitialCapital,
+		FinalEquity:    cfg.InitialCapital,
+		EquityCurve:    []models.EquityPoint{},
+		Trades:         []models.BacktestTrade{},
+	}
+	rejections := map[string]int{}
+	if len(segments) == 0 {
+		chained.Metrics = calculateMetrics(chained, cfg, rejections)
+		return chained
+	}
+	chained.StartDate = segments[0].StartDate
+	chained.EndDate = segments[len(segments)-1].EndDate
+
+	equity, peak := cfg.InitialCapital, cfg.InitialCapital
+	for _, seg := range segments {
+		if seg.InitialCapital <= 0 {
+			continue
+		}
+		scale := equity / seg.InitialCapital
+		for _, point := range seg.EquityCurve {
+			point.Equity *= scale
+			point.Cash *= scale
+			point.PositionValue *= scale
+			peak = math.Max(peak, point.Equity)
+			point.Drawdown = (peak - point.Equity) / peak * 100
+			chained.EquityCurve = append(chained.EquityCurve, point)
+		}
+		if len(chained.EquityCurve) > 0 {
+			equity = chained.EquityCurve[len(chained.EquityCurve)-1].Equity
+		}
+		chained.Trades = append(chained.Trades, seg.Trades...)
+		for reason, count := range seg.Metrics.Rejections {
+			rejections[reason] += count
+		}
+	}
+
+	chained.FinalEquity = equity
+	chained.Metrics = calculateMetrics(chained, cfg, rejections)
+	return chained
+}
+
+// buildCandidates lists the parameter sets to evaluate
+func buildCandidates(opt OptimizeConfig) ([]map[string]float64, error) {
+	for _, p := range opt.Parameters {
+		if p.Max < p.Min {
+			return nil, fmt.Errorf("parameter %s: max is below min", p.Name)
+		}
+	}
+
+	switch opt.Method {
+	case "", "grid":
+		candidates := []map[string]float64{{}}
+		for _, p := range opt.Parameters {
+			values := parameterValues(p)
+			next := make([]map[string]float64, 0, len(candidates)*len(values))
+			for _, base := range candidates {
+				for _, value := range values {
+					params := map[string]float64{p.Name: value}
+					for k, v := range base {
+						params[k] = v
+					}
+					next = append(next, params)
+				}
+			}
+			candidates = next
+		}
+		return candidates, nil
+
+	case "random":
+		if opt.Samples <= 0 {
+			return nil, fmt.Errorf("random search needs samples > 0")
+		}
+		rng := rand.New(rand.NewSource(opt.Seed))
+		candidates := make([]map[string]float64, opt.Samples)
+		for i := range candidates {
+			candidates[i] = map[string]float64{}
+			for _, p := range opt.Parameters {
+				value := p.Min + rng.Float64()*(p.Max-p.Min)
+				if p.Step > 0 {
+					value = snapToStep(value, p)
+				}
+				candidates[i][p.Name] = value
+			}
+		}
+		return candidates, nil
+	}
+
+	return nil, fmt.Errorf("unknown search method %q (use grid or random)", opt.Method)
+}
+
+// parameterValues lists the grid points of p from Min to Max
+func parameterValues(p Parameter) []float64 {
+	if p.Step <= 0 || p.Max == p.Min {
+		return []float64{p.Min}
+	}
+	values := []float64{}
+	for i := 0; ; i++ {
+		value := p.Min + float64(i)*p.Step
+		if value > p.Max+p.Step*1e-9 {
+			break
+		}
+		values = append(values, math.Round(value*1e9)/1e9)
+	}
+	return values
+}
+
+// snapToStep rounds value to the nearest grid point of p
+func snapToStep(value float64, p Parameter) float64 {
+	steps := math.Round((value - p.Min) / p.Step)
+	return math.Round((p.Min+steps*p.Step)*1e9) / 1e9
+}
+
+// objectiveScore extracts the optimization target from the metrics
+func objectiveScore(metrics models.BacktestMetrics, objective string) (float64, error) {
+	switch objective {
+	case "", "sharpe":
+		return metrics.Sharpe, nil
+	case "sortino":
+		return metrics.Sortino, nil
+	case "cagr":
+		return metrics.CAGR, nil
+	case "total_return":
+		return metrics.TotalReturn, nil
+	case "calmar":
+		if metrics.MaxDrawdown == 0 {
+			return 0, nil
+		}
+		return metrics.CAGR / metrics.MaxDrawdown, nil
+	}
+	return 0, fmt.Errorf("unknown objective %q", objective)
+}
+
+// buildHeatmap averages the in-sample scores over the (x, y) parameter plane.
+// Each cell's value is averaged per fold first, so Spread measures how much
+// the cell's score moves from one period to the next
+func buildHeatmap(x, y Parameter, candidates []map[string]float64, scores [][]float64) models.ParameterHeatmap {
+	xValues, xIndex := heatmapAxis(x, candidates)
+	yValues, yIndex := heatmapAxis(y, candidates)
+
+	heatmap := models.ParameterHeatmap{
+		X:       x.Name,
+		Y:       y.Name,
+		XValues: xValues,
+		YValues: yValues,
+		Values:  make([][]float64, len(yValues)),
+		Spread:  make([][]float64, len(yValues)),
+		Samples: make([][]int, len(yValues)),
+	}
+
+	// sums[row][col][fold] accumulates the scores of the cell's candidates in that fold
+	sums := make([][][]float64, len(yValues))
+	counts := make([][]int, len(yValues))
+	for row := range sums {
+		sums[row] = make([][]float64, len(xValues))
+		counts[row] = make([]int, len(xValues))
+		for col := range sums[row] {
+			sums[row][col] = make([]float64, len(scores))
+		}
+		heatmap.Values[row] = make([]float64, len(xValues))
+		heatmap.Spread[row] = make([]float64, len(xValues))
+		heatmap.Samples[row] = make([]int, len(xValues))
+	}
+
+	for c, params := range candidates {
+		row, col := yIndex(params[y.Name]), xIndex(params[x.Name])
+		counts[row][col]++
+		for f := range scores {
+			sums[row][col][f] += scores[f][c]
+		}
+	}
+
+	for row := range sums {
+		for col := range sums[row] {
+			n := counts[row][col]
+			if n == 0 {
+				continue
+			}
+			perFold := make([]float64, len(scores))
+			for f := range perFold {
+				perFold[f] = sums[row][col][f] / float64(n)
+			}
+			heatmap.Values[row][col] = average(perFold)
+			heatmap.Spread[row][col] = stdDev(perFold)
+			heatmap.Samples[row][col] = n * len(scores)
+		}
+	}
+
+	return heatmap
+}
+
+// heatmapAxis returns the axis labels for p and a function mapping a value to
+// its cell. Grids of up to 10 points get a cell per value; otherwise the range
+// is split into heatmapBins equal bins labelled by their centers
+func heatmapAxis(p Parameter, candidates []map[string]float64) ([]float64, func(float64) int) {
+	distinct := map[float64]bool{}
+	for _, params := range candidates {
+		distinct[params[p.Name]] = true
+	}
+
+	if len(distinct) <= 10 {
+		values := make([]float64, 0, len(distinct))
+		for v := range distinct {
+			values = append(values, v)
+		}
+		sort.Float64s(values)
+		return values, func(v float64) int {
+			return sort.SearchFloat64s(values, v)
+		}
+	}
+
+	width := (p.Max - p.Min) / heatmapBins
+	values := make([]float64, heatmapBins)
+	for i := range values {
+		values[i] = p.Min + width*(float64(i)+0.5)
+	}
+	return values, func(v float64) int {
+		if width <= 0 {
+			return 0
+		}
+		return min(heatmapBins-1, max(0, int((v-p.Min)/width)))
+	}
+}
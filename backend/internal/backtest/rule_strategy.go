@@ -0,0 +1,60 @@
+package backtest
+
+import (
+	"stocking-chain/internal/rules"
+)
+
+// ============================================================================
+// RULE STRATEGY
+// ============================================================================
+
+// RuleStrategy buys at the next open when the entry rule holds and no position
+// is open, and sells the settled shares at the next open when the exit rule
+// holds. Without an exit rule it exits once the entry rule stops holding. The
+// rules see only the bars so far
+type RuleStrategy struct {
+	Entry *rules.Rule
+	Exit  *rules.Rule // Optional
+
+	ctx *rules.Context
+}
+
+// NewRuleStrategy trades entry and the optional exit rule
+func NewRuleStrategy(entry, exit *rules.Rule) *RuleStrategy {
+	return &RuleStrategy{Entry: entry, Exit: exit}
+}
+
+func (s *RuleStrategy) Name() string {
+	return "rule"
+}
+
+func (s *RuleStrategy) OnBar(ctx *Context) []Order {
+	// The bars grow by one each call, so the indicator caches carry over
+	if s.ctx == nil {
+		s.ctx = rules.NewContext(ctx.Bars)
+	} else {
+		s.ctx.SetData(ctx.Bars)
+	}
+	i := len(ctx.Bars) - 1
+
+	if ctx.Position.Shares == 0 {
+		if s.Entry.At(s.ctx, i) {
+			return []Order{{Side: Buy, Fraction: 1, Reason: "entry rule"}}
+		}
+		return nil
+	}
+
+	if ctx.Position.Sellable == 0 {
+		return nil
+	}
+	if s.Exit != nil {
+		if s.Exit.At(s.ctx, i) {
+			return []Order{{Side: Sell, Fraction: 1, Reason: "exit rule"}}
+		}
+		return nil
+	}
+	if !s.Entry.At(s.ctx, i) {
+		return []Order{{Side: Sell, Fraction: 1, Reason: "entry rule no longer holds"}}
+	}
+	return nil
+}
@@ -0,0 +1,20 @@
+package backtest
+
+// ============================================================================
+// BUILT-IN STRATEGIES
+// ============================================================================
+
+// BuyAndHold spends all cash on the first bar and never sells. It is the
+// baseline other strategies are compared against
+type BuyAndHold struct{}
+
+func (BuyAndHold) Name() string {
+	return "buy_and_hold"
+}
+
+func (BuyAndHold) OnBar(ctx *Context) []Order {
+	if ctx.Position.Shares > 0 {
+		return nil
+	}
+	return []Order{{Side: Buy, Fraction: 1, Reason: "initial purchase"}}
+}
@@ -28,10 +28,40 @@ type TechnicalIndicators struct {
 	BollingerLower float64 `json:"bollinger_lower"`
 }
 
+// PatternAdjustment records why a pattern's confidence was boosted or penalized
+type PatternAdjustment struct {
+	Factor string  `json:"factor"` // "volume", "trend"
+	Delta  float64 `json:"delta"`
+	Reason string  `json:"reason"`
+}
+
 type CandlestickPattern struct {
-	Name       string  `json:"name"`
-	Type       string  `json:"type"` // "bullish", "bearish", "neutral"
-	Confidence float64 `json:"confidence"`
+	Name           string              `json:"name"`
+	Type           string              `json:"type"` // "bullish", "bearish", "neutral"
+	Confidence     float64             `json:"confidence"`
+	BaseConfidence float64             `json:"base_confidence"` // Confidence from the candle shape alone
+	RelativeVolume float64             `json:"relative_volume"` // Average volume of the pattern bars vs. the 20 bars before
+	Adjustments    []PatternAdjustment `json:"adjustments,omitempty"`
+}
+
+// ChartPoint is a labeled swing point used to draw a chart pattern overlay
+type ChartPoint struct {
+	Label string    `json:"label"` // "left_shoulder", "head", "neckline", etc.
+	Date  time.Time `json:"date"`
+	Price float64   `json:"price"`
+}
+
+// ChartPattern represents a multi-week chart structure built from swing points
+type ChartPattern struct {
+	Name          string       `json:"name"`   // "Head and Shoulders", "Double Bottom", "Ascending Triangle", etc.
+	Type          string       `json:"type"`   // "bullish", "bearish", "neutral"
+	Status        string       `json:"status"` // "forming", "confirmed", "failed"
+	StartDate     time.Time    `json:"start_date"`
+	EndDate       time.Time    `json:"end_date"`
+	BreakoutLevel float64      `json:"breakout_level"` // Neckline or breakout line at the latest bar
+	Target        float64      `json:"target"`         // Measured-move price target
+	Confidence    float64      `json:"confidence"`
+	KeyPoints     []ChartPoint `json:"key_points"`
 }
 
 type TimeframePatterns struct {
@@ -40,6 +70,20 @@ type TimeframePatterns struct {
 	Monthly []CandlestickPattern `json:"monthly"`
 }
 
+// PriceGap represents a price gap between two consecutive bars
+type PriceGap struct {
+	Direction   string     `json:"direction"` // "up", "down"
+	Kind        string     `json:"kind"`      // "common", "breakaway", "runaway", "exhaustion"
+	Date        time.Time  `json:"date"`      // Date of the bar that opened the gap
+	Low         float64    `json:"low"`       // Bottom edge of the gap zone
+	High        float64    `json:"high"`      // Top edge of the gap zone
+	Size        float64    `json:"size"`      // Gap height as a fraction of the prior close
+	VolumeRatio float64    `json:"volume_ratio"`
+	FillPercent float64    `json:"fill_percent"` // 0 to 1, how much of the gap later trading has covered
+	Filled      bool       `json:"filled"`
+	FilledDate  *time.Time `json:"filled_date,omitempty"`
+}
+
 type SupportResistance struct {
 	SupportLevels    []float64 `json:"support_levels"`
 	ResistanceLevels []float64 `json:"resistance_levels"`
@@ -58,7 +102,9 @@ type AnalysisReport struct {
 	CurrentPrice        float64             `json:"current_price"`
 	Indicators          TechnicalIndicators `json:"indicators"`
 	Patterns            TimeframePatterns   `json:"patterns"`
+	ChartPatterns       []ChartPattern      `json:"chart_patterns"`
 	SupportResistance   SupportResistance   `json:"support_resistance"`
+	Gaps                []PriceGap          `json:"gaps"`
 	Trend               TrendAnalysis       `json:"trend"`
 	Wyckoff             WyckoffAnalysis     `json:"wyckoff"`
 	BuyRange            PriceRange          `json:"buy_range"`
@@ -66,7 +112,177 @@ type AnalysisReport struct {
 	SellRange           PriceRange          `json:"sell_range"`
 	Recommendation      string              `json:"recommendation"` // "buy", "sell", "hold"
 	RecommendationScore float64             `json:"recommendation_score"`
+	ScoringModel        string              `json:"scoring_model"`              // Model that produced the recommendation
+	ModelComparison     []ModelScore        `json:"model_comparison,omitempty"` // The scoring model first, then the comparison models
+	ScoreBreakdown      ScoreBreakdown      `json:"score_breakdown"`            // Why the scoring model reached RecommendationScore
 	PriceHistory        []StockData         `json:"price_history"`
+	Transform           *BarTransform       `json:"transform,omitempty"`      // Set when the analysis ran on transformed bars
+	AsOf                *time.Time          `json:"as_of,omitempty"`          // Set for point-in-time analysis: the last bar the analysis could see
+	CustomSignals       []CustomSignal      `json:"custom_signals,omitempty"` // Rule-based signals requested with the analysis
+	TradePlan           *TradePlan          `json:"trade_plan,omitempty"`     // Position size, stop and targets for the requested account
+	Forecast            *PriceForecast      `json:"forecast,omitempty"`       // Statistical price bands and level probabilities
+}
+
+// ModelScore is the overall score and recommendation of one scoring model
+type ModelScore struct {
+	Model          string         `json:"model"`
+	Description    string         `json:"description,omitempty"`
+	RawScore       float64        `json:"raw_score"` // Weighted sum before normalization
+	Score          float64        `json:"score"`     // -1 to 1
+	Recommendation string         `json:"recommendation"`
+	BuyThreshold   float64        `json:"buy_threshold"`
+	SellThreshold  float64        `json:"sell_threshold"`
+	Breakdown      ScoreBreakdown `json:"breakdown"`
+}
+
+// ScoreContribution is one signal component of a score: the signal's value, the
+// condition it met and what it added after weighting
+type ScoreContribution struct {
+	Name         string  `json:"name"`
+	Value        float64 `json:"value"`     // Signal value before weighting, bullish positive
+	Condition    string  `json:"condition"` // What the signal found, e.g. "RSI 27.3 below 30 (oversold)"
+	Met          bool    `json:"met"`       // Whether the component moved the score
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"` // Weight times value, in raw score units
+}
+
+// ScoreBreakdown explains a score as the sum of its contributions. Score is
+// RawScore divided by Normalizer, clamped to [-1, 1]; the contributions divided
+// by Normalizer form a waterfall that ends at the unclamped score
+type ScoreBreakdown struct {
+	Contributions []ScoreContribution `json:"contributions"`
+	RawScore      float64             `json:"raw_score"`
+	Normalizer    float64             `json:"normalizer"`
+	Score         float64             `json:"score"`
+}
+
+// TradePlan sizes a long trade from the buy and sell ranges: scale-in tiers,
+// a stop-loss, and scale-out targets with their reward-to-risk
+type TradePlan struct {
+	AccountEquity   float64         `json:"account_equity"`
+	RiskPercent     float64         `json:"risk_percent"`
+	RiskBudget      float64         `json:"risk_budget"` // Equity times the risk percent
+	StopMethod      string          `json:"stop_method,omitempty"`
+	StopLoss        float64         `json:"stop_loss"`
+	Stops           []StopCandidate `json:"stops"` // Every method's stop, for comparison
+	Entries         []TradeTier     `json:"entries"`
+	Targets         []TradeTier     `json:"targets"`
+	Shares          int64           `json:"shares"`
+	Lots            int64           `json:"lots"`
+	LotSize         int64           `json:"lot_size"`
+	AverageEntry    float64         `json:"average_entry"`
+	PositionValue   float64         `json:"position_value"`
+	PositionPercent float64         `json:"position_percent"` // Position value, percent of equity
+	RiskPerShare    float64         `json:"risk_per_share"`
+	RiskAmount      float64         `json:"risk_amount"` // Loss at the stop with every tier filled, after lot rounding
+	RewardRisk      float64         `json:"reward_risk"` // Profit at all targets over RiskAmount
+	Notes           []string        `json:"notes"`
+}
+
+// TradeTier is one scale-in entry or scale-out target of a trade plan
+type TradeTier struct {
+	Label      string     `json:"label"`
+	Zone       PriceRange `json:"zone"`
+	Price      float64    `json:"price"`
+	Fraction   float64    `json:"fraction"` // Share of the position
+	Shares     int64      `json:"shares"`
+	Lots       int64      `json:"lots"`
+	RewardRisk float64    `json:"reward_risk,omitempty"` // Targets: gain per share over the risk per share
+	Profit     float64    `json:"profit,omitempty"`      // Targets: profit on this tier's shares
+}
+
+// StopCandidate is the stop-loss one method would place
+type StopCandidate struct {
+	Method          string  `json:"method"` // "structural", "wyckoff", "atr"
+	Price           float64 `json:"price"`
+	DistancePercent float64 `json:"distance_percent"` // Below the lowest entry
+	Reason          string  `json:"reason"`
+}
+
+// PriceForecast holds the statistical price bands of each forecast method
+type PriceForecast struct {
+	Horizon      int              `json:"horizon"` // Trading days ahead
+	CurrentPrice float64          `json:"current_price"`
+	LookbackBars int              `json:"lookback_bars"` // Daily returns the methods were fitted to
+	Drift        bool             `json:"drift"`
+	Methods      []ForecastMethod `json:"methods"`
+	Notes        []string         `json:"notes"`
+}
+
+// ForecastMethod is one method's forecast: volatility, percentile bands by day
+// and the probabilities of reaching each level. Volatilities and
+// probabilities are percentages
+type ForecastMethod struct {
+	Method            string             `json:"method"` // "historical", "bootstrap", "garch"
+	DailyVolatility   float64            `json:"daily_volatility"`
+	AnnualVolatility  float64            `json:"annual_volatility"`
+	HorizonVolatility float64            `json:"horizon_volatility"` // One standard deviation of the log return over the horizon
+	GARCH             *GARCHFit          `json:"garch,omitempty"`
+	Bands             []ForecastBand     `json:"bands"`
+	Levels            []LevelProbability `json:"levels"`
+}
+
+// ForecastBand is the percentile range of the price a number of days ahead
+type ForecastBand struct {
+	Day int     `json:"day"`
+	P5  float64 `json:"p5"`
+	P25 float64 `json:"p25"`
+	P50 float64 `json:"p50"`
+	P75 float64 `json:"p75"`
+	P95 float64 `json:"p95"`
+}
+
+// LevelProbability is the chance of price reaching a support, resistance or
+// Wyckoff zone within the forecast horizon
+type LevelProbability struct {
+	Label                string      `json:"label"` // e.g. "support_1", "sell_zone"
+	Kind                 string      `json:"kind"`  // "support", "resistance", "wyckoff_zone"
+	Price                float64     `json:"price"` // The level, or the zone's edge nearest the price
+	Zone                 *PriceRange `json:"zone,omitempty"`
+	Direction            string      `json:"direction"` // "below", "above", "inside" (price is in the zone)
+	DistancePercent      float64     `json:"distance_percent"`
+	TouchProbability     float64     `json:"touch_probability"`                // Trades at the level at any time within the horizon
+	EndBeyondProbability float64     `json:"end_beyond_probability"`           // Closes past the level on the last day
+	EndInsideProbability *float64    `json:"end_inside_probability,omitempty"` // Zones: closes inside the zone on the last day
+}
+
+// GARCHFit is a GARCH(1,1) model of daily returns; volatilities are daily percentages
+type GARCHFit struct {
+	Omega             float64 `json:"omega"`
+	Alpha             float64 `json:"alpha"` // Reaction to the last shock
+	Beta              float64 `json:"beta"`  // Persistence of the last variance
+	Persistence       float64 `json:"persistence"`
+	HalfLife          float64 `json:"half_life"` // Days for a volatility shock to halve
+	LongRunVolatility float64 `json:"long_run_volatility"`
+	NextVolatility    float64 `json:"next_volatility"` // Conditional volatility of the next day
+	LogLikelihood     float64 `json:"log_likelihood"`
+}
+
+// CustomSignal is a user-defined rule checked against the analyzed bars
+type CustomSignal struct {
+	Name          string     `json:"name"`
+	Rule          string     `json:"rule"`
+	Triggered     bool       `json:"triggered"` // Whether the rule holds at the last bar
+	LastTriggered *time.Time `json:"last_triggered,omitempty"`
+	BarsSince     *int       `json:"bars_since,omitempty"` // Bars since the rule last held, within the lookback
+	TriggerCount  int        `json:"trigger_count"`        // Bars the rule held within the lookback
+	Lookback      int        `json:"lookback"`
+}
+
+// ScreenResult lists the symbols whose latest bar satisfies a rule
+type ScreenResult struct {
+	Rule    string      `json:"rule"`
+	Matches []string    `json:"matches"`
+	Results []ScreenRow `json:"results"` // Every requested symbol, in request order
+}
+
+// ScreenRow is the outcome of a screening rule for one symbol
+type ScreenRow struct {
+	Symbol  string     `json:"symbol"`
+	Matched bool       `json:"matched"`
+	Date    *time.Time `json:"date,omitempty"` // Bar the rule was checked on
+	Close   float64    `json:"close"`
+	Error   string     `json:"error,omitempty"` // Why the symbol could not be screened
 }
 
 type PriceRange struct {
@@ -82,6 +298,16 @@ type WyckoffEvent struct {
 	Price      float64   `json:"price"`
 	Volume     int64     `json:"volume"`
 	Confidence float64   `json:"confidence"`
+
+	// Span of the merged cluster; Date, Price and Volume are from its peak bar
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Bars      int       `json:"bars"`
+
+	// Point-in-time analysis only: the close on which the event was first
+	// reported, and how many bars after the start of the event that was
+	ConfirmedDate    *time.Time `json:"confirmed_date,omitempty"`
+	ConfirmationBars int        `json:"confirmation_bars,omitempty"`
 }
 
 // WyckoffAnalysis contains the complete Wyckoff method analysis
@@ -93,10 +319,409 @@ type WyckoffAnalysis struct {
 	EffortResult    string         `json:"effort_result"` // "confirming", "diverging"
 
 	// Wyckoff-specific recommendation and trading zones
-	Recommendation      string     `json:"recommendation"`       // "buy", "sell", "hold"
-	RecommendationScore float64    `json:"recommendation_score"` // -1 to 1
-	BuyZone             PriceRange `json:"buy_zone"`             // Aggressive entry zone (bottom 20% of range)
-	AccumulationZone    PriceRange `json:"accumulation_zone"`    // Moderate entry zone (20-40% of range)
-	DistributionZone    PriceRange `json:"distribution_zone"`    // Take profit zone (60-80% of range)
-	SellZone            PriceRange `json:"sell_zone"`            // Exit/short zone (top 20% of range)
+	Recommendation      string         `json:"recommendation"`       // "buy", "sell", "hold"
+	RecommendationScore float64        `json:"recommendation_score"` // -1 to 1
+	ScoreBreakdown      ScoreBreakdown `json:"score_breakdown"`      // Why the Wyckoff signals reached RecommendationScore
+	BuyZone             PriceRange     `json:"buy_zone"`             // Aggressive entry zone (bottom 20% of range)
+	AccumulationZone    PriceRange     `json:"accumulation_zone"`    // Moderate entry zone (20-40% of range)
+	DistributionZone    PriceRange     `json:"distribution_zone"`    // Take profit zone (60-80% of range)
+	SellZone            PriceRange     `json:"sell_zone"`            // Exit/short zone (top 20% of range)
+
+	// Schematic phases (A-E) walked chronologically through the events
+	Schematic      string               `json:"schematic,omitempty"`       // "accumulation", "distribution", "reaccumulation", "redistribution"
+	SchematicPhase string               `json:"schematic_phase,omitempty"` // "A" through "E"
+	PhaseHistory   []WyckoffPhasePeriod `json:"phase_history"`
+
+	// Law of cause and effect: Point & Figure count across the trading range
+	CauseCount *CauseCount `json:"cause_count,omitempty"`
+
+	// Full history segmented into trading ranges and the trends between them
+	Ranges []HistoricalRange `json:"ranges"`
+	Trends []TrendSegment    `json:"trends"`
+
+	// Strength versus the market (VN-Index) and, when given, the sector index
+	RelativeStrength *RelativeStrength `json:"relative_strength,omitempty"`
+	SectorStrength   *RelativeStrength `json:"sector_strength,omitempty"`
+
+	// Per-bar Volume Spread Analysis of the Composite Operator's footprints
+	VSA VSAAnalysis `json:"vsa"`
+
+	// Wyckoff readings of the weekly and monthly series and how they agree with daily
+	Timeframes []WyckoffTimeframe  `json:"timeframes"`
+	Alignment  *TimeframeAlignment `json:"alignment,omitempty"`
+}
+
+// WyckoffTimeframe is the Wyckoff reading of one higher timeframe
+type WyckoffTimeframe struct {
+	Timeframe           string         `json:"timeframe"` // "weekly", "monthly"
+	Bars                int            `json:"bars"`
+	Phase               string         `json:"phase"`
+	PhaseConfidence     float64        `json:"phase_confidence"`
+	Schematic           string         `json:"schematic,omitempty"`
+	SchematicPhase      string         `json:"schematic_phase,omitempty"`
+	TradingRange        PriceRange     `json:"trading_range"`
+	Events              []WyckoffEvent `json:"events"`
+	Recommendation      string         `json:"recommendation"`
+	RecommendationScore float64        `json:"recommendation_score"`
+	Bias                string         `json:"bias"` // "bullish", "bearish", "neutral"
+}
+
+// TimeframeAlignment describes how the daily phase agrees with the higher timeframes
+type TimeframeAlignment struct {
+	DailyBias   string  `json:"daily_bias"`  // "bullish", "bearish", "neutral"
+	Agreeing    int     `json:"agreeing"`    // Higher timeframes with the daily bias
+	Conflicting int     `json:"conflicting"` // Higher timeframes with the opposite bias
+	Aligned     bool    `json:"aligned"`     // Daily and every higher timeframe share a bullish or bearish bias
+	Score       float64 `json:"score"`       // Confidence-weighted agreement minus conflict, signed by the daily bias: -1 (sell) to 1 (buy)
+}
+
+// VSABar is one bar annotated with its Volume Spread Analysis classification
+type VSABar struct {
+	Date           time.Time `json:"date"`
+	Open           float64   `json:"open"`
+	High           float64   `json:"high"`
+	Low            float64   `json:"low"`
+	Close          float64   `json:"close"`
+	Volume         int64     `json:"volume"`
+	Spread         string    `json:"spread"` // "narrow", "average", "wide"
+	SpreadRatio    float64   `json:"spread_ratio"`
+	ClosePosition  string    `json:"close_position"` // "low", "mid", "high"
+	CloseLocation  float64   `json:"close_location"` // 0 = low, 1 = high
+	VolumeClass    string    `json:"volume_class"`   // "low", "average", "high", "ultra_high", "unknown"
+	RelativeVolume float64   `json:"relative_volume"`
+	Signal         string    `json:"signal,omitempty"`      // "No Demand", "No Supply", "Stopping Volume", "Upthrust", "Test", "Effort to Rise", "Effort to Fall", "Shakeout", "Climactic Action"
+	SignalType     string    `json:"signal_type,omitempty"` // "bullish", "bearish"
+}
+
+// VSASummary tallies the VSA signals found in the series
+type VSASummary struct {
+	Bars          int            `json:"bars"`
+	Counts        map[string]int `json:"counts"`
+	RecentBullish int            `json:"recent_bullish"` // Bullish signals in the last 20 bars
+	RecentBearish int            `json:"recent_bearish"` // Bearish signals in the last 20 bars
+	Bias          string         `json:"bias"`           // "bullish", "bearish", "neutral"
+	LatestSignal  *VSABar        `json:"latest_signal,omitempty"`
+}
+
+// VSAAnalysis is the annotated bar series and its summary
+type VSAAnalysis struct {
+	Bars    []VSABar   `json:"bars"`
+	Summary VSASummary `json:"summary"`
+}
+
+// RSPoint is one value of a relative strength line
+type RSPoint struct {
+	Date  time.Time `json:"date"`
+	Value float64   `json:"value"`
+}
+
+// RelativeStrength compares a stock's performance against a benchmark index
+type RelativeStrength struct {
+	Benchmark       string             `json:"benchmark"`
+	Line            []RSPoint          `json:"line"`             // Stock/benchmark ratio, 100 on the first shared date
+	Current         float64            `json:"current"`          // Latest RS line value
+	Trend           string             `json:"trend"`            // "rising", "falling", "flat" over the last 20 bars
+	Rank            int                `json:"rank"`             // 0-100 percentile of the current RS value in its history
+	Outperformance  map[string]float64 `json:"outperformance"`   // Stock minus benchmark return (percentage points) by period
+	DownsideCapture float64            `json:"downside_capture"` // Stock/benchmark return on benchmark down days; below 1 is defensive
+
+	// Latest benchmark decline and how the stock held up during it
+	DeclineStart     *time.Time `json:"decline_start,omitempty"`
+	DeclineEnd       *time.Time `json:"decline_end,omitempty"`
+	BenchmarkDecline float64    `json:"benchmark_decline,omitempty"` // Percent
+	StockDecline     float64    `json:"stock_decline,omitempty"`     // Percent over the same dates
+	HeldUp           bool       `json:"held_up"`
+}
+
+// HistoricalRange is one trading range found in the price history and its lifecycle
+type HistoricalRange struct {
+	StartDate     time.Time  `json:"start_date"`
+	EndDate       time.Time  `json:"end_date"`
+	Bars          int        `json:"bars"`
+	Creek         float64    `json:"creek"` // Resistance (average swing high)
+	Ice           float64    `json:"ice"`   // Support (average swing low)
+	High          float64    `json:"high"`
+	Low           float64    `json:"low"`
+	PriorTrend    string     `json:"prior_trend"` // "up", "down", "none"
+	Breakout      string     `json:"breakout"`    // "up", "down", "none" (still in range)
+	BreakoutDate  *time.Time `json:"breakout_date,omitempty"`
+	BreakoutPrice float64    `json:"breakout_price,omitempty"`
+	Outcome       string     `json:"outcome"`   // "active", "pending", "failed", "target_reached", "follow_through"
+	Structure     string     `json:"structure"` // "accumulation", "reaccumulation", "distribution", "redistribution", "undetermined"
+	Active        bool       `json:"active"`
+}
+
+// TrendSegment is a directional move between two trading ranges
+type TrendSegment struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Bars      int       `json:"bars"`
+	Direction string    `json:"direction"` // "up", "down", "sideways"
+	Change    float64   `json:"change"`    // Percent
+}
+
+// WyckoffPhasePeriod is one schematic phase in the progression of a structure
+type WyckoffPhasePeriod struct {
+	Schematic string    `json:"schematic"` // "accumulation", "distribution", "reaccumulation", "redistribution"
+	Phase     string    `json:"phase"`     // "A" through "E"
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"` // Latest bar while the phase is current
+	Trigger   string    `json:"trigger"`  // Event (or "Range Breakout") that began the phase
+	Current   bool      `json:"current"`
+}
+
+// CauseTarget is a price objective projected from a Point & Figure count
+type CauseTarget struct {
+	Direction string  `json:"direction"` // "upside", "downside"
+	Basis     string  `json:"basis"`     // "count_line" (aggressive), "range_extreme" (conservative)
+	Price     float64 `json:"price"`
+	Change    float64 `json:"change"` // Percent from the current price
+}
+
+// CauseCount is a Wyckoff horizontal Point & Figure count and the targets it projects
+type CauseCount struct {
+	Structure  string        `json:"structure"` // "accumulation", "distribution"
+	Bias       string        `json:"bias"`      // "upside", "downside"
+	BoxSize    float64       `json:"box_size"`
+	Reversal   int           `json:"reversal"`
+	StartDate  time.Time     `json:"start_date"`
+	EndDate    time.Time     `json:"end_date"`
+	StartEvent string        `json:"start_event"` // PS/SC, PSY/BC or "Range Start"
+	EndEvent   string        `json:"end_event"`   // LPS/Spring, LPSY/Upthrust or "Latest Bar"
+	CountLine  float64       `json:"count_line"`  // Row the columns were counted along
+	Columns    int           `json:"columns"`     // Horizontal count
+	Cause      float64       `json:"cause"`       // Columns * box size * reversal
+	Targets    []CauseTarget `json:"targets"`
+}
+
+// BarTransform describes an alternative, non time-based bar construction
+type BarTransform struct {
+	Type      string  `json:"type"`                 // "heikin_ashi", "renko", "kagi", "line_break", "point_figure"
+	BoxSize   float64 `json:"box_size,omitempty"`   // Renko/P&F box or Kagi reversal amount; 0 uses ATR
+	ATRPeriod int     `json:"atr_period,omitempty"` // ATR period when BoxSize is 0 (default 14)
+	Reversal  int     `json:"reversal,omitempty"`   // P&F reversal in boxes (default 3)
+	Lines     int     `json:"lines,omitempty"`      // Line break count (default 3)
+}
+
+// PointFigureColumn is a single column of X's (rising) or O's (falling)
+type PointFigureColumn struct {
+	Direction string    `json:"direction"` // "X", "O"
+	Top       float64   `json:"top"`
+	Bottom    float64   `json:"bottom"`
+	Boxes     int       `json:"boxes"`
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+	Volume    int64     `json:"volume"`
+}
+
+// TransformedSeries is the result of applying a BarTransform to daily bars
+type TransformedSeries struct {
+	Symbol    string              `json:"symbol"`
+	Transform BarTransform        `json:"transform"` // With defaults and ATR box size resolved
+	Bars      []StockData         `json:"bars"`
+	Columns   []PointFigureColumn `json:"columns,omitempty"` // Point & Figure only
+}
+
+// WyckoffBacktestTrade is one round trip opened by a Wyckoff event or zone
+type WyckoffBacktestTrade struct {
+	Trigger       string     `json:"trigger"`              // Event name, or "Buy Zone"/"Accumulation Zone"
+	EventDate     *time.Time `json:"event_date,omitempty"` // Bar the event happened on; entry waits for its confirmation
+	EntryDate     time.Time  `json:"entry_date"`
+	EntryPrice    float64    `json:"entry_price"`
+	ExitDate      time.Time  `json:"exit_date"`
+	ExitPrice     float64    `json:"exit_price"`
+	ExitReason    string     `json:"exit_reason"` // "stop_loss", "take_profit", "max_hold", "exit_event", "exit_zone", "end_of_data"
+	Bars          int        `json:"bars"`
+	ReturnPercent float64    `json:"return_percent"`
+}
+
+// WyckoffTriggerStats summarizes the trades of one entry trigger
+type WyckoffTriggerStats struct {
+	Trigger      string  `json:"trigger"`
+	Trades       int     `json:"trades"`
+	Wins         int     `json:"wins"`
+	Losses       int     `json:"losses"`
+	WinRate      float64 `json:"win_rate"`   // Percent
+	AvgWin       float64 `json:"avg_win"`    // Percent
+	AvgLoss      float64 `json:"avg_loss"`   // Percent
+	Expectancy   float64 `json:"expectancy"` // Average return per trade, percent
+	ProfitFactor float64 `json:"profit_factor"`
+	TotalReturn  float64 `json:"total_return"` // Compounded, percent
+	MaxDrawdown  float64 `json:"max_drawdown"` // Percent, on the compounded trade sequence
+	AvgBars      float64 `json:"avg_bars"`
+}
+
+// WyckoffBacktestResult is the outcome of a bar-by-bar Wyckoff backtest
+type WyckoffBacktestResult struct {
+	Symbol    string                 `json:"symbol"`
+	StartDate time.Time              `json:"start_date"`
+	EndDate   time.Time              `json:"end_date"`
+	Bars      int                    `json:"bars"`
+	Trades    []WyckoffBacktestTrade `json:"trades"`
+	ByTrigger []WyckoffTriggerStats  `json:"by_trigger"`
+	Overall   WyckoffTriggerStats    `json:"overall"`
+}
+
+// EquityPoint is the marked-to-market portfolio value at one bar close
+type EquityPoint struct {
+	Date          time.Time `json:"date"`
+	Equity        float64   `json:"equity"`
+	Cash          float64   `json:"cash"`
+	PositionValue float64   `json:"position_value"`
+	Shares        int64     `json:"shares"`
+	Drawdown      float64   `json:"drawdown"` // Percent below the running peak
+}
+
+// BacktestTrade is one filled order
+type BacktestTrade struct {
+	Date        time.Time `json:"date"`
+	Side        string    `json:"side"` // "buy", "sell"
+	Shares      int64     `json:"shares"`
+	Price       float64   `json:"price"`
+	Value       float64   `json:"value"`
+	Commission  float64   `json:"commission"`
+	Tax         float64   `json:"tax"`                    // Sell-side tax
+	RealizedPnL float64   `json:"realized_pnl,omitempty"` // Sells only: against the average cost, after fees
+	Reason      string    `json:"reason,omitempty"`       // Why the strategy placed the order
+}
+
+// BacktestMetrics summarizes a backtest's performance
+type BacktestMetrics struct {
+	TotalReturn     float64        `json:"total_return"`      // Percent
+	CAGR            float64        `json:"cagr"`              // Percent per year
+	Volatility      float64        `json:"volatility"`        // Annualized, percent
+	Sharpe          float64        `json:"sharpe"`            // Annualized
+	Sortino         float64        `json:"sortino"`           // Annualized
+	MaxDrawdown     float64        `json:"max_drawdown"`      // Percent
+	MaxDrawdownBars int            `json:"max_drawdown_bars"` // Longest stretch below a previous peak
+	Exposure        float64        `json:"exposure"`          // Percent of bars holding shares
+	Trades          int            `json:"trades"`            // Filled orders
+	RoundTrips      int            `json:"round_trips"`       // Sells that realized a profit or loss
+	WinRate         float64        `json:"win_rate"`          // Percent of sells with a positive realized PnL
+	TotalCommission float64        `json:"total_commission"`
+	TotalTax        float64        `json:"total_tax"`
+	Rejections      map[string]int `json:"rejections"` // Orders that could not fill, by reason
+}
+
+// BacktestResult is the outcome of replaying a strategy over a price history
+type BacktestResult struct {
+	Symbol         string          `json:"symbol"`
+	Strategy       string          `json:"strategy"`
+	StartDate      time.Time       `json:"start_date"`
+	EndDate        time.Time       `json:"end_date"`
+	InitialCapital float64         `json:"initial_capital"`
+	FinalEquity    float64         `json:"final_equity"`
+	EquityCurve    []EquityPoint   `json:"equity_curve"`
+	Trades         []BacktestTrade `json:"trades"`
+	Metrics        BacktestMetrics `json:"metrics"`
+}
+
+// BacktestComparison sets a strategy's backtest against buy-and-hold of the
+// same stock and against the market index
+type BacktestComparison struct {
+	Symbol             string            `json:"symbol"`
+	Strategy           BacktestResult    `json:"strategy"`
+	BuyAndHold         BacktestResult    `json:"buy_and_hold"`
+	Index              *BacktestResult   `json:"index,omitempty"`
+	ExcessVsBuyAndHold float64           `json:"excess_vs_buy_and_hold"` // Total return difference, percentage points
+	ExcessVsIndex      float64           `json:"excess_vs_index"`        // Total return difference, percentage points
+	MonteCarlo         *MonteCarloResult `json:"monte_carlo,omitempty"`
+}
+
+// OptimizationCandidate is one parameter set and how it scored in-sample
+type OptimizationCandidate struct {
+	Params        map[string]float64 `json:"params"`
+	MeanScore     float64            `json:"mean_score"`     // In-sample objective averaged over the folds
+	ScoreStdDev   float64            `json:"score_std_dev"`  // Spread of the in-sample objective across folds
+	TimesSelected int                `json:"times_selected"` // Folds in which it scored best in-sample
+}
+
+// WalkForwardFold is one in-sample optimization and its out-of-sample check
+type WalkForwardFold struct {
+	InSampleStart    time.Time          `json:"in_sample_start"`
+	InSampleEnd      time.Time          `json:"in_sample_end"`
+	OutOfSampleStart time.Time          `json:"out_of_sample_start"`
+	OutOfSampleEnd   time.Time          `json:"out_of_sample_end"`
+	Params           map[string]float64 `json:"params"` // Best in-sample parameters
+	InSampleScore    float64            `json:"in_sample_score"`
+	OutOfSampleScore float64            `json:"out_of_sample_score"`
+	OutOfSample      BacktestMetrics    `json:"out_of_sample"`
+}
+
+// ParameterHeatmap shows the in-sample objective over a pair of parameters,
+// averaged over the other parameters. Rows follow YValues, columns XValues
+type ParameterHeatmap struct {
+	X       string      `json:"x"`
+	Y       string      `json:"y"`
+	XValues []float64   `json:"x_values"`
+	YValues []float64   `json:"y_values"`
+	Values  [][]float64 `json:"values"`  // Mean objective across folds
+	Spread  [][]float64 `json:"spread"`  // Standard deviation across folds; low means stable
+	Samples [][]int     `json:"samples"` // Evaluations behind each cell; 0 means no data
+}
+
+// OptimizationResult is the outcome of a walk-forward parameter search
+type OptimizationResult struct {
+	Symbol                string                  `json:"symbol"`
+	Method                string                  `json:"method"`    // "grid", "random"
+	Objective             string                  `json:"objective"` // "sharpe", "sortino", "cagr", "total_return", "calmar"
+	Evaluations           int                     `json:"evaluations"`
+	Workers               int                     `json:"workers"`
+	Candidates            []OptimizationCandidate `json:"candidates"` // Best mean in-sample score first
+	Folds                 []WalkForwardFold       `json:"folds"`
+	OutOfSample           BacktestResult          `json:"out_of_sample"` // Out-of-sample segments chained together
+	MeanInSampleScore     float64                 `json:"mean_in_sample_score"`
+	MeanOutOfSampleScore  float64                 `json:"mean_out_of_sample_score"`
+	WalkForwardEfficiency float64                 `json:"walk_forward_efficiency"` // Out-of-sample over in-sample score
+	Heatmaps              []ParameterHeatmap      `json:"heatmaps"`
+	Recommended           map[string]float64      `json:"recommended"` // Best mean in-sample score across every fold
+}
+
+// Distribution summarizes a simulated statistic
+type Distribution struct {
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"std_dev"`
+	Min    float64 `json:"min"`
+	Lower  float64 `json:"lower"` // Lower bound of the confidence interval
+	Median float64 `json:"median"`
+	Upper  float64 `json:"upper"` // Upper bound of the confidence interval
+	Max    float64 `json:"max"`
+}
+
+// EquityBand is the spread of simulated equity at one step of the paths
+type EquityBand struct {
+	Step int        `json:"step"`           // Trade or bar number; 0 is the starting capital
+	Date *time.Time `json:"date,omitempty"` // Bar date, for bar-by-bar paths
+	P5   float64    `json:"p5"`
+	P25  float64    `json:"p25"`
+	P50  float64    `json:"p50"`
+	P75  float64    `json:"p75"`
+	P95  float64    `json:"p95"`
+}
+
+// MonteCarloSimulation is the outcome of one resampling method
+type MonteCarloSimulation struct {
+	Method            string        `json:"method"`  // "trade_resample", "block_bootstrap"
+	Samples           int           `json:"samples"` // Trade or bar returns per path
+	FinalEquity       Distribution  `json:"final_equity"`
+	TotalReturn       Distribution  `json:"total_return"` // Percent
+	CAGR              Distribution  `json:"cagr"`         // Percent per year
+	Sharpe            *Distribution `json:"sharpe,omitempty"`
+	MaxDrawdown       Distribution  `json:"max_drawdown"`        // Percent
+	ProbabilityOfRuin float64       `json:"probability_of_ruin"` // Percent of paths that lost RuinLoss of the capital
+	ProbabilityOfLoss float64       `json:"probability_of_loss"` // Percent of paths ending below the initial capital
+	EquityBands       []EquityBand  `json:"equity_bands"`
+}
+
+// MonteCarloResult is the robustness analysis of a backtest
+type MonteCarloResult struct {
+	Symbol          string                `json:"symbol"`
+	Strategy        string                `json:"strategy"`
+	Simulations     int                   `json:"simulations"`
+	BlockSize       int                   `json:"block_size"`
+	RuinLoss        float64               `json:"ruin_loss"` // Percent
+	ConfidenceLevel float64               `json:"confidence_level"`
+	Original        BacktestMetrics       `json:"original"`
+	TradeResample   *MonteCarloSimulation `json:"trade_resample,omitempty"`  // Absent without round trips
+	BlockBootstrap  *MonteCarloSimulation `json:"block_bootstrap,omitempty"` // Absent without enough bars
 }
@@ -0,0 +1,388 @@
+package rules
+
+import (
+	"math"
+	"strings"
+)
+
+// ============================================================================
+// VARIABLES AND FUNCTIONS
+// ============================================================================
+
+// variable returns the node for a bare name, or nil when the name is not a variable
+func variable(name string) node {
+	switch name {
+	case "open", "high", "low", "close", "volume":
+		return &priceNode{field: name}
+	case "true", "false":
+		return &boolLiteral{value: name == "true"}
+	case "wyckoff.phase", "wyckoff.recommendation", "wyckoff.schematic", "wyckoff.schematic_phase", "wyckoff.effort_result":
+		return &wyckoffTextNode{field: strings.TrimPrefix(name, "wyckoff.")}
+	case "wyckoff.phase_confidence", "wyckoff.score", "wyckoff.range_high", "wyckoff.range_low":
+		return &wyckoffNumberNode{field: strings.TrimPrefix(name, "wyckoff.")}
+	}
+	return nil
+}
+
+// call builds a function node, checking the number and types of its arguments
+func (p *parser) call(t token, name string, args []node) (node, error) {
+	switch name {
+	case "sma", "ema", "rsi", "highest", "lowest", "sum":
+		defaults := map[string]int{"rsi": 14}
+		source, rest, err := p.source(t, name, args, defaultSource(name))
+		if err != nil {
+			return nil, err
+		}
+		period, err := p.periodArg(t, name, rest, 0, defaults[name])
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 1 {
+			return nil, p.errorAt(t, "%s takes a series and a period", name)
+		}
+		transforms := map[string]func(values []float64) []float64{
+			"sma":     func(v []float64) []float64 { return rollingMean(v, period) },
+			"ema":     func(v []float64) []float64 { return emaSeries(v, period) },
+			"rsi":     func(v []float64) []float64 { return rsiSeries(v, period) },
+			"highest": func(v []float64) []float64 { return rolling(v, period, math.Max) },
+			"lowest":  func(v []float64) []float64 { return rolling(v, period, math.Min) },
+			"sum":     func(v []float64) []float64 { return rolling(v, period, func(a, b float64) float64 { return a + b }) },
+		}
+		return &seriesNode{input: source, transform: transforms[name]}, nil
+
+	case "macd", "macd_signal", "macd_hist":
+		source, rest, err := p.source(t, name, args, &priceNode{field: "close"})
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 0 {
+			return nil, p.errorAt(t, "%s takes at most a series", name)
+		}
+		transform := map[string]func(values []float64) []float64{
+			"macd":        func(v []float64) []float64 { line, _ := macdSeries(v); return line },
+			"macd_signal": func(v []float64) []float64 { _, signal := macdSeries(v); return signal },
+			"macd_hist": func(v []float64) []float64 {
+				line, signal := macdSeries(v)
+				hist := make([]float64, len(v))
+				for i := range hist {
+					hist[i] = line[i] - signal[i]
+				}
+				return hist
+			},
+		}[name]
+		return &seriesNode{input: source, transform: transform}, nil
+
+	case "bb_upper", "bb_middle", "bb_lower":
+		source, rest, err := p.source(t, name, args, &priceNode{field: "close"})
+		if err != nil {
+			return nil, err
+		}
+		period, err := p.periodArg(t, name, rest, 0, 20)
+		if err != nil {
+			return nil, err
+		}
+		width := 2.0
+		if len(rest) > 1 {
+			literal, ok := rest[1].(*numberLiteral)
+			if !ok || literal.value <= 0 {
+				return nil, p.errorAt(t, "%s width must be a positive number", name)
+			}
+			width = literal.value
+		}
+		if len(rest) > 2 {
+			return nil, p.errorAt(t, "%s takes a series, a period and a width", name)
+		}
+		side := map[string]float64{"bb_upper": 1, "bb_middle": 0, "bb_lower": -1}[name]
+		return &seriesNode{input: source, transform: func(v []float64) []float64 {
+			return bollingerSeries(v, period, width*side)
+		}}, nil
+
+	case "atr":
+		period, err := p.periodArg(t, name, args, 0, 14)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 1 {
+			return nil, p.errorAt(t, "atr takes a period")
+		}
+		return &atrNode{period: period}, nil
+
+	case "ref", "change":
+		source, rest, err := p.source(t, name, args, &priceNode{field: "close"})
+		if err != nil {
+			return nil, err
+		}
+		bars, err := p.periodArg(t, name, rest, 0, 1)
+		if err != nil {
+			return nil, err
+		}
+		if len(rest) > 1 {
+			return nil, p.errorAt(t, "%s takes a series and a number of bars", name)
+		}
+		past := &refNode{operand: source, bars: bars}
+		if name == "ref" {
+			return past, nil
+		}
+		return &mathNode{args: []numberNode{source, past}, fn: func(v []float64) float64 {
+			if v[1] == 0 {
+				return math.NaN()
+			}
+			return (v[0] - v[1]) / v[1] * 100
+		}}, nil
+
+	case "abs", "min", "max":
+		want := map[string]int{"abs": 1, "min": 2, "max": 2}[name]
+		if len(args) != want {
+			return nil, p.errorAt(t, "%s takes %d numbers", name, want)
+		}
+		numbers, err := p.numbers(t, name, args)
+		if err != nil {
+			return nil, err
+		}
+		fn := map[string]func(v []float64) float64{
+			"abs": func(v []float64) float64 { return math.Abs(v[0]) },
+			"min": func(v []float64) float64 { return math.Min(v[0], v[1]) },
+			"max": func(v []float64) float64 { return math.Max(v[0], v[1]) },
+		}[name]
+		return &mathNode{args: numbers, fn: fn}, nil
+
+	case "crosses_above", "crosses_below":
+		if len(args) != 2 {
+			return nil, p.errorAt(t, "%s takes two numbers", name)
+		}
+		numbers, err := p.numbers(t, name, args)
+		if err != nil {
+			return nil, err
+		}
+		return &crossNode{above: name == "crosses_above", a: numbers[0], b: numbers[1]}, nil
+
+	case "count", "bars_since":
+		if len(args) == 0 {
+			return nil, p.errorAt(t, "%s needs a condition", name)
+		}
+		cond, ok := args[0].(boolNode)
+		if !ok {
+			return nil, p.errorAt(t, "%s needs a condition, got %s", name, describe(args[0]))
+		}
+		if name == "bars_since" {
+			if len(args) > 1 {
+				return nil, p.errorAt(t, "bars_since takes a condition")
+			}
+			return &barsSinceNode{cond: cond}, nil
+		}
+		window, err := p.periodArg(t, name, args, 1, 0)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 2 {
+			return nil, p.errorAt(t, "count takes a condition and a number of bars")
+		}
+		return &countNode{cond: cond, window: window}, nil
+
+	case "pattern", "event":
+		if len(args) == 0 {
+			return nil, p.errorAt(t, "%s needs a name", name)
+		}
+		label, ok := args[0].(*stringLiteral)
+		if !ok {
+			return nil, p.errorAt(t, "%s needs a name, got %s", name, describe(args[0]))
+		}
+		if name == "pattern" {
+			if len(args) > 1 {
+				return nil, p.errorAt(t, "pattern takes a name")
+			}
+			return &patternNode{name: label.value}, nil
+		}
+		window, err := p.periodArg(t, name, args, 1, 10)
+		if err != nil {
+			return nil, err
+		}
+		if len(args) > 2 {
+			return nil, p.errorAt(t, "event takes a name and a number of bars")
+		}
+		return &eventNode{name: label.value, window: window}, nil
+	}
+	return nil, p.errorAt(t, "unknown function %s", name)
+}
+
+// defaultSource is the series a function reads when none is given: the highs
+// for highest, the lows for lowest and the closes otherwise
+func defaultSource(name string) numberNode {
+	switch name {
+	case "highest":
+		return &priceNode{field: "high"}
+	case "lowest":
+		return &priceNode{field: "low"}
+	}
+	return &priceNode{field: "close"}
+}
+
+// source splits an optional leading series argument from the rest. A leading
+// number literal is a period, not a series, so sma(20) averages the closes
+func (p *parser) source(t token, name string, args []node, fallback numberNode) (numberNode, []node, error) {
+	if len(args) == 0 {
+		return fallback, args, nil
+	}
+	if _, ok := args[0].(*numberLiteral); ok {
+		return fallback, args, nil
+	}
+	series, ok := args[0].(numberNode)
+	if !ok {
+		return nil, nil, p.errorAt(t, "%s needs a number series, got %s", name, describe(args[0]))
+	}
+	return series, args[1:], nil
+}
+
+// periodArg reads args[index] as a whole number of bars from 1 to MaxBars, or
+// returns fallback when it is missing. A zero fallback makes the argument required
+func (p *parser) periodArg(t token, name string, args []node, index, fallback int) (int, error) {
+	if index >= len(args) {
+		if fallback == 0 {
+			return 0, p.errorAt(t, "%s needs a number of bars", name)
+		}
+		return fallback, nil
+	}
+	literal, ok := args[index].(*numberLiteral)
+	if !ok || literal.value < 1 || literal.value != math.Trunc(literal.value) {
+		return 0, p.errorAt(t, "%s needs a whole number of bars", name)
+	}
+	if literal.value > MaxBars {
+		return 0, p.errorAt(t, "%s takes at most %d bars", name, MaxBars)
+	}
+	return int(literal.value), nil
+}
+
+// numbers checks that every argument is a number
+func (p *parser) numbers(t token, name string, args []node) ([]numberNode, error) {
+	numbers := make([]numberNode, len(args))
+	for i, arg := range args {
+		n, ok := arg.(numberNode)
+		if !ok {
+			return nil, p.errorAt(t, "%s needs numbers, got %s", name, describe(arg))
+		}
+		numbers[i] = n
+	}
+	return numbers, nil
+}
+
+// ============================================================================
+// SERIES TRANSFORMS
+// ============================================================================
+
+// Each transform maps a series to one of the same length. A value is NaN until
+// enough non-NaN inputs precede it
+
+// rolling folds the last period values with fn
+func rolling(values []float64, period int, fn func(a, b float64) float64) []float64 {
+	out := nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		acc := values[i-period+1]
+		for j := i - period + 2; j <= i; j++ {
+			acc = fn(acc, values[j])
+		}
+		out[i] = acc // NaN inputs propagate through + and the math.Max/Min folds
+	}
+	return out
+}
+
+// rollingMean is the simple moving average, as CalculateSMA
+func rollingMean(values []float64, period int) []float64 {
+	out := rolling(values, period, func(a, b float64) float64 { return a + b })
+	for i := range out {
+		out[i] /= float64(period)
+	}
+	return out
+}
+
+// emaSeries seeds the average with the mean of the first period values and
+// smooths from there, as CalculateEMA. A NaN restarts the seeding
+func emaSeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	multiplier := 2.0 / float64(period+1)
+	ema, run, sum := math.NaN(), 0, 0.0
+	for i, v := range values {
+		if math.IsNaN(v) {
+			ema, run, sum = math.NaN(), 0, 0
+			continue
+		}
+		if run < period {
+			run++
+			sum += v
+			if run == period {
+				ema = sum / float64(period)
+				out[i] = ema
+			}
+			continue
+		}
+		ema = (v-ema)*multiplier + ema
+		out[i] = ema
+	}
+	return out
+}
+
+// rsiSeries averages the gains and losses of the last period changes, as
+// CalculateRSI
+func rsiSeries(values []float64, period int) []float64 {
+	out := nanSeries(len(values))
+	for i := period; i < len(values); i++ {
+		gains, losses := 0.0, 0.0
+		for j := i - period + 1; j <= i; j++ {
+			change := values[j] - values[j-1]
+			if change > 0 {
+				gains += change
+			} else {
+				losses -= change
+			}
+		}
+		switch {
+		case math.IsNaN(gains) || math.IsNaN(losses):
+		case losses == 0:
+			out[i] = 100
+		default:
+			out[i] = 100 - 100/(1+gains/losses)
+		}
+	}
+	return out
+}
+
+// macdSeries returns the 12/26 MACD line and its 9-period signal line. Like
+// CalculateMACD, the signal starts from the line's second value
+func macdSeries(values []float64) (line, signal []float64) {
+	fast, slow := emaSeries(values, 12), emaSeries(values, 26)
+	line = make([]float64, len(values))
+	for i := range line {
+		line[i] = fast[i] - slow[i]
+	}
+
+	signalInput := append([]float64(nil), line...)
+	for i, v := range signalInput {
+		if !math.IsNaN(v) {
+			signalInput[i] = math.NaN()
+			break
+		}
+	}
+	return line, emaSeries(signalInput, 9)
+}
+
+// bollingerSeries is the moving average shifted by offset population standard
+// deviations, as CalculateBollingerBands
+func bollingerSeries(values []float64, period int, offset float64) []float64 {
+	middle := rollingMean(values, period)
+	out := nanSeries(len(values))
+	for i := period - 1; i < len(values); i++ {
+		variance := 0.0
+		for j := i - period + 1; j <= i; j++ {
+			variance += (values[j] - middle[i]) * (values[j] - middle[i])
+		}
+		out[i] = middle[i] + offset*math.Sqrt(variance/float64(period))
+	}
+	return out
+}
+
+func nanSeries(n int) []float64 {
+	out := make([]float64, n)
+	for i := range out {
+		out[i] = math.NaN()
+	}
+	return out
+}
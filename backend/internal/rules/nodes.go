@@ -0,0 +1,325 @@
+package rules
+
+import (
+	"math"
+	"stocking-chain/internal/analysis"
+	"strings"
+)
+
+// ============================================================================
+// EXPRESSION NODES
+// ============================================================================
+
+// node is a parsed expression. Every node is one of numberNode, boolNode or
+// stringNode; the parser checks types so evaluation cannot fail. Values are
+// evaluated at a bar index and never read bars after it
+type node interface{}
+
+type numberNode interface {
+	number(c *Context, i int) float64 // NaN when there is not enough history
+}
+
+type boolNode interface {
+	truth(c *Context, i int) bool
+}
+
+type stringNode interface {
+	text(c *Context, i int) string
+}
+
+type numberLiteral struct{ value float64 }
+
+func (n *numberLiteral) number(c *Context, i int) float64 { return n.value }
+
+type stringLiteral struct{ value string }
+
+func (n *stringLiteral) text(c *Context, i int) string { return n.value }
+
+type boolLiteral struct{ value bool }
+
+func (n *boolLiteral) truth(c *Context, i int) bool { return n.value }
+
+// priceNode reads a field of the bar
+type priceNode struct{ field string }
+
+func (n *priceNode) number(c *Context, i int) float64 {
+	if i < 0 || i >= len(c.data) {
+		return math.NaN()
+	}
+	bar := c.data[i]
+	switch n.field {
+	case "open":
+		return bar.Open
+	case "high":
+		return bar.High
+	case "low":
+		return bar.Low
+	case "volume":
+		return float64(bar.Volume)
+	}
+	return bar.Close
+}
+
+type arithmeticNode struct {
+	op          byte
+	left, right numberNode
+}
+
+func (n *arithmeticNode) number(c *Context, i int) float64 {
+	l, r := n.left.number(c, i), n.right.number(c, i)
+	switch n.op {
+	case '+':
+		return l + r
+	case '-':
+		return l - r
+	case '*':
+		return l * r
+	}
+	if r == 0 {
+		return math.NaN()
+	}
+	return l / r
+}
+
+// compareNode compares numbers; any comparison with a missing value is false
+type compareNode struct {
+	op          string
+	left, right numberNode
+}
+
+func (n *compareNode) truth(c *Context, i int) bool {
+	l, r := n.left.number(c, i), n.right.number(c, i)
+	if math.IsNaN(l) || math.IsNaN(r) {
+		return false
+	}
+	switch n.op {
+	case "<":
+		return l < r
+	case "<=":
+		return l <= r
+	case ">":
+		return l > r
+	case ">=":
+		return l >= r
+	case "==":
+		return l == r
+	}
+	return l != r
+}
+
+// compareTextNode compares text case-insensitively
+type compareTextNode struct {
+	equal       bool
+	left, right stringNode
+}
+
+func (n *compareTextNode) truth(c *Context, i int) bool {
+	return strings.EqualFold(n.left.text(c, i), n.right.text(c, i)) == n.equal
+}
+
+type logicalNode struct {
+	or          bool
+	left, right boolNode
+}
+
+func (n *logicalNode) truth(c *Context, i int) bool {
+	if n.or {
+		return n.left.truth(c, i) || n.right.truth(c, i)
+	}
+	return n.left.truth(c, i) && n.right.truth(c, i)
+}
+
+type notNode struct{ operand boolNode }
+
+func (n *notNode) truth(c *Context, i int) bool { return !n.operand.truth(c, i) }
+
+// refNode is the operand's value bars ago: x[n] or ref(x, n)
+type refNode struct {
+	operand numberNode
+	bars    int
+}
+
+func (n *refNode) number(c *Context, i int) float64 {
+	if i-n.bars < 0 {
+		return math.NaN()
+	}
+	return n.operand.number(c, i-n.bars)
+}
+
+// seriesNode applies a transform to the whole input series and caches the
+// result until the data grows. Every transform only looks back, so the value at
+// a bar does not depend on later bars
+type seriesNode struct {
+	input     numberNode
+	transform func(values []float64) []float64
+}
+
+func (n *seriesNode) number(c *Context, i int) float64 {
+	if i < 0 || i >= len(c.data) {
+		return math.NaN()
+	}
+	series := c.series[n]
+	if len(series) < len(c.data) {
+		values := make([]float64, len(c.data))
+		for j := range values {
+			values[j] = n.input.number(c, j)
+		}
+		series = n.transform(values)
+		c.series[n] = series
+	}
+	return series[i]
+}
+
+// atrNode is the average true range, which needs the whole bar
+type atrNode struct{ period int }
+
+func (n *atrNode) number(c *Context, i int) float64 {
+	if i < n.period || i >= len(c.data) {
+		return math.NaN()
+	}
+	return analysis.CalculateATR(c.data[:i+1], n.period)
+}
+
+// mathNode applies a function of one or two numbers
+type mathNode struct {
+	args []numberNode
+	fn   func(args []float64) float64
+}
+
+func (n *mathNode) number(c *Context, i int) float64 {
+	values := make([]float64, len(n.args))
+	for k, arg := range n.args {
+		values[k] = arg.number(c, i)
+		if math.IsNaN(values[k]) {
+			return math.NaN()
+		}
+	}
+	return n.fn(values)
+}
+
+// crossNode is true on the bar a crosses above (or below) b
+type crossNode struct {
+	above bool
+	a, b  numberNode
+}
+
+func (n *crossNode) truth(c *Context, i int) bool {
+	if i < 1 {
+		return false
+	}
+	a, b := n.a.number(c, i), n.b.number(c, i)
+	prevA, prevB := n.a.number(c, i-1), n.b.number(c, i-1)
+	for _, v := range []float64{a, b, prevA, prevB} {
+		if math.IsNaN(v) {
+			return false
+		}
+	}
+	if n.above {
+		return a > b && prevA <= prevB
+	}
+	return a < b && prevA >= prevB
+}
+
+// countNode counts the bars in the last window where cond held
+type countNode struct {
+	cond   boolNode
+	window int
+}
+
+func (n *countNode) number(c *Context, i int) float64 {
+	if i+1 < n.window {
+		return math.NaN()
+	}
+	count := 0
+	for j := i - n.window + 1; j <= i; j++ {
+		if n.cond.truth(c, j) {
+			count++
+		}
+	}
+	return float64(count)
+}
+
+// barsSinceNode is how many bars ago cond last held, 0 on the current bar
+type barsSinceNode struct{ cond boolNode }
+
+func (n *barsSinceNode) number(c *Context, i int) float64 {
+	for j := i; j >= 0; j-- {
+		if n.cond.truth(c, j) {
+			return float64(i - j)
+		}
+	}
+	return math.NaN()
+}
+
+// patternNode is true when a candlestick pattern with this name (or type:
+// "bullish", "bearish") completes on the bar
+type patternNode struct{ name string }
+
+func (n *patternNode) truth(c *Context, i int) bool {
+	for _, pattern := range c.patternsAt(i) {
+		if strings.EqualFold(pattern.Name, n.name) || strings.EqualFold(pattern.Type, n.name) {
+			return true
+		}
+	}
+	return false
+}
+
+// eventNode is true when the Wyckoff analysis at the bar knows of an event with
+// this name dated within the last window bars
+type eventNode struct {
+	name   string
+	window int
+}
+
+func (n *eventNode) truth(c *Context, i int) bool {
+	if i < 0 || i >= len(c.data) {
+		return false
+	}
+	since := c.data[max(0, i-n.window+1)].Date
+	for _, event := range c.wyckoffAt(i).Events {
+		if strings.EqualFold(event.Name, n.name) && !event.Date.Before(since) {
+			return true
+		}
+	}
+	return false
+}
+
+// wyckoffNumberNode reads a numeric field of the Wyckoff analysis at the bar
+type wyckoffNumberNode struct{ field string }
+
+func (n *wyckoffNumberNode) number(c *Context, i int) float64 {
+	if i < 0 || i >= len(c.data) {
+		return math.NaN()
+	}
+	wyckoff := c.wyckoffAt(i)
+	switch n.field {
+	case "phase_confidence":
+		return wyckoff.PhaseConfidence
+	case "range_high":
+		return wyckoff.TradingRange.Max
+	case "range_low":
+		return wyckoff.TradingRange.Min
+	}
+	return wyckoff.RecommendationScore
+}
+
+// wyckoffTextNode reads a text field of the Wyckoff analysis at the bar
+type wyckoffTextNode struct{ field string }
+
+func (n *wyckoffTextNode) text(c *Context, i int) string {
+	if i < 0 || i >= len(c.data) {
+		return ""
+	}
+	wyckoff := c.wyckoffAt(i)
+	switch n.field {
+	case "recommendation":
+		return wyckoff.Recommendation
+	case "schematic":
+		return wyckoff.Schematic
+	case "schematic_phase":
+		return wyckoff.SchematicPhase
+	case "effort_result":
+		return wyckoff.EffortResult
+	}
+	return wyckoff.Phase
+}
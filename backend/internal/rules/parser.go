@@ -0,0 +1,429 @@
+package rules
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ============================================================================
+// LEXER
+// ============================================================================
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp // Operators and punctuation
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int // Byte offset in the source, for error messages
+}
+
+// MaxBars caps every period, window and lookback a rule names: about twenty
+// years of daily bars
+const MaxBars = 5000
+
+// twoCharOps are matched before single-character operators
+var twoCharOps = []string{"<=", ">=", "==", "!=", "&&", "||"}
+
+// tokenize splits a rule into tokens. Identifiers may contain dots
+// (wyckoff.phase); strings use single or double quotes
+func tokenize(src string) ([]token, error) {
+	tokens := []token{}
+	i := 0
+	for i < len(src) {
+		c := rune(src[i])
+		switch {
+		case unicode.IsSpace(c):
+			i++
+
+		case unicode.IsDigit(c) || (c == '.' && i+1 < len(src) && unicode.IsDigit(rune(src[i+1]))):
+			start := i
+			for i < len(src) && (unicode.IsDigit(rune(src[i])) || src[i] == '.') {
+				i++
+			}
+			// Exponent, e.g. 1e-6
+			if j := i + 1; j < len(src) && (src[i] == 'e' || src[i] == 'E') {
+				if (src[j] == '-' || src[j] == '+') && j+1 < len(src) {
+					j++
+				}
+				if unicode.IsDigit(rune(src[j])) {
+					i = j
+					for i < len(src) && unicode.IsDigit(rune(src[i])) {
+						i++
+					}
+				}
+			}
+			tokens = append(tokens, token{kind: tokNumber, text: src[start:i], pos: start})
+
+		case unicode.IsLetter(c) || c == '_':
+			start := i
+			for i < len(src) && (unicode.IsLetter(rune(src[i])) || unicode.IsDigit(rune(src[i])) || src[i] == '_' || src[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokIdent, text: src[start:i], pos: start})
+
+		case c == '"' || c == '\'':
+			start := i
+			i++
+			for i < len(src) && rune(src[i]) != c {
+				i++
+			}
+			if i >= len(src) {
+				return nil, fmt.Errorf("position %d: unterminated string", start)
+			}
+			tokens = append(tokens, token{kind: tokString, text: src[start+1 : i], pos: start})
+			i++
+
+		default:
+			op := ""
+			for _, candidate := range twoCharOps {
+				if strings.HasPrefix(src[i:], candidate) {
+					op = candidate
+					break
+				}
+			}
+			if op == "" && strings.ContainsRune("<>=!+-*/(),[]", c) {
+				op = string(c)
+			}
+			if op == "" {
+				return nil, fmt.Errorf("position %d: unexpected character %q", i, c)
+			}
+			tokens = append(tokens, token{kind: tokOp, text: op, pos: i})
+			i += len(op)
+		}
+	}
+	tokens = append(tokens, token{kind: tokEOF, pos: len(src)})
+	return tokens, nil
+}
+
+// ============================================================================
+// PARSER
+// ============================================================================
+
+// parser is a recursive-descent parser. Precedence, loosest first:
+// OR, AND, NOT, comparison, + -, * /, unary minus, [lookback], primary
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+// keyword reports whether the next token is the operator or case-insensitive word
+func (p *parser) keyword(words ...string) bool {
+	t := p.peek()
+	for _, w := range words {
+		if (t.kind == tokOp && t.text == w) || (t.kind == tokIdent && strings.EqualFold(t.text, w)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) expect(op string) error {
+	t := p.next()
+	if t.kind != tokOp || t.text != op {
+		return p.errorAt(t, "expected %q", op)
+	}
+	return nil
+}
+
+func (p *parser) errorAt(t token, format string, args ...any) error {
+	found := t.text
+	if t.kind == tokEOF {
+		found = "end of rule"
+	}
+	return fmt.Errorf("position %d (%s): %s", t.pos, found, fmt.Sprintf(format, args...))
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("or", "||") {
+		t := p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		l, r, err := bothBool(p, t, left, right)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{or: true, left: l, right: r}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("and", "&&") {
+		t := p.next()
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		l, r, err := bothBool(p, t, left, right)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{left: l, right: r}
+	}
+	return left, nil
+}
+
+func (p *parser) parseNot() (node, error) {
+	if p.keyword("not", "!") {
+		t := p.next()
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		b, ok := operand.(boolNode)
+		if !ok {
+			return nil, p.errorAt(t, "NOT needs a condition")
+		}
+		return &notNode{operand: b}, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if !p.keyword("<", "<=", ">", ">=", "==", "!=", "=") {
+		return left, nil
+	}
+	t := p.next()
+	if t.text == "=" {
+		t.text = "==" // A lone "=" reads as equality
+	}
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	if l, ok := left.(numberNode); ok {
+		if r, ok := right.(numberNode); ok {
+			return &compareNode{op: t.text, left: l, right: r}, nil
+		}
+	}
+	if l, ok := left.(stringNode); ok {
+		if r, ok := right.(stringNode); ok {
+			if t.text != "==" && t.text != "!=" {
+				return nil, p.errorAt(t, "text can only be compared with == or !=")
+			}
+			return &compareTextNode{equal: t.text == "==", left: l, right: r}, nil
+		}
+	}
+	return nil, p.errorAt(t, "cannot compare %s with %s", describe(left), describe(right))
+}
+
+func (p *parser) parseAdditive() (node, error) {
+	left, err := p.parseMultiplicative()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("+", "-") {
+		t := p.next()
+		right, err := p.parseMultiplicative()
+		if err != nil {
+			return nil, err
+		}
+		l, r, err := bothNumbers(p, t, left, right)
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: t.text[0], left: l, right: r}
+	}
+	return left, nil
+}
+
+func (p *parser) parseMultiplicative() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("*", "/") {
+		t := p.next()
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		l, r, err := bothNumbers(p, t, left, right)
+		if err != nil {
+			return nil, err
+		}
+		left = &arithmeticNode{op: t.text[0], left: l, right: r}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.keyword("-") {
+		t := p.next()
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		n, ok := operand.(numberNode)
+		if !ok {
+			return nil, p.errorAt(t, "minus needs a number")
+		}
+		return &arithmeticNode{op: '-', left: &numberLiteral{value: 0}, right: n}, nil
+	}
+	return p.parsePostfix()
+}
+
+// parsePostfix handles x[n], the value of x n bars ago
+func (p *parser) parsePostfix() (node, error) {
+	operand, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	for p.keyword("[") {
+		t := p.next()
+		bars, err := p.parseBars()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+		n, ok := operand.(numberNode)
+		if !ok {
+			return nil, p.errorAt(t, "only numbers can be looked back with [n]")
+		}
+		operand = &refNode{operand: n, bars: bars}
+	}
+	return operand, nil
+}
+
+// parseBars reads a whole number of bars from 0 to MaxBars
+func (p *parser) parseBars() (int, error) {
+	t := p.next()
+	value, err := strconv.ParseFloat(t.text, 64)
+	if t.kind != tokNumber || err != nil || value < 0 || value > MaxBars || value != math.Trunc(value) {
+		return 0, p.errorAt(t, "expected a whole number of bars up to %d", MaxBars)
+	}
+	return int(value), nil
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		value, err := strconv.ParseFloat(t.text, 64)
+		if err != nil {
+			return nil, p.errorAt(t, "invalid number")
+		}
+		return &numberLiteral{value: value}, nil
+
+	case tokString:
+		return &stringLiteral{value: t.text}, nil
+
+	case tokIdent:
+		name := strings.ToLower(t.text)
+		if p.keyword("(") {
+			p.next()
+			args := []node{}
+			if !p.keyword(")") {
+				for {
+					arg, err := p.parseOr()
+					if err != nil {
+						return nil, err
+					}
+					args = append(args, arg)
+					if !p.keyword(",") {
+						break
+					}
+					p.next()
+				}
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return p.call(t, name, args)
+		}
+		if n := variable(name); n != nil {
+			return n, nil
+		}
+		// Anything else is a bare word, e.g. accumulation in wyckoff.phase == accumulation
+		if strings.Contains(name, ".") {
+			return nil, p.errorAt(t, "unknown field %s", t.text)
+		}
+		return &stringLiteral{value: t.text}, nil
+
+	case tokOp:
+		if t.text == "(" {
+			inner, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		}
+	}
+	return nil, p.errorAt(t, "expected a value")
+}
+
+func bothNumbers(p *parser, t token, left, right node) (numberNode, numberNode, error) {
+	l, lok := left.(numberNode)
+	r, rok := right.(numberNode)
+	if !lok || !rok {
+		return nil, nil, p.errorAt(t, "%q needs numbers, got %s and %s", t.text, describe(left), describe(right))
+	}
+	return l, r, nil
+}
+
+func bothBool(p *parser, t token, left, right node) (boolNode, boolNode, error) {
+	l, lok := left.(boolNode)
+	r, rok := right.(boolNode)
+	if !lok || !rok {
+		return nil, nil, p.errorAt(t, "%s needs conditions, got %s and %s", strings.ToUpper(t.text), describe(left), describe(right))
+	}
+	return l, r, nil
+}
+
+// describe names the type of a node for error messages
+func describe(n node) string {
+	switch v := n.(type) {
+	case *stringLiteral:
+		return fmt.Sprintf("text %q", v.value)
+	case numberNode:
+		return "a number"
+	case boolNode:
+		return "a condition"
+	case stringNode:
+		return "text"
+	}
+	return "a value"
+}
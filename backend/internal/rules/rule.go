@@ -0,0 +1,190 @@
+package rules
+
+import (
+	"fmt"
+	"stocking-chain/internal/analysis"
+	"stocking-chain/internal/models"
+	"strings"
+)
+
+// ============================================================================
+// RULES
+// ============================================================================
+
+// Rule is a parsed condition such as
+//
+//	RSI(14) < 30 AND close > SMA(200) AND wyckoff.phase == accumulation
+type Rule struct {
+	Source string
+	root   boolNode
+}
+
+// Parse compiles a rule. The rule must be a condition, not a bare number or text
+func Parse(source string) (*Rule, error) {
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 1 {
+		return nil, fmt.Errorf("empty rule")
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokEOF {
+		return nil, p.errorAt(t, "unexpected input")
+	}
+	condition, ok := root.(boolNode)
+	if !ok {
+		return nil, fmt.Errorf("rule must be a condition, got %s", describe(root))
+	}
+	return &Rule{Source: strings.TrimSpace(source), root: condition}, nil
+}
+
+// At reports whether the rule holds at bar i of the context's data
+func (r *Rule) At(c *Context, i int) bool {
+	if i < 0 || i >= len(c.data) {
+		return false
+	}
+	return r.root.truth(c, i)
+}
+
+// Evaluate reports whether the rule holds at the last bar of data
+func (r *Rule) Evaluate(data []models.StockData) bool {
+	return r.At(NewContext(data), len(data)-1)
+}
+
+// ============================================================================
+// EVALUATION CONTEXT
+// ============================================================================
+
+// Context holds the bars rules are evaluated over and caches the indicator
+// series, Wyckoff analyses and candlestick patterns they read. Several rules
+// can share a context. Every value at a bar uses only the bars up to it, so a
+// context over the full history has no lookahead
+type Context struct {
+	WyckoffWindow int // Bars each per-bar Wyckoff analysis sees
+
+	data     []models.StockData
+	series   map[*seriesNode][]float64
+	wyckoff  map[int]models.WyckoffAnalysis
+	patterns map[int][]models.CandlestickPattern
+}
+
+// NewContext evaluates over data with a 200-bar Wyckoff window
+func NewContext(data []models.StockData) *Context {
+	return &Context{
+		WyckoffWindow: 200,
+		data:          data,
+		series:        map[*seriesNode][]float64{},
+		wyckoff:       map[int]models.WyckoffAnalysis{},
+		patterns:      map[int][]models.CandlestickPattern{},
+	}
+}
+
+// SetData replaces the bars with a longer series that starts with the same
+// bars, as a backtest sees them one at a time. The caches stay valid
+func (c *Context) SetData(data []models.StockData) {
+	c.data = data
+}
+
+// Len returns the number of bars
+func (c *Context) Len() int {
+	return len(c.data)
+}
+
+// wyckoffAt analyzes the WyckoffWindow bars ending at bar i
+func (c *Context) wyckoffAt(i int) models.WyckoffAnalysis {
+	if wyckoff, ok := c.wyckoff[i]; ok {
+		return wyckoff
+	}
+	start := 0
+	if c.WyckoffWindow > 0 {
+		start = max(0, i+1-c.WyckoffWindow)
+	}
+	wyckoff := analysis.AnalyzeWyckoff(c.data[start : i+1])
+	c.wyckoff[i] = wyckoff
+	return wyckoff
+}
+
+// patternsAt detects the candlestick patterns completing on bar i
+func (c *Context) patternsAt(i int) []models.CandlestickPattern {
+	if patterns, ok := c.patterns[i]; ok {
+		return patterns
+	}
+	patterns := analysis.DetectCandlestickPatterns(c.data[:i+1])
+	c.patterns[i] = patterns
+	return patterns
+}
+
+// ============================================================================
+// CUSTOM SIGNALS
+// ============================================================================
+
+// SignalLookback is how many bars back EvaluateSignals counts triggers
+const SignalLookback = 60
+
+// Signal is a named rule reported alongside the analysis
+type Signal struct {
+	Name string `json:"name"`
+	Rule string `json:"rule"`
+}
+
+// ParseSignals compiles every signal's rule; the error names the failing signal
+func ParseSignals(signals []Signal) ([]*Rule, error) {
+	parsed := make([]*Rule, len(signals))
+	for i, signal := range signals {
+		rule, err := Parse(signal.Rule)
+		if err != nil {
+			return nil, fmt.Errorf("signal %q: %w", signalName(signal, i), err)
+		}
+		parsed[i] = rule
+	}
+	return parsed, nil
+}
+
+// EvaluateSignals checks each signal at the last bar and counts how often it
+// fired over the last SignalLookback bars. A signal that fails to parse is an error
+func EvaluateSignals(signals []Signal, data []models.StockData) ([]models.CustomSignal, error) {
+	parsed, err := ParseSignals(signals)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx := NewContext(data)
+	last := len(data) - 1
+	results := make([]models.CustomSignal, len(signals))
+	for i, rule := range parsed {
+		result := models.CustomSignal{
+			Name:     signalName(signals[i], i),
+			Rule:     rule.Source,
+			Lookback: SignalLookback,
+		}
+		for j := last; j >= 0 && j > last-SignalLookback; j-- {
+			if !rule.At(ctx, j) {
+				continue
+			}
+			if result.TriggerCount == 0 {
+				date := data[j].Date
+				result.LastTriggered = &date
+				bars := last - j
+				result.BarsSince = &bars
+			}
+			result.TriggerCount++
+		}
+		result.Triggered = result.BarsSince != nil && *result.BarsSince == 0
+		results[i] = result
+	}
+	return results, nil
+}
+
+// signalName defaults an unnamed signal to its position
+func signalName(signal Signal, i int) string {
+	if signal.Name != "" {
+		return signal.Name
+	}
+	return fmt.Sprintf("signal_%d", i+1)
+}
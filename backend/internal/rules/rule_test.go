@@ -0,0 +1,106 @@
+package rules
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"stocking-chain/internal/models"
+)
+
+// testBars is a gently rising series of n daily bars
+func testBars(n int) []models.StockData {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	data := make([]models.StockData, n)
+	for i := range data {
+		price := 10000 + float64(i%7)*100 + float64(i)*10
+		data[i] = models.StockData{
+			Date:   start.AddDate(0, 0, i),
+			Open:   price,
+			High:   price * 1.01,
+			Low:    price * 0.99,
+			Close:  price,
+			Volume: 100000,
+		}
+	}
+	return data
+}
+
+func TestParseRejectsBadBarCounts(t *testing.T) {
+	cases := []struct {
+		rule string
+		want string
+	}{
+		{"rsi(close, 1e30) > 0", "at most"},
+		{"atr(1e30) > 0", "at most"},
+		{"count(close > 0, 1e30) > 0", "at most"},
+		{"event('Spring', 1e30)", "at most"},
+		{"sma(5001) > 0", "at most"},
+		{"close[1e30] > 0", "whole number of bars"},
+		{"close[5001] > 0", "whole number of bars"},
+		{"close[1.5] > 0", "whole number of bars"},
+		{"sma(0) > 0", "whole number"},
+		{"sma(-3) > 0", "needs a number of bars"},
+		{"sma(2.5) > 0", "whole number"},
+		{"ref(close, 1e-6) > 0", "whole number"},
+		{"count(close > 0) > 0", "needs a number of bars"},
+		{"sma(close, 1e999) > 0", "invalid number"},
+		{"close >", "expected a value"},
+		{"close", "must be a condition"},
+		{"", "empty rule"},
+	}
+	for _, tc := range cases {
+		_, err := Parse(tc.rule)
+		if err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error containing %q", tc.rule, tc.want)
+			continue
+		}
+		if !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("Parse(%q) error %q, want it to contain %q", tc.rule, err, tc.want)
+		}
+	}
+}
+
+func TestEvaluatePeriodsLongerThanData(t *testing.T) {
+	data := testBars(30)
+	for _, source := range []string{
+		"rsi(close, 5000) > 0",
+		"atr(5000) > 0",
+		"count(close > 0, 5000) > 0",
+		"highest(5000) > 0",
+		"close[5000] > 0",
+		"change(close, 5000) > 0",
+	} {
+		rule, err := Parse(source)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", source, err)
+		}
+		if rule.Evaluate(data) {
+			t.Errorf("%q held on %d bars, want false while the values are missing", source, len(data))
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	data := testBars(60)
+	cases := []struct {
+		rule string
+		want bool
+	}{
+		{"close > sma(20)", true},
+		{"close > close[1] OR close <= close[1]", true},
+		{"count(close > 0, 10) == 10", true},
+		{"rsi(14) >= 0 AND rsi(14) <= 100", true},
+		{"NOT close > 0", false},
+		{"bars_since(close > 1e9) >= 0", false},
+	}
+	for _, tc := range cases {
+		rule, err := Parse(tc.rule)
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.rule, err)
+		}
+		if got := rule.Evaluate(data); got != tc.want {
+			t.Errorf("%q = %v, want %v", tc.rule, got, tc.want)
+		}
+	}
+}
@@ -13,6 +13,9 @@ import (
 
 const (
 	YAHOO_BASE_URL = "https://query1.finance.yahoo.com"
+
+	// VN_INDEX_SYMBOL is the Yahoo Finance ticker for the VN-Index
+	VN_INDEX_SYMBOL = "^VNINDEX.VN"
 )
 
 type Client struct {
@@ -101,9 +104,13 @@ func NewClient(apiKey string) *Client {
 	}
 }
 
-// formatSymbol adds .VN suffix for Vietnamese stocks if not already present
+// formatSymbol adds .VN suffix for Vietnamese stocks if not already present.
+// Index tickers (starting with ^) are passed through unchanged
 func formatSymbol(symbol string) string {
 	symbol = strings.ToUpper(strings.TrimSpace(symbol))
+	if strings.HasPrefix(symbol, "^") {
+		return symbol
+	}
 	if !strings.HasSuffix(symbol, ".VN") {
 		return symbol + ".VN"
 	}
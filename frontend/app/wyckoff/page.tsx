@@ -87,7 +87,7 @@ function WyckoffContent() {
                   <ul className="list-disc list-inside space-y-2 text-sm text-gray-400">
                     <li><strong className="text-gray-300">Effort vs Result:</strong> Compare volume (effort) to price movement (result)</li>
                     <li><strong className="text-gray-300">Trading Range:</strong> Consolidation zones where accumulation/distribution occurs</li>
-                    <li><strong className="text-gray-300">Wyckoff Events:</strong> Spring, Upthrust, Climax, Sign of Strength/Weakness</li>
+                    <li><strong className="text-gray-300">Wyckoff Events:</strong> PS/PSY, Climax, AR, ST, Spring, Test of Spring, Upthrust, UTAD, Sign of Strength/Weakness, LPS/LPSY, JAC and Back-Up</li>
                   </ul>
                 </div>
               </div>
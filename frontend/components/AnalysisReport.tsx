@@ -3,6 +3,9 @@
 import { useState } from 'react';
 import { AnalysisReport as ReportType, CandlestickPattern } from '@/types';
 import StockChart from './StockChart';
+import ScoreWaterfall from './ScoreWaterfall';
+import TradePlanCard from './TradePlanCard';
+import ForecastCard from './ForecastCard';
 
 type Timeframe = 'daily' | 'weekly' | 'monthly';
 
@@ -89,9 +92,65 @@ export default function AnalysisReport({ report }: AnalysisReportProps) {
             <p className="text-sm font-medium">Recommendation</p>
             <p className="text-2xl font-bold uppercase">{report.recommendation}</p>
             <p className="text-sm">Score: {(report.recommendation_score * 100).toFixed(0)}%</p>
+            <p className="text-xs opacity-75">Model: {report.scoring_model}</p>
           </div>
         </div>
 
+        {report.score_breakdown && report.score_breakdown.contributions.length > 0 && (
+          <div className="mb-8">
+            <ScoreWaterfall breakdown={report.score_breakdown} title={`Why ${report.recommendation.toUpperCase()}`} />
+          </div>
+        )}
+
+        {report.model_comparison && report.model_comparison.length > 1 && (
+          <div className="mb-8">
+            <h3 className="text-lg font-semibold text-gray-800 dark:text-white mb-3">Scoring Models</h3>
+            <div className="grid grid-cols-2 md:grid-cols-4 gap-3">
+              {report.model_comparison.map((model) => (
+                <div
+                  key={model.model}
+                  className={`p-3 rounded-lg border ${getRecommendationColor(model.recommendation)}`}
+                  title={model.description}
+                >
+                  <p className="text-sm font-medium">{model.model}</p>
+                  <p className="text-lg font-bold uppercase">{model.recommendation}</p>
+                  <p className="text-xs">Score: {(model.score * 100).toFixed(0)}%</p>
+                </div>
+              ))}
+            </div>
+          </div>
+        )}
+
+        {report.custom_signals && report.custom_signals.length > 0 && (
+          <div className="mb-8">
+            <h3 className="text-lg font-semibold text-gray-800 dark:text-white mb-3">Custom Signals</h3>
+            <div className="space-y-2">
+              {report.custom_signals.map((signal) => (
+                <div
+                  key={signal.name}
+                  className={`p-3 rounded-lg border flex justify-between items-center ${
+                    signal.triggered
+                      ? 'bg-green-50 dark:bg-green-900/20 border-green-200'
+                      : 'bg-gray-50 dark:bg-gray-700/50 border-gray-200 dark:border-gray-600'
+                  }`}
+                >
+                  <div>
+                    <p className="text-sm font-medium text-gray-800 dark:text-gray-200">{signal.name}</p>
+                    <p className="text-xs font-mono text-gray-500 dark:text-gray-400">{signal.rule}</p>
+                  </div>
+                  <div className="text-right text-xs text-gray-600 dark:text-gray-400">
+                    <p className="font-bold uppercase">{signal.triggered ? 'Triggered' : 'Not triggered'}</p>
+                    <p>
+                      {signal.bars_since !== undefined ? `${signal.bars_since} bars ago · ` : ''}
+                      {signal.trigger_count}/{signal.lookback} bars
+                    </p>
+                  </div>
+                </div>
+              ))}
+            </div>
+          </div>
+        )}
+
         <div className="grid grid-cols-1 md:grid-cols-3 gap-6 mb-8">
           <div className="bg-green-50 dark:bg-green-900/20 p-6 rounded-lg border border-green-200">
             <h3 className="text-lg font-semibold text-green-800 dark:text-green-300 mb-3">Buy Range</h3>
@@ -118,6 +177,10 @@ export default function AnalysisReport({ report }: AnalysisReportProps) {
           </div>
         </div>
 
+        {report.trade_plan && <TradePlanCard plan={report.trade_plan} />}
+
+        {report.forecast && <ForecastCard forecast={report.forecast} />}
+
         <div className="border-t pt-6">
           <h3 className="text-xl font-semibold text-gray-800 dark:text-white mb-4">Trend Analysis</h3>
           <div className="grid grid-cols-2 md:grid-cols-3 gap-4">
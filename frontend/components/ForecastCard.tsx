@@ -0,0 +1,131 @@
+'use client';
+
+import { useState } from 'react';
+import { PriceForecast } from '@/types';
+
+interface ForecastCardProps {
+  forecast: PriceForecast;
+}
+
+const formatVND = (value: number) => new Intl.NumberFormat('vi-VN').format(Math.round(value));
+
+const formatLabel = (label: string) =>
+  label.replace(/_/g, ' ').replace(/\b\w/g, (c) => c.toUpperCase());
+
+export default function ForecastCard({ forecast }: ForecastCardProps) {
+  const [selected, setSelected] = useState(0);
+  if (forecast.methods.length === 0) {
+    return null;
+  }
+  const method = forecast.methods[Math.min(selected, forecast.methods.length - 1)];
+
+  // A handful of days across the horizon
+  const days = Array.from(new Set([1, 5, 10, forecast.horizon].filter((d) => d <= forecast.horizon)));
+  const bands = method.bands.filter((band) => days.includes(band.day));
+
+  return (
+    <div className="border-t pt-6 mb-8">
+      <div className="flex justify-between items-center mb-4">
+        <h3 className="text-xl font-semibold text-gray-800 dark:text-white">{forecast.horizon}-Day Forecast</h3>
+        <div className="flex gap-2">
+          {forecast.methods.map((m, i) => (
+            <button
+              key={m.method}
+              onClick={() => setSelected(i)}
+              className={`px-3 py-1 text-sm rounded ${
+                m.method === method.method
+                  ? 'bg-blue-600 text-white'
+                  : 'bg-gray-100 text-gray-700 dark:bg-gray-700 dark:text-gray-300'
+              }`}
+            >
+              {formatLabel(m.method)}
+            </button>
+          ))}
+        </div>
+      </div>
+
+      <p className="text-sm text-gray-500 dark:text-gray-400 mb-4">
+        Volatility {method.daily_volatility.toFixed(2)}% daily, {method.annual_volatility.toFixed(1)}% annualized, ±
+        {method.horizon_volatility.toFixed(1)}% over the horizon
+        {method.garch &&
+          ` · GARCH α ${method.garch.alpha.toFixed(3)}, β ${method.garch.beta.toFixed(3)}, next day ${method.garch.next_volatility.toFixed(2)}%`}
+      </p>
+
+      <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
+        <table className="w-full text-sm">
+          <thead>
+            <tr className="text-left text-gray-500 dark:text-gray-400">
+              <th className="pb-2">Day</th>
+              <th className="pb-2 text-right">5%</th>
+              <th className="pb-2 text-right">25%</th>
+              <th className="pb-2 text-right">Median</th>
+              <th className="pb-2 text-right">75%</th>
+              <th className="pb-2 text-right">95%</th>
+            </tr>
+          </thead>
+          <tbody className="font-mono text-gray-800 dark:text-gray-200">
+            {bands.map((band) => (
+              <tr key={band.day} className="border-t border-gray-100 dark:border-gray-700">
+                <td className="py-2 font-sans">{band.day}</td>
+                <td className="py-2 text-right text-red-600 dark:text-red-400">{formatVND(band.p5)}</td>
+                <td className="py-2 text-right">{formatVND(band.p25)}</td>
+                <td className="py-2 text-right font-semibold">{formatVND(band.p50)}</td>
+                <td className="py-2 text-right">{formatVND(band.p75)}</td>
+                <td className="py-2 text-right text-emerald-600 dark:text-emerald-400">{formatVND(band.p95)}</td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+
+        <table className="w-full text-sm">
+          <thead>
+            <tr className="text-left text-gray-500 dark:text-gray-400">
+              <th className="pb-2">Level</th>
+              <th className="pb-2 text-right">Price</th>
+              <th className="pb-2 text-right">Touch</th>
+              <th className="pb-2 text-right">Close Beyond</th>
+            </tr>
+          </thead>
+          <tbody className="text-gray-800 dark:text-gray-200">
+            {method.levels.map((level) => (
+              <tr key={level.label} className="border-t border-gray-100 dark:border-gray-700">
+                <td className="py-2">{formatLabel(level.label)}</td>
+                <td className="py-2 text-right font-mono">
+                  {formatVND(level.price)}
+                  <span className="block text-xs text-gray-500">
+                    {level.distance_percent > 0 ? '+' : ''}
+                    {level.distance_percent.toFixed(1)}%
+                  </span>
+                </td>
+                <td className="py-2 text-right">
+                  <div className="flex items-center justify-end gap-2">
+                    <div className="w-16 h-2 rounded bg-gray-100 dark:bg-gray-700">
+                      <div
+                        className={`h-2 rounded ${level.direction === 'below' ? 'bg-red-500' : 'bg-emerald-500'}`}
+                        style={{ width: `${level.touch_probability}%` }}
+                      />
+                    </div>
+                    {level.touch_probability.toFixed(0)}%
+                  </div>
+                </td>
+                <td className="py-2 text-right">
+                  {level.end_inside_probability !== undefined
+                    ? `${level.end_inside_probability.toFixed(0)}% inside`
+                    : `${level.end_beyond_probability.toFixed(0)}%`}
+                </td>
+              </tr>
+            ))}
+          </tbody>
+        </table>
+      </div>
+
+      {forecast.notes.length > 0 && (
+        <ul className="mt-4 text-sm text-amber-700 dark:text-amber-400 list-disc list-inside">
+          {forecast.notes.map((note) => (
+            <li key={note}>{note}</li>
+          ))}
+        </ul>
+      )}
+    </div>
+  );
+}
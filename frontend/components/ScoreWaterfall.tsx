@@ -0,0 +1,66 @@
+'use client';
+
+import { ScoreBreakdown } from '@/types';
+
+interface ScoreWaterfallProps {
+  breakdown: ScoreBreakdown;
+  title?: string;
+  dark?: boolean;
+}
+
+const formatName = (name: string) =>
+  name.replace(/_/g, ' ').replace(/\b\w/g, (c) => c.toUpperCase());
+
+export default function ScoreWaterfall({ breakdown, title = 'Score Breakdown', dark = false }: ScoreWaterfallProps) {
+  if (!breakdown || breakdown.contributions.length === 0 || breakdown.normalizer <= 0) {
+    return null;
+  }
+
+  // Running total after each contribution, in normalized score units
+  let running = 0;
+  const steps = breakdown.contributions.map((c) => {
+    const start = running;
+    running += c.contribution / breakdown.normalizer;
+    return { ...c, start, end: running };
+  });
+  const extent = Math.max(1, ...steps.map((s) => Math.abs(s.end)), ...steps.map((s) => Math.abs(s.start)));
+  const toPercent = (value: number) => ((value + extent) / (2 * extent)) * 100;
+
+  const textMuted = dark ? 'text-gray-400' : 'text-gray-500 dark:text-gray-400';
+  const textMain = dark ? 'text-gray-200' : 'text-gray-800 dark:text-gray-200';
+  const track = dark ? 'bg-gray-800' : 'bg-gray-100 dark:bg-gray-700';
+
+  return (
+    <div>
+      <h3 className={`text-lg font-semibold mb-3 ${dark ? 'text-white' : 'text-gray-800 dark:text-white'}`}>{title}</h3>
+      <div className="space-y-2">
+        {steps.map((step, i) => {
+          const left = toPercent(Math.min(step.start, step.end));
+          const width = Math.max(Math.abs(step.end - step.start) / (2 * extent) * 100, step.met ? 0.5 : 0);
+          return (
+            <div key={`${step.name}-${i}`} className="grid grid-cols-12 gap-2 items-center text-sm" title={step.condition}>
+              <div className="col-span-4">
+                <p className={`font-medium ${textMain}`}>{formatName(step.name)}</p>
+                <p className={`text-xs truncate ${textMuted}`}>{step.condition}</p>
+              </div>
+              <div className={`col-span-6 relative h-4 rounded ${track}`}>
+                <div className="absolute top-0 bottom-0 w-px bg-gray-400" style={{ left: `${toPercent(0)}%` }} />
+                <div
+                  className={`absolute top-0 bottom-0 rounded ${step.contribution >= 0 ? 'bg-emerald-500' : 'bg-red-500'}`}
+                  style={{ left: `${left}%`, width: `${width}%` }}
+                />
+              </div>
+              <div className={`col-span-2 text-right font-mono ${step.contribution > 0 ? 'text-emerald-500' : step.contribution < 0 ? 'text-red-500' : textMuted}`}>
+                {step.contribution > 0 ? '+' : ''}{step.contribution.toFixed(2)}
+                <span className={`block text-xs ${textMuted}`}>{step.weight} × {step.value.toFixed(2)}</span>
+              </div>
+            </div>
+          );
+        })}
+      </div>
+      <p className={`mt-3 text-sm ${textMuted}`}>
+        Total {breakdown.raw_score.toFixed(2)} ÷ {breakdown.normalizer} = {(breakdown.score * 100).toFixed(0)}% (clamped to ±100%)
+      </p>
+    </div>
+  );
+}
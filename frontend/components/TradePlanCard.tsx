@@ -0,0 +1,111 @@
+'use client';
+
+import { TradePlan, TradeTier } from '@/types';
+
+interface TradePlanCardProps {
+  plan: TradePlan;
+}
+
+const formatVND = (value: number) => new Intl.NumberFormat('vi-VN').format(Math.round(value));
+
+const formatLabel = (label: string) =>
+  label.replace(/_/g, ' ').replace(/\b\w/g, (c) => c.toUpperCase());
+
+function TierRow({ tier, showReward }: { tier: TradeTier; showReward: boolean }) {
+  return (
+    <tr className="border-t border-gray-100 dark:border-gray-700">
+      <td className="py-2 text-gray-800 dark:text-gray-200">{formatLabel(tier.label)}</td>
+      <td className="py-2 text-right font-mono">{formatVND(tier.price)}</td>
+      <td className="py-2 text-right">{(tier.fraction * 100).toFixed(0)}%</td>
+      <td className="py-2 text-right">{tier.lots} lots ({formatVND(tier.shares)})</td>
+      {showReward && (
+        <td className="py-2 text-right font-mono text-emerald-600 dark:text-emerald-400">
+          {tier.reward_risk !== undefined ? `${tier.reward_risk.toFixed(2)}R` : '-'}
+        </td>
+      )}
+    </tr>
+  );
+}
+
+export default function TradePlanCard({ plan }: TradePlanCardProps) {
+  return (
+    <div className="border-t pt-6 mb-8">
+      <h3 className="text-xl font-semibold text-gray-800 dark:text-white mb-4">Trade Plan</h3>
+
+      <div className="grid grid-cols-2 md:grid-cols-4 gap-4 mb-6">
+        <div>
+          <p className="text-sm text-gray-600 dark:text-gray-400">Position</p>
+          <p className="text-lg font-bold text-gray-800 dark:text-white">{plan.lots} lots</p>
+          <p className="text-xs text-gray-500">{formatVND(plan.position_value)} VND ({plan.position_percent.toFixed(1)}%)</p>
+        </div>
+        <div>
+          <p className="text-sm text-gray-600 dark:text-gray-400">Stop-Loss</p>
+          <p className="text-lg font-bold text-red-600 dark:text-red-400">{plan.stop_loss > 0 ? formatVND(plan.stop_loss) : '-'}</p>
+          <p className="text-xs text-gray-500">{plan.stop_method ?? 'none'}</p>
+        </div>
+        <div>
+          <p className="text-sm text-gray-600 dark:text-gray-400">Risk</p>
+          <p className="text-lg font-bold text-gray-800 dark:text-white">{formatVND(plan.risk_amount)} VND</p>
+          <p className="text-xs text-gray-500">Budget {formatVND(plan.risk_budget)} ({plan.risk_percent}% of equity)</p>
+        </div>
+        <div>
+          <p className="text-sm text-gray-600 dark:text-gray-400">Reward : Risk</p>
+          <p className="text-lg font-bold text-emerald-600 dark:text-emerald-400">{plan.reward_risk.toFixed(2)}</p>
+          <p className="text-xs text-gray-500">Avg entry {formatVND(plan.average_entry)}</p>
+        </div>
+      </div>
+
+      <div className="grid grid-cols-1 md:grid-cols-2 gap-6">
+        <table className="w-full text-sm">
+          <thead>
+            <tr className="text-left text-gray-500 dark:text-gray-400">
+              <th className="pb-2">Scale In</th>
+              <th className="pb-2 text-right">Price</th>
+              <th className="pb-2 text-right">Share</th>
+              <th className="pb-2 text-right">Size</th>
+            </tr>
+          </thead>
+          <tbody>
+            {plan.entries.map((tier) => (
+              <TierRow key={tier.label} tier={tier} showReward={false} />
+            ))}
+          </tbody>
+        </table>
+        <table className="w-full text-sm">
+          <thead>
+            <tr className="text-left text-gray-500 dark:text-gray-400">
+              <th className="pb-2">Scale Out</th>
+              <th className="pb-2 text-right">Price</th>
+              <th className="pb-2 text-right">Share</th>
+              <th className="pb-2 text-right">Size</th>
+              <th className="pb-2 text-right">R:R</th>
+            </tr>
+          </thead>
+          <tbody>
+            {plan.targets.map((tier) => (
+              <TierRow key={tier.label} tier={tier} showReward />
+            ))}
+          </tbody>
+        </table>
+      </div>
+
+      {plan.stops.length > 0 && (
+        <div className="mt-4 text-xs text-gray-500 dark:text-gray-400 space-y-1">
+          {plan.stops.map((stop) => (
+            <p key={stop.method}>
+              <span className="font-medium uppercase">{stop.method}</span>: {formatVND(stop.price)} ({stop.distance_percent.toFixed(1)}%) – {stop.reason}
+            </p>
+          ))}
+        </div>
+      )}
+
+      {plan.notes.length > 0 && (
+        <ul className="mt-4 text-sm text-amber-700 dark:text-amber-400 list-disc list-inside">
+          {plan.notes.map((note) => (
+            <li key={note}>{note}</li>
+          ))}
+        </ul>
+      )}
+    </div>
+  );
+}
@@ -102,6 +102,24 @@ export default function EventsTimeline({ events }: EventsTimelineProps) {
                     <span className="text-white font-medium">{formatDate(event.date)}</span>
                   </div>
 
+                  {event.bars > 1 && (
+                    <div className="flex items-center justify-between text-sm">
+                      <span className="text-gray-400">Span:</span>
+                      <span className="text-gray-300 font-medium">
+                        {formatDate(event.start_date)} - {formatDate(event.end_date)} ({event.bars} bars)
+                      </span>
+                    </div>
+                  )}
+
+                  {event.confirmed_date && (
+                    <div className="flex items-center justify-between text-sm">
+                      <span className="text-gray-400">Confirmed:</span>
+                      <span className="text-gray-300 font-medium">
+                        {formatDate(event.confirmed_date)} (+{event.confirmation_bars} bars)
+                      </span>
+                    </div>
+                  )}
+
                   <div className="flex items-center justify-between text-sm">
                     <span className="text-gray-400">Price:</span>
                     <span className="text-white font-medium">{formatPrice(event.price)}</span>
@@ -154,6 +172,9 @@ export default function EventsTimeline({ events }: EventsTimelineProps) {
                 <li><strong className="text-green-400">{EVENT_ICONS['Spring']} Spring:</strong> False breakdown, bullish reversal signal</li>
                 <li><strong className="text-green-400">{EVENT_ICONS['Sign of Strength']} Sign of Strength:</strong> Strong move up, confirms accumulation ending</li>
                 <li><strong className="text-green-400">{EVENT_ICONS['Selling Climax']} Selling Climax:</strong> Panic selling exhaustion, potential bottom</li>
+                <li><strong className="text-green-400">{EVENT_ICONS['Preliminary Support']} PS / {EVENT_ICONS['Automatic Rally']} AR / {EVENT_ICONS['Secondary Test']} ST:</strong> Structure of a new accumulation range</li>
+                <li><strong className="text-green-400">{EVENT_ICONS['Test of Spring']} Test of Spring / {EVENT_ICONS['Last Point of Support']} LPS:</strong> Low-volume higher lows, supply exhausted</li>
+                <li><strong className="text-green-400">{EVENT_ICONS['Jump Across the Creek']} JAC / {EVENT_ICONS['Back-Up']} Back-Up:</strong> Breakout above resistance and its successful retest</li>
               </ul>
             </div>
 
@@ -164,6 +185,9 @@ export default function EventsTimeline({ events }: EventsTimelineProps) {
                 <li><strong className="text-red-400">{EVENT_ICONS['Upthrust']} Upthrust:</strong> False breakout, bearish reversal signal</li>
                 <li><strong className="text-red-400">{EVENT_ICONS['Sign of Weakness']} Sign of Weakness:</strong> Strong move down, confirms distribution ending</li>
                 <li><strong className="text-red-400">{EVENT_ICONS['Buying Climax']} Buying Climax:</strong> Euphoric buying exhaustion, potential top</li>
+                <li><strong className="text-red-400">{EVENT_ICONS['Preliminary Supply']} PSY / {EVENT_ICONS['Automatic Reaction']} AR / {EVENT_ICONS['Secondary Test']} ST:</strong> Structure of a new distribution range</li>
+                <li><strong className="text-red-400">{EVENT_ICONS['Upthrust After Distribution']} UTAD:</strong> Late failed breakout that traps buyers</li>
+                <li><strong className="text-red-400">{EVENT_ICONS['Last Point of Supply']} LPSY:</strong> Weak low-volume rally before markdown</li>
               </ul>
             </div>
           </div>
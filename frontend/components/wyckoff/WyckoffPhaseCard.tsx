@@ -3,7 +3,8 @@ import {
   PHASE_COLORS,
   PHASE_TITLES,
   PHASE_DESCRIPTIONS,
-  EFFORT_RESULT_DESCRIPTIONS
+  EFFORT_RESULT_DESCRIPTIONS,
+  SCHEMATIC_PHASE_DESCRIPTIONS
 } from '@/lib/wyckoffConfig';
 import EducationalTooltip from './EducationalTooltip';
 
@@ -22,6 +23,14 @@ export default function WyckoffPhaseCard({ analysis, currentPrice }: WyckoffPhas
   const rangeSize = analysis.trading_range.max - analysis.trading_range.min;
   const rangePercentage = ((rangeSize / analysis.trading_range.min) * 100).toFixed(2);
 
+  // Progression of the most recent structure, oldest phase first
+  const phaseHistory = analysis.phase_history || [];
+  const latestStart = phaseHistory.map(p => p.phase).lastIndexOf('A');
+  const progression = latestStart >= 0 ? phaseHistory.slice(latestStart) : [];
+
+  const formatDate = (dateString: string) =>
+    new Date(dateString).toLocaleDateString('vi-VN', { day: '2-digit', month: '2-digit', year: 'numeric' });
+
   return (
     <div className="bg-gray-900 rounded-xl shadow-2xl border border-gray-800 overflow-hidden">
       {/* Header */}
@@ -69,6 +78,87 @@ export default function WyckoffPhaseCard({ analysis, currentPrice }: WyckoffPhas
           <p className="text-sm text-gray-300 leading-relaxed">{phaseDescription}</p>
         </div>
 
+        {/* Schematic Phase Progression */}
+        {progression.length > 0 && (
+          <div className="mb-6">
+            <div className="flex items-center gap-2 mb-3">
+              <span className="text-sm font-medium text-gray-300">
+                Schematic: {progression[0].schematic.charAt(0).toUpperCase() + progression[0].schematic.slice(1)}
+              </span>
+              <EducationalTooltip
+                title="Wyckoff Schematic Phases"
+                content="Wyckoff structures progress through Phases A to E. The phases are assigned by walking the detected events in chronological order."
+              />
+            </div>
+            <div className="flex gap-2">
+              {(['A', 'B', 'C', 'D', 'E'] as const).map((letter) => {
+                const period = progression.find(p => p.phase === letter);
+                return (
+                  <div
+                    key={letter}
+                    title={SCHEMATIC_PHASE_DESCRIPTIONS[letter]}
+                    className={`flex-1 rounded-lg p-2 border text-center ${
+                      period?.current
+                        ? `${phaseColors.badge} border-transparent text-white`
+                        : period
+                          ? 'bg-gray-800 border-gray-600 text-gray-200'
+                          : 'bg-gray-900 border-gray-800 text-gray-600'
+                    }`}
+                  >
+                    <div className="text-sm font-bold">Phase {letter}</div>
+                    {period && (
+                      <div className="text-[10px] mt-1 opacity-80">
+                        {formatDate(period.start_date)}
+                        <br />
+                        {period.current ? 'now' : formatDate(period.end_date)}
+                      </div>
+                    )}
+                    {period && <div className="text-[10px] mt-1 truncate">{period.trigger}</div>}
+                  </div>
+                );
+              })}
+            </div>
+          </div>
+        )}
+
+        {/* Higher Timeframes */}
+        {analysis.timeframes?.length > 0 && (
+          <div className="mb-6">
+            <div className="flex items-center gap-2 mb-3">
+              <span className="text-sm font-medium text-gray-300">Higher Timeframes</span>
+              <EducationalTooltip
+                title="Multi-Timeframe Alignment"
+                content="Wyckoff setups are more reliable when the weekly and monthly phases point the same way as the daily phase."
+              />
+              {analysis.alignment && (
+                <span className={`ml-auto text-xs font-semibold ${analysis.alignment.aligned ? 'text-green-400' : 'text-gray-400'}`}>
+                  {analysis.alignment.aligned
+                    ? 'Aligned'
+                    : `${analysis.alignment.agreeing} agree • ${analysis.alignment.conflicting} conflict`}
+                </span>
+              )}
+            </div>
+            <div className="grid grid-cols-2 gap-2">
+              {analysis.timeframes.map((tf) => {
+                const tfColors = PHASE_COLORS[tf.phase] || PHASE_COLORS.unknown;
+                return (
+                  <div key={tf.timeframe} className="bg-gray-800/50 rounded-lg p-3 border border-gray-700">
+                    <div className="text-xs text-gray-400 capitalize">{tf.timeframe}</div>
+                    <div className={`text-sm font-bold ${tfColors.text}`}>
+                      {PHASE_TITLES[tf.phase] || 'Unknown Phase'}
+                    </div>
+                    {tf.schematic_phase && (
+                      <div className="text-[10px] text-gray-500 mt-1">
+                        {tf.schematic} • Phase {tf.schematic_phase}
+                      </div>
+                    )}
+                  </div>
+                );
+              })}
+            </div>
+          </div>
+        )}
+
         {/* Quick Stats Grid */}
         <div className="grid grid-cols-3 gap-4">
           {/* Events Count */}
@@ -1,6 +1,7 @@
 'use client';
 
 import { WyckoffAnalysis } from '@/types';
+import ScoreWaterfall from '../ScoreWaterfall';
 
 interface WyckoffRecommendationCardProps {
   analysis: WyckoffAnalysis;
@@ -190,6 +191,12 @@ export default function WyckoffRecommendationCard({
           </div>
         </div>
       </div>
+
+      {analysis.score_breakdown && analysis.score_breakdown.contributions.length > 0 && (
+        <div className="mt-6 pt-6 border-t border-gray-800">
+          <ScoreWaterfall breakdown={analysis.score_breakdown} title="Why this recommendation" dark />
+        </div>
+      )}
     </div>
   );
 }
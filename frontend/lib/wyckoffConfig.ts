@@ -57,6 +57,17 @@ export const EVENT_ICONS: Record<string, string> = {
   'Buying Climax': '📈',
   'Sign of Strength': '💪',
   'Sign of Weakness': '😰',
+  'Preliminary Support': '🛑',
+  'Preliminary Supply': '🚧',
+  'Automatic Rally': '↗️',
+  'Automatic Reaction': '↘️',
+  'Secondary Test': '🔁',
+  'Test of Spring': '🧪',
+  'Last Point of Support': '🟢',
+  'Jump Across the Creek': '🦘',
+  'Back-Up': '↩️',
+  'Upthrust After Distribution': '🎯',
+  'Last Point of Supply': '🔴',
 };
 
 export const EVENT_DESCRIPTIONS: Record<string, string> = {
@@ -66,6 +77,17 @@ export const EVENT_DESCRIPTIONS: Record<string, string> = {
   'Buying Climax': 'Euphoric buying with exceptionally high volume and wide price spread upward. Often marks a top as the public rushes in at the worst time.',
   'Sign of Strength': 'Price rises strongly on good volume, breaking above resistance. Confirms accumulation phase is ending and markup is beginning.',
   'Sign of Weakness': 'Price falls strongly on good volume, breaking below support. Confirms distribution phase is ending and markdown is beginning.',
+  'Preliminary Support': 'The first heavy buying after a prolonged decline. Large interests begin to absorb supply, although the downtrend usually continues into a Selling Climax.',
+  'Preliminary Supply': 'The first heavy selling after a prolonged advance. Large interests begin to unload, although the uptrend usually continues into a Buying Climax.',
+  'Automatic Rally': 'The sharp rally after a Selling Climax as selling pressure dries up. Its high defines the top of the accumulation range.',
+  'Automatic Reaction': 'The sharp drop after a Buying Climax as buying pressure dries up. Its low defines the bottom of the distribution range.',
+  'Secondary Test': 'Price revisits the climax area on noticeably lower volume, showing that supply (or demand) has been exhausted at that level.',
+  'Test of Spring': 'A low-volume retest of the Spring low that holds. Confirms that supply was removed by the Spring.',
+  'Last Point of Support': 'A quiet pullback that makes a higher low after a Spring or Sign of Strength. A classic low-risk entry before markup.',
+  'Jump Across the Creek': 'A decisive, high-volume close above the trading range resistance (the creek) after accumulation. Signals the start of markup.',
+  'Back-Up': 'A low-volume pullback to the old resistance after the Jump Across the Creek. Former resistance now acts as support.',
+  'Upthrust After Distribution': 'A late failed breakout above distribution resistance that traps buyers. Often the final test of demand before markdown.',
+  'Last Point of Supply': 'A weak, low-volume rally that fails below resistance after a Sign of Weakness. A classic exit or short entry before markdown.',
 };
 
 export const PHASE_DESCRIPTIONS: Record<string, string> = {
@@ -100,3 +122,11 @@ export const EFFORT_RESULT_DESCRIPTIONS: Record<string, { title: string; descrip
     description: 'Insufficient recent data to determine the relationship between volume and price movement. More trading activity is needed for analysis.',
   },
 };
+
+export const SCHEMATIC_PHASE_DESCRIPTIONS: Record<string, string> = {
+  A: 'Stopping the prior trend: preliminary support/supply, the climax, the automatic rally/reaction and the secondary test.',
+  B: 'Building the cause: price oscillates inside the range while large interests absorb or distribute supply.',
+  C: 'The test: a Spring or UTAD shakes out the last weak holders or traps the last buyers.',
+  D: 'The trend emerges inside the range: Signs of Strength/Weakness, LPS/LPSY and the Jump Across the Creek.',
+  E: 'Price leaves the range and the new markup or markdown trend is underway.',
+};
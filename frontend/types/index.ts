@@ -24,10 +24,37 @@ export interface TechnicalIndicators {
   bollinger_lower: number;
 }
 
+export interface PatternAdjustment {
+  factor: 'volume' | 'trend';
+  delta: number;
+  reason: string;
+}
+
 export interface CandlestickPattern {
   name: string;
   type: 'bullish' | 'bearish' | 'neutral';
   confidence: number;
+  base_confidence: number;
+  relative_volume: number; // Average volume of the pattern bars vs. the 20 bars before
+  adjustments?: PatternAdjustment[];
+}
+
+export interface ChartPoint {
+  label: string;
+  date: string;
+  price: number;
+}
+
+export interface ChartPattern {
+  name: string;
+  type: 'bullish' | 'bearish' | 'neutral';
+  status: 'forming' | 'confirmed' | 'failed';
+  start_date: string;
+  end_date: string;
+  breakout_level: number;
+  target: number;
+  confidence: number;
+  key_points: ChartPoint[];
 }
 
 export interface TimeframePatterns {
@@ -36,6 +63,19 @@ export interface TimeframePatterns {
   monthly: CandlestickPattern[];
 }
 
+export interface PriceGap {
+  direction: 'up' | 'down';
+  kind: 'common' | 'breakaway' | 'runaway' | 'exhaustion';
+  date: string;
+  low: number;
+  high: number;
+  size: number;
+  volume_ratio: number;
+  fill_percent: number;
+  filled: boolean;
+  filled_date?: string;
+}
+
 export interface SupportResistance {
   support_levels: number[];
   resistance_levels: number[];
@@ -59,6 +99,157 @@ export interface WyckoffEvent {
   price: number;
   volume: number;
   confidence: number;
+  start_date: string;
+  end_date: string;
+  bars: number;
+  confirmed_date?: string;
+  confirmation_bars?: number;
+}
+
+export interface CauseTarget {
+  direction: 'upside' | 'downside';
+  basis: 'count_line' | 'range_extreme';
+  price: number;
+  change: number;
+}
+
+export interface CauseCount {
+  structure: 'accumulation' | 'distribution';
+  bias: 'upside' | 'downside';
+  box_size: number;
+  reversal: number;
+  start_date: string;
+  end_date: string;
+  start_event: string;
+  end_event: string;
+  count_line: number;
+  columns: number;
+  cause: number;
+  targets: CauseTarget[];
+}
+
+export type WyckoffSchematic = 'accumulation' | 'distribution' | 'reaccumulation' | 'redistribution';
+
+export type WyckoffSchematicPhase = 'A' | 'B' | 'C' | 'D' | 'E';
+
+export interface WyckoffPhasePeriod {
+  schematic: WyckoffSchematic;
+  phase: WyckoffSchematicPhase;
+  start_date: string;
+  end_date: string;
+  trigger: string;
+  current: boolean;
+}
+
+export interface HistoricalRange {
+  start_date: string;
+  end_date: string;
+  bars: number;
+  creek: number;
+  ice: number;
+  high: number;
+  low: number;
+  prior_trend: 'up' | 'down' | 'none';
+  breakout: 'up' | 'down' | 'none';
+  breakout_date?: string;
+  breakout_price?: number;
+  outcome: 'active' | 'pending' | 'failed' | 'target_reached' | 'follow_through';
+  structure: 'accumulation' | 'reaccumulation' | 'distribution' | 'redistribution' | 'undetermined';
+  active: boolean;
+}
+
+export interface TrendSegment {
+  start_date: string;
+  end_date: string;
+  bars: number;
+  direction: 'up' | 'down' | 'sideways';
+  change: number;
+}
+
+export interface RSPoint {
+  date: string;
+  value: number;
+}
+
+export interface RelativeStrength {
+  benchmark: string;
+  line: RSPoint[];
+  current: number;
+  trend: 'rising' | 'falling' | 'flat';
+  rank: number;
+  outperformance: Record<string, number>;
+  downside_capture: number;
+  decline_start?: string;
+  decline_end?: string;
+  benchmark_decline?: number;
+  stock_decline?: number;
+  held_up: boolean;
+}
+
+export type VSASignal =
+  | 'No Demand'
+  | 'No Supply'
+  | 'Stopping Volume'
+  | 'Upthrust'
+  | 'Test'
+  | 'Effort to Rise'
+  | 'Effort to Fall'
+  | 'Shakeout'
+  | 'Climactic Action';
+
+export interface VSABar {
+  date: string;
+  open: number;
+  high: number;
+  low: number;
+  close: number;
+  volume: number;
+  spread: 'narrow' | 'average' | 'wide';
+  spread_ratio: number;
+  close_position: 'low' | 'mid' | 'high';
+  close_location: number;
+  volume_class: 'low' | 'average' | 'high' | 'ultra_high' | 'unknown';
+  relative_volume: number;
+  signal?: VSASignal;
+  signal_type?: 'bullish' | 'bearish';
+}
+
+export interface VSASummary {
+  bars: number;
+  counts: Record<string, number>;
+  recent_bullish: number;
+  recent_bearish: number;
+  bias: 'bullish' | 'bearish' | 'neutral';
+  latest_signal?: VSABar;
+}
+
+export interface VSAAnalysis {
+  bars: VSABar[];
+  summary: VSASummary;
+}
+
+export type WyckoffBias = 'bullish' | 'bearish' | 'neutral';
+
+export interface WyckoffTimeframe {
+  timeframe: 'weekly' | 'monthly';
+  bars: number;
+  phase: WyckoffAnalysis['phase'];
+  phase_confidence: number;
+  schematic?: WyckoffSchematic;
+  schematic_phase?: WyckoffSchematicPhase;
+  trading_range: PriceRange;
+  events: WyckoffEvent[];
+  recommendation: 'buy' | 'sell' | 'hold';
+  recommendation_score: number;
+  bias: WyckoffBias;
+}
+
+export interface TimeframeAlignment {
+  daily_bias: WyckoffBias;
+  agreeing: number;
+  conflicting: number;
+  aligned: boolean;
+  score: number;
 }
 
 export interface WyckoffAnalysis {
@@ -68,13 +259,64 @@ export interface WyckoffAnalysis {
   trading_range: PriceRange;
   effort_result: 'confirming' | 'diverging' | 'unknown';
 
+  // Schematic phases A-E
+  schematic?: WyckoffSchematic;
+  schematic_phase?: WyckoffSchematicPhase;
+  phase_history: WyckoffPhasePeriod[];
+
   // Wyckoff-specific recommendation and trading zones
   recommendation: 'buy' | 'sell' | 'hold';
   recommendation_score: number;
+  score_breakdown: ScoreBreakdown;
   buy_zone: PriceRange;
   accumulation_zone: PriceRange;
   distribution_zone: PriceRange;
   sell_zone: PriceRange;
+
+  // Point & Figure cause-and-effect count
+  cause_count?: CauseCount;
+
+  // Full history segmented into trading ranges and trends
+  ranges: HistoricalRange[];
+  trends: TrendSegment[];
+
+  // Strength versus the VN-Index and optional sector index
+  relative_strength?: RelativeStrength;
+  sector_strength?: RelativeStrength;
+
+  // Per-bar Volume Spread Analysis
+  vsa: VSAAnalysis;
+
+  // Weekly and monthly Wyckoff readings and their agreement with daily
+  timeframes: WyckoffTimeframe[];
+  alignment?: TimeframeAlignment;
+}
+
+export type BarTransformType = 'heikin_ashi' | 'renko' | 'kagi' | 'line_break' | 'point_figure';
+
+export interface BarTransform {
+  type: BarTransformType;
+  box_size?: number;
+  atr_period?: number;
+  reversal?: number;
+  lines?: number;
+}
+
+export interface PointFigureColumn {
+  direction: 'X' | 'O';
+  top: number;
+  bottom: number;
+  boxes: number;
+  start_date: string;
+  end_date: string;
+  volume: number;
+}
+
+export interface TransformedSeries {
+  symbol: string;
+  transform: BarTransform;
+  bars: StockData[];
+  columns?: PointFigureColumn[];
 }
 
 export interface AnalysisReport {
@@ -84,7 +326,9 @@ export interface AnalysisReport {
   current_price: number;
   indicators: TechnicalIndicators;
   patterns: TimeframePatterns;
+  chart_patterns: ChartPattern[];
   support_resistance: SupportResistance;
+  gaps: PriceGap[];
   trend: TrendAnalysis;
   wyckoff: WyckoffAnalysis;
   buy_range: PriceRange;
@@ -92,5 +336,340 @@ export interface AnalysisReport {
   sell_range: PriceRange;
   recommendation: 'buy' | 'sell' | 'hold';
   recommendation_score: number;
+  scoring_model: string;
+  model_comparison?: ModelScore[];
+  score_breakdown: ScoreBreakdown;
   price_history: StockData[];
+  transform?: BarTransform;
+  as_of?: string;
+  custom_signals?: CustomSignal[];
+  trade_plan?: TradePlan;
+  forecast?: PriceForecast;
+}
+
+export interface ForecastBand {
+  day: number;
+  p5: number;
+  p25: number;
+  p50: number;
+  p75: number;
+  p95: number;
+}
+
+export interface LevelProbability {
+  label: string;
+  kind: 'support' | 'resistance' | 'wyckoff_zone';
+  price: number;
+  zone?: PriceRange;
+  direction: 'below' | 'above' | 'inside';
+  distance_percent: number;
+  touch_probability: number;
+  end_beyond_probability: number;
+  end_inside_probability?: number;
+}
+
+export interface GARCHFit {
+  omega: number;
+  alpha: number;
+  beta: number;
+  persistence: number;
+  half_life: number;
+  long_run_volatility: number;
+  next_volatility: number;
+  log_likelihood: number;
+}
+
+export interface ForecastMethod {
+  method: 'historical' | 'bootstrap' | 'garch';
+  daily_volatility: number;
+  annual_volatility: number;
+  horizon_volatility: number;
+  garch?: GARCHFit;
+  bands: ForecastBand[];
+  levels: LevelProbability[];
+}
+
+export interface PriceForecast {
+  horizon: number;
+  current_price: number;
+  lookback_bars: number;
+  drift: boolean;
+  methods: ForecastMethod[];
+  notes: string[];
+}
+
+export interface StopCandidate {
+  method: 'structural' | 'wyckoff' | 'atr';
+  price: number;
+  distance_percent: number;
+  reason: string;
+}
+
+export interface TradeTier {
+  label: string;
+  zone: PriceRange;
+  price: number;
+  fraction: number;
+  shares: number;
+  lots: number;
+  reward_risk?: number;
+  profit?: number;
+}
+
+export interface TradePlan {
+  account_equity: number;
+  risk_percent: number;
+  risk_budget: number;
+  stop_method?: string;
+  stop_loss: number;
+  stops: StopCandidate[];
+  entries: TradeTier[];
+  targets: TradeTier[];
+  shares: number;
+  lots: number;
+  lot_size: number;
+  average_entry: number;
+  position_value: number;
+  position_percent: number;
+  risk_per_share: number;
+  risk_amount: number;
+  reward_risk: number;
+  notes: string[];
+}
+
+export interface CustomSignal {
+  name: string;
+  rule: string;
+  triggered: boolean;
+  last_triggered?: string;
+  bars_since?: number;
+  trigger_count: number;
+  lookback: number;
+}
+
+export interface ScreenRow {
+  symbol: string;
+  matched: boolean;
+  date?: string;
+  close: number;
+  error?: string;
+}
+
+export interface ScreenResult {
+  rule: string;
+  matches: string[];
+  results: ScreenRow[];
+}
+
+export interface ModelScore {
+  model: string;
+  description?: string;
+  raw_score: number;
+  score: number;
+  recommendation: 'buy' | 'sell' | 'hold';
+  buy_threshold: number;
+  sell_threshold: number;
+  breakdown: ScoreBreakdown;
+}
+
+export interface ScoreContribution {
+  name: string;
+  value: number;
+  condition: string;
+  met: boolean;
+  weight: number;
+  contribution: number;
+}
+
+export interface ScoreBreakdown {
+  contributions: ScoreContribution[];
+  raw_score: number;
+  normalizer: number;
+  score: number;
+}
+
+export interface WyckoffBacktestTrade {
+  trigger: string;
+  event_date?: string;
+  entry_date: string;
+  entry_price: number;
+  exit_date: string;
+  exit_price: number;
+  exit_reason: 'stop_loss' | 'take_profit' | 'max_hold' | 'exit_event' | 'exit_zone' | 'end_of_data';
+  bars: number;
+  return_percent: number;
+}
+
+export interface WyckoffTriggerStats {
+  trigger: string;
+  trades: number;
+  wins: number;
+  losses: number;
+  win_rate: number;
+  avg_win: number;
+  avg_loss: number;
+  expectancy: number;
+  profit_factor: number;
+  total_return: number;
+  max_drawdown: number;
+  avg_bars: number;
+}
+
+export interface WyckoffBacktestResult {
+  symbol: string;
+  start_date: string;
+  end_date: string;
+  bars: number;
+  trades: WyckoffBacktestTrade[];
+  by_trigger: WyckoffTriggerStats[];
+  overall: WyckoffTriggerStats;
+}
+
+export interface EquityPoint {
+  date: string;
+  equity: number;
+  cash: number;
+  position_value: number;
+  shares: number;
+  drawdown: number;
+}
+
+export interface BacktestTrade {
+  date: string;
+  side: 'buy' | 'sell';
+  shares: number;
+  price: number;
+  value: number;
+  commission: number;
+  tax: number;
+  realized_pnl?: number;
+  reason?: string;
+}
+
+export interface BacktestMetrics {
+  total_return: number;
+  cagr: number;
+  volatility: number;
+  sharpe: number;
+  sortino: number;
+  max_drawdown: number;
+  max_drawdown_bars: number;
+  exposure: number;
+  trades: number;
+  round_trips: number;
+  win_rate: number;
+  total_commission: number;
+  total_tax: number;
+  rejections: Record<string, number>;
+}
+
+export interface BacktestResult {
+  symbol: string;
+  strategy: string;
+  start_date: string;
+  end_date: string;
+  initial_capital: number;
+  final_equity: number;
+  equity_curve: EquityPoint[];
+  trades: BacktestTrade[];
+  metrics: BacktestMetrics;
+}
+
+export interface BacktestComparison {
+  symbol: string;
+  strategy: BacktestResult;
+  buy_and_hold: BacktestResult;
+  index?: BacktestResult;
+  excess_vs_buy_and_hold: number;
+  excess_vs_index: number;
+  monte_carlo?: MonteCarloResult;
+}
+
+export interface Distribution {
+  mean: number;
+  std_dev: number;
+  min: number;
+  lower: number;
+  median: number;
+  upper: number;
+  max: number;
+}
+
+export interface EquityBand {
+  step: number;
+  date?: string;
+  p5: number;
+  p25: number;
+  p50: number;
+  p75: number;
+  p95: number;
+}
+
+export interface MonteCarloSimulation {
+  method: 'trade_resample' | 'block_bootstrap';
+  samples: number;
+  final_equity: Distribution;
+  total_return: Distribution;
+  cagr: Distribution;
+  sharpe?: Distribution;
+  max_drawdown: Distribution;
+  probability_of_ruin: number;
+  probability_of_loss: number;
+  equity_bands: EquityBand[];
+}
+
+export interface MonteCarloResult {
+  symbol: string;
+  strategy: string;
+  simulations: number;
+  block_size: number;
+  ruin_loss: number;
+  confidence_level: number;
+  original: BacktestMetrics;
+  trade_resample?: MonteCarloSimulation;
+  block_bootstrap?: MonteCarloSimulation;
+}
+
+export interface OptimizationCandidate {
+  params: Record<string, number>;
+  mean_score: number;
+  score_std_dev: number;
+  times_selected: number;
+}
+
+export interface WalkForwardFold {
+  in_sample_start: string;
+  in_sample_end: string;
+  out_of_sample_start: string;
+  out_of_sample_end: string;
+  params: Record<string, number>;
+  in_sample_score: number;
+  out_of_sample_score: number;
+  out_of_sample: BacktestMetrics;
+}
+
+export interface ParameterHeatmap {
+  x: string;
+  y: string;
+  x_values: number[];
+  y_values: number[];
+  values: number[][];
+  spread: number[][];
+  samples: number[][];
+}
+
+export interface OptimizationResult {
+  symbol: string;
+  method: 'grid' | 'random';
+  objective: 'sharpe' | 'sortino' | 'cagr' | 'total_return' | 'calmar';
+  evaluations: number;
+  workers: number;
+  candidates: OptimizationCandidate[];
+  folds: WalkForwardFold[];
+  out_of_sample: BacktestResult;
+  mean_in_sample_score: number;
+  mean_out_of_sample_score: number;
+  walk_forward_efficiency: number;
+  heatmaps: ParameterHeatmap[];
+  recommended: Record<string, number>;
 }